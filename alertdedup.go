@@ -0,0 +1,47 @@
+package notifyme
+
+import (
+	"errors"
+	"time"
+)
+
+// maxAlertFingerprints is the number of remembered alerts above which
+// expired ones are forgotten
+const maxAlertFingerprints = 1024
+
+// WithAlertDeduplication stops repeats of the same event from notifying
+// again within window: sinks added with SinkAlerting get the first entry
+// with a given fingerprint, and further entries with that fingerprint are
+// withheld from all of them until window has passed. The fingerprint
+// covers the logger name, level, caller and message, and clones share the
+// remembered fingerprints. Other sinks and the primary output receive
+// every entry.
+func WithAlertDeduplication(window time.Duration) Option {
+	return func(l *Logger) error {
+		if window <= 0 {
+			return errors.New("notifyme: alert deduplication window must be positive")
+		}
+		l.opts.alertWindow = window
+		return nil
+	}
+}
+
+// shouldAlert reports whether alerting sinks get the entry, remembering its
+// fingerprint if so. It must be called with the logger mutex held.
+func (l *Logger) shouldAlert(entry Entry) bool {
+	if l.opts.alertWindow == 0 {
+		return true
+	}
+	hasAlerting := false
+	for _, attached := range l.sinks {
+		if attached.alerting {
+			hasAlerting = true
+			break
+		}
+	}
+	if !hasAlerting {
+		return true
+	}
+
+	return l.alertSeen.check(entryFingerprint(entry), entry.Time, l.opts.alertWindow)
+}
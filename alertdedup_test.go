@@ -0,0 +1,133 @@
+package notifyme
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithAlertDeduplication(t *testing.T) {
+	type alertStep struct {
+		wait    time.Duration
+		level   int
+		message string
+	}
+	tests := []struct {
+		name    string
+		steps   []alertStep
+		alerted int
+	}{
+		{"repeats withheld", []alertStep{
+			{0, LevelError, "db down"}, {time.Second, LevelError, "db down"}, {time.Second, LevelError, "db down"},
+		}, 1},
+		{"different messages", []alertStep{
+			{0, LevelError, "db down"}, {0, LevelError, "cache down"}, {0, LevelError, "db down"},
+		}, 2},
+		{"different levels", []alertStep{
+			{0, LevelError, "db down"}, {0, LevelCritical, "db down"},
+		}, 2},
+		{"again after the window", []alertStep{
+			{0, LevelError, "db down"}, {59 * time.Second, LevelError, "db down"}, {time.Second, LevelError, "db down"},
+		}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			logger, err := NewLoggerE(LevelInfo, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			slack, _ := NewRingSink(10)
+			pager, _ := NewRingSink(10)
+			archive, _ := NewRingSink(10)
+			logger.AddSink(slack, SinkAlerting())
+			logger.AddSink(pager, SinkAlerting())
+			logger.AddSink(archive)
+			clock := newFakeClock(time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC))
+			if err := logger.Configure(WithClock(clock), WithAlertDeduplication(time.Minute)); err != nil {
+				t.Fatal(err)
+			}
+			for _, step := range tt.steps {
+				clock.Advance(step.wait)
+				logger.Log(step.level, step.message)
+			}
+			if err := logger.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := len(slack.Entries()); got != tt.alerted {
+				t.Errorf("first alerting sink got %d entries, want %d", got, tt.alerted)
+			}
+			if got := len(pager.Entries()); got != tt.alerted {
+				t.Errorf("second alerting sink got %d entries, want %d", got, tt.alerted)
+			}
+			if got := len(archive.Entries()); got != len(tt.steps) {
+				t.Errorf("plain sink got %d entries, want all %d", got, len(tt.steps))
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Count(string(data), "\n"); got != len(tt.steps) {
+				t.Errorf("file has %d lines, want all %d", got, len(tt.steps))
+			}
+		})
+	}
+}
+
+func TestWithAlertDeduplicationFingerprintsCaller(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	alerts, _ := NewRingSink(10)
+	logger.AddSink(alerts, SinkAlerting())
+	if err := logger.Configure(WithAlertDeduplication(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	logger.Log(LevelError, "db down")
+	logger.Log(LevelError, "db down")
+	logger.Named("worker").Log(LevelError, "db down")
+	if got := len(alerts.Entries()); got != 3 {
+		t.Errorf("alerting sink got %d entries, want one per call site and logger name", got)
+	}
+}
+
+func TestWithAlertDeduplicationThroughWithFields(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	alerts, _ := NewRingSink(10)
+	archive, _ := NewRingSink(10)
+	logger.AddSink(alerts, SinkAlerting())
+	logger.AddSink(archive)
+	if err := logger.Configure(WithAlertDeduplication(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		logger.WithFields(map[string]interface{}{"request_id": i}).Log(LevelError, "db down")
+	}
+	if got := len(alerts.Entries()); got != 1 {
+		t.Errorf("alerting sink got %d entries from per-request loggers, want 1", got)
+	}
+	if got := len(archive.Entries()); got != 3 {
+		t.Errorf("plain sink got %d entries, want all 3", got)
+	}
+}
+
+func TestWithAlertDeduplicationOff(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	alerts, _ := NewRingSink(10)
+	logger.AddSink(alerts, SinkAlerting())
+	for i := 0; i < 3; i++ {
+		logger.Log(LevelError, "db down")
+	}
+	if got := len(alerts.Entries()); got != 3 {
+		t.Errorf("alerting sink got %d entries without deduplication, want 3", got)
+	}
+}
+
+func TestWithAlertDeduplicationInvalid(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	if err := logger.Configure(WithAlertDeduplication(0)); err == nil {
+		t.Error("Configure accepted a zero window")
+	}
+}
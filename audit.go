@@ -0,0 +1,175 @@
+package notifyme
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Keys of the hash chain fields in audit log entries
+const (
+	auditPrevHashKey  = "prev_hash"
+	auditEntryHashKey = "entry_hash"
+)
+
+// AuditLogger writes tamper-evident JSON-lines entries. Every entry carries
+// the hash of the previous entry and its own hash, computed as
+// SHA256(prev_hash + canonical entry JSON), so removing, reordering or
+// altering entries breaks the chain.
+type AuditLogger struct {
+	mu       sync.Mutex
+	w        io.Writer
+	prevHash string
+	now      func() time.Time
+}
+
+// NewAuditLogger creates an AuditLogger writing to w. The seed is used as
+// the prev_hash of the first entry.
+func NewAuditLogger(w io.Writer, seed string) *AuditLogger {
+	return &AuditLogger{w: w, prevHash: seed, now: time.Now}
+}
+
+// Log writes an audit entry with the given level, message and fields
+func (a *AuditLogger) Log(level int, message string, fields map[string]interface{}) error {
+	entry := map[string]interface{}{
+		"ts":    a.now().UTC().Format(time.RFC3339Nano),
+		"level": levelName(level),
+		"msg":   message,
+	}
+	if len(fields) > 0 {
+		entry["fields"] = fields
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	canonical, err := canonicalJSON(entry)
+	if err != nil {
+		return err
+	}
+	entryHash := chainHash(a.prevHash, canonical)
+
+	var line map[string]interface{}
+	if err := decodeJSON(canonical, &line); err != nil {
+		return err
+	}
+	line[auditPrevHashKey] = a.prevHash
+	line[auditEntryHashKey] = entryHash
+	data, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	if _, err := a.w.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	a.prevHash = entryHash
+	return nil
+}
+
+// chainHash returns the hex SHA256 of the previous hash followed by the
+// canonical entry
+func chainHash(prevHash string, canonical []byte) string {
+	sum := sha256.Sum256(append([]byte(prevHash), canonical...))
+	return hex.EncodeToString(sum[:])
+}
+
+// canonicalJSON marshals v and normalizes it by decoding and re-encoding,
+// which sorts object keys and keeps numbers as written, so the same entry
+// always produces the same bytes
+func canonicalJSON(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := decodeJSON(data, &generic); err != nil {
+		return nil, err
+	}
+	return json.Marshal(generic)
+}
+
+// decodeJSON decodes data keeping numbers as json.Number
+func decodeJSON(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// ChainError reports where an audit log failed verification
+type ChainError struct {
+	Line   int
+	Reason string
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("notifyme: audit chain broken at line %d: %s", e.Line, e.Reason)
+}
+
+// VerifyChain reads a JSON-lines audit log written by AuditLogger and checks
+// that every entry's hash matches its content and links to the entry before
+// it. The first entry's prev_hash is taken as the seed. On failure it
+// returns a *ChainError with the 1-based line number.
+func VerifyChain(r io.Reader) error {
+	reader := bufio.NewReader(r)
+	prevHash := ""
+	lineNum := 0
+	first := true
+	for {
+		data, err := reader.ReadBytes('\n')
+		if len(data) > 0 {
+			lineNum++
+			data = bytes.TrimSpace(data)
+			if len(data) > 0 {
+				hash, verr := verifyChainLine(data, prevHash, first)
+				if verr != nil {
+					return &ChainError{Line: lineNum, Reason: verr.Error()}
+				}
+				prevHash = hash
+				first = false
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// verifyChainLine checks a single entry and returns its entry_hash
+func verifyChainLine(data []byte, prevHash string, first bool) (string, error) {
+	var line map[string]interface{}
+	if err := decodeJSON(data, &line); err != nil {
+		return "", fmt.Errorf("invalid JSON: %v", err)
+	}
+	linePrev, ok := line[auditPrevHashKey].(string)
+	if !ok {
+		return "", errors.New("missing prev_hash")
+	}
+	lineHash, ok := line[auditEntryHashKey].(string)
+	if !ok {
+		return "", errors.New("missing entry_hash")
+	}
+	if !first && linePrev != prevHash {
+		return "", errors.New("prev_hash does not match previous entry_hash")
+	}
+
+	delete(line, auditPrevHashKey)
+	delete(line, auditEntryHashKey)
+	canonical, err := json.Marshal(line)
+	if err != nil {
+		return "", err
+	}
+	if chainHash(linePrev, canonical) != lineHash {
+		return "", errors.New("entry_hash does not match entry content")
+	}
+	return lineHash, nil
+}
@@ -0,0 +1,149 @@
+package notifyme
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeAuditLog logs n entries to a new audit log with the given seed and
+// returns its lines
+func writeAuditLog(t *testing.T, seed string, n int) []string {
+	t.Helper()
+	var buf bytes.Buffer
+	audit := NewAuditLogger(&buf, seed)
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	audit.now = func() time.Time { return at }
+	for i := 0; i < n; i++ {
+		at = at.Add(time.Second)
+		if err := audit.Log(LevelInfo, "user updated", map[string]interface{}{"user": "ann", "change": i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+}
+
+// checkChainLine recomputes the hash of an audit line, returning its
+// prev_hash and entry_hash and whether the hash matches the content
+func checkChainLine(t *testing.T, line string) (prev, hash string, valid bool) {
+	t.Helper()
+	var fields map[string]interface{}
+	if err := decodeJSON([]byte(line), &fields); err != nil {
+		t.Fatal(err)
+	}
+	prev, _ = fields[auditPrevHashKey].(string)
+	hash, _ = fields[auditEntryHashKey].(string)
+	delete(fields, auditPrevHashKey)
+	delete(fields, auditEntryHashKey)
+	canonical, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return prev, hash, chainHash(prev, canonical) == hash
+}
+
+func TestAuditLoggerChain(t *testing.T) {
+	tests := []struct {
+		name string
+		seed string
+	}{
+		{"empty seed", ""},
+		{"custom seed", "genesis"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := writeAuditLog(t, tt.seed, 4)
+			if len(lines) != 4 {
+				t.Fatalf("got %d lines, want 4", len(lines))
+			}
+			prevHash := tt.seed
+			for i, line := range lines {
+				prev, hash, valid := checkChainLine(t, line)
+				if prev != prevHash {
+					t.Errorf("line %d prev_hash = %q, want %q", i+1, prev, prevHash)
+				}
+				if !valid {
+					t.Errorf("line %d entry_hash does not match its content", i+1)
+				}
+				prevHash = hash
+			}
+		})
+	}
+}
+
+func TestAuditLoggerTamperDetected(t *testing.T) {
+	lines := writeAuditLog(t, "seed", 3)
+	tampered := strings.Replace(lines[1], `"user":"ann"`, `"user":"bob"`, 1)
+	if tampered == lines[1] {
+		t.Fatal("test line was not modified")
+	}
+	if _, _, valid := checkChainLine(t, tampered); valid {
+		t.Error("the hash of a modified entry still matches")
+	}
+}
+
+func TestAuditLoggerEntryFormat(t *testing.T) {
+	lines := writeAuditLog(t, "", 1)
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]interface{}{"ts": "2024-01-01T00:00:01Z", "level": "INFO", "msg": "user updated"} {
+		if entry[key] != want {
+			t.Errorf("%s = %v, want %v", key, entry[key], want)
+		}
+	}
+	if fields, ok := entry["fields"].(map[string]interface{}); !ok || fields["user"] != "ann" {
+		t.Errorf("fields = %v", entry["fields"])
+	}
+}
+
+func TestVerifyChain(t *testing.T) {
+	valid := writeAuditLog(t, "seed", 4)
+	tests := []struct {
+		name     string
+		lines    func([]string) []string
+		wantLine int
+		reason   string
+	}{
+		{"valid", func(l []string) []string { return l }, 0, ""},
+		{"blank lines", func(l []string) []string { return []string{l[0], "", l[1], "  ", l[2], l[3]} }, 0, ""},
+		{"modified entry", func(l []string) []string {
+			l[2] = strings.Replace(l[2], `"change":2`, `"change":9`, 1)
+			return l
+		}, 3, "content"},
+		{"removed entry", func(l []string) []string { return append(l[:1], l[2:]...) }, 2, "previous entry_hash"},
+		{"reordered entries", func(l []string) []string { l[1], l[2] = l[2], l[1]; return l }, 2, "previous entry_hash"},
+		{"invalid JSON", func(l []string) []string { l[3] = "{"; return l }, 4, "invalid JSON"},
+		{"missing hash", func(l []string) []string { l[0] = `{"msg":"x","prev_hash":"seed"}`; return l }, 1, "missing entry_hash"},
+		{"missing prev hash", func(l []string) []string { l[1] = `{"msg":"x","entry_hash":"h"}`; return l }, 2, "missing prev_hash"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := tt.lines(append([]string(nil), valid...))
+			err := VerifyChain(strings.NewReader(strings.Join(lines, "\n") + "\n"))
+			if tt.wantLine == 0 {
+				if err != nil {
+					t.Errorf("VerifyChain = %v, want nil", err)
+				}
+				return
+			}
+			chainErr, ok := err.(*ChainError)
+			if !ok {
+				t.Fatalf("VerifyChain = %v, want a *ChainError", err)
+			}
+			if chainErr.Line != tt.wantLine || !strings.Contains(chainErr.Reason, tt.reason) {
+				t.Errorf("VerifyChain failed at line %d (%s), want line %d (%s)", chainErr.Line, chainErr.Reason, tt.wantLine, tt.reason)
+			}
+		})
+	}
+}
+
+func TestVerifyChainWithoutTrailingNewline(t *testing.T) {
+	lines := writeAuditLog(t, "", 2)
+	if err := VerifyChain(strings.NewReader(strings.Join(lines, "\n"))); err != nil {
+		t.Errorf("VerifyChain = %v, want nil", err)
+	}
+}
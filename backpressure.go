@@ -0,0 +1,137 @@
+package notifyme
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// BackpressureState tells whether sink deliveries keep up with logging
+type BackpressureState int
+
+// Backpressure states
+const (
+	// BackpressureOK means the delivery queue is mostly empty
+	BackpressureOK BackpressureState = iota
+	// BackpressureDegraded means the queue is at least half full
+	BackpressureDegraded
+	// BackpressureOverloaded means the queue is nearly full or deliveries
+	// were dropped within the window
+	BackpressureOverloaded
+)
+
+// Thresholds of the queue fill ratio for the backpressure states
+const (
+	degradedQueueRatio   = 0.5
+	overloadedQueueRatio = 0.9
+)
+
+// defaultBackpressureWindow is the drop-rate window used unless
+// WithBackpressureMonitor sets another
+const defaultBackpressureWindow = 10 * time.Second
+
+// String returns the state name, such as "DEGRADED"
+func (s BackpressureState) String() string {
+	switch s {
+	case BackpressureOK:
+		return "OK"
+	case BackpressureDegraded:
+		return "DEGRADED"
+	case BackpressureOverloaded:
+		return "OVERLOADED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// backpressureMonitor tracks the state and the drops of the current and
+// previous window. It lives in the sink pool holder, which clones share,
+// so it has its own mutex.
+type backpressureMonitor struct {
+	mu          sync.Mutex
+	pool        *sinkPool // nil until the first evaluation
+	state       BackpressureState
+	windowStart time.Time
+	startDrops  int64
+	prevDrops   int64
+}
+
+// WithBackpressureMonitor sets the window over which dropped sink
+// deliveries count towards BackpressureState and registers fn, if not nil,
+// to be called with the old and new state on every transition. The state
+// is evaluated whenever an entry is queued for the sinks and when
+// BackpressureState is called. Clones sharing the delivery pool share
+// the state, so a transition is reported once, by whichever logger sees
+// it. fn runs with the logger mutex held, so it must not log through the
+// same logger or its clones.
+func WithBackpressureMonitor(window time.Duration, fn func(old, new BackpressureState)) Option {
+	return func(l *Logger) error {
+		if window <= 0 {
+			return errors.New("notifyme: backpressure window must be positive")
+		}
+		l.opts.backpressureWindow = window
+		l.opts.backpressureFn = fn
+		return nil
+	}
+}
+
+// BackpressureState reports whether the WithSinkConcurrency delivery queue
+// keeps up: OVERLOADED when it is at least 90% full or deliveries were
+// dropped in the last window, DEGRADED when it is at least half full, and
+// OK otherwise, including when sinks are delivered inline.
+func (l *Logger) BackpressureState() BackpressureState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.updateBackpressure()
+}
+
+// updateBackpressure evaluates the backpressure state, calling the
+// transition callback if it changed. It must be called with the logger
+// mutex held.
+func (l *Logger) updateBackpressure() BackpressureState {
+	pool := l.sinkPool.current()
+	if pool == nil {
+		return BackpressureOK
+	}
+	window := l.opts.backpressureWindow
+	if window == 0 {
+		window = defaultBackpressureWindow
+	}
+	now := l.currentTime()
+	dropped := pool.dropped.Load()
+	m := &l.sinkPool.monitor
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch m.pool {
+	case pool:
+	case nil:
+		m.pool, m.windowStart, m.startDrops = pool, now, dropped
+	default:
+		// A replaced pool counts its drops from zero
+		m.pool, m.startDrops, m.prevDrops = pool, 0, 0
+	}
+	if elapsed := now.Sub(m.windowStart); elapsed >= window {
+		m.prevDrops = dropped - m.startDrops
+		if elapsed >= 2*window {
+			m.prevDrops = 0
+		}
+		m.windowStart = now
+		m.startDrops = dropped
+	}
+
+	state := BackpressureOK
+	fill := float64(len(pool.jobs)) / float64(cap(pool.jobs))
+	switch {
+	case fill >= overloadedQueueRatio || m.prevDrops > 0 || dropped > m.startDrops:
+		state = BackpressureOverloaded
+	case fill >= degradedQueueRatio:
+		state = BackpressureDegraded
+	}
+	if old := m.state; old != state {
+		m.state = state
+		if l.opts.backpressureFn != nil {
+			l.opts.backpressureFn(old, state)
+		}
+	}
+	return state
+}
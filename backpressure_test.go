@@ -0,0 +1,172 @@
+package notifyme
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// waitForEmptyQueue waits until the sink workers took every queued job
+func waitForEmptyQueue(t *testing.T, logger *Logger) {
+	t.Helper()
+	pool := logger.sinkPool.current()
+	deadline := time.Now().Add(time.Second)
+	for len(pool.jobs) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d jobs still queued", len(pool.jobs))
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBackpressureState(t *testing.T) {
+	sink := &gateSink{started: make(chan struct{}, 100), release: make(chan struct{})}
+	clock := newFakeClock(time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC))
+	var transitions []string
+	logger := newPoolTestLogger(t, sink,
+		WithClock(clock), WithSinkConcurrency(1), WithSinkQueue(10, OverflowDrop),
+		WithBackpressureMonitor(time.Minute, func(old, new BackpressureState) {
+			transitions = append(transitions, old.String()+">"+new.String())
+		}),
+	)
+	logger.Log(LevelInfo, "running")
+	<-sink.started
+
+	// The worker is blocked, so every entry stays queued
+	steps := []struct {
+		entries int
+		want    BackpressureState
+	}{
+		{4, BackpressureOK},
+		{1, BackpressureDegraded},
+		{3, BackpressureDegraded},
+		{1, BackpressureOverloaded},
+		{1, BackpressureOverloaded},
+		{2, BackpressureOverloaded},
+	}
+	for i, step := range steps {
+		for j := 0; j < step.entries; j++ {
+			logger.Log(LevelInfo, "queued")
+		}
+		if got := logger.BackpressureState(); got != step.want {
+			t.Fatalf("step %d: state %s, want %s", i, got, step.want)
+		}
+	}
+	if got := logger.DroppedSinkDeliveries(); got != 2 {
+		t.Fatalf("DroppedSinkDeliveries = %d, want 2", got)
+	}
+
+	close(sink.release)
+	waitForEmptyQueue(t, logger)
+	if got := logger.BackpressureState(); got != BackpressureOverloaded {
+		t.Errorf("state %s with an empty queue, want drops in the window to keep it %s", got, BackpressureOverloaded)
+	}
+	clock.Advance(time.Minute)
+	if got := logger.BackpressureState(); got != BackpressureOverloaded {
+		t.Errorf("state %s one window later, want the previous window's drops to count", got)
+	}
+	clock.Advance(time.Minute)
+	if got := logger.BackpressureState(); got != BackpressureOK {
+		t.Errorf("state %s two windows later, want %s", got, BackpressureOK)
+	}
+
+	want := []string{"OK>DEGRADED", "DEGRADED>OVERLOADED", "OVERLOADED>OK"}
+	if !reflect.DeepEqual(transitions, want) {
+		t.Errorf("transitions %q, want %q", transitions, want)
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBackpressureStateQuietWindowClearsDrops(t *testing.T) {
+	sink := &gateSink{started: make(chan struct{}, 100), release: make(chan struct{})}
+	clock := newFakeClock(time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC))
+	logger := newPoolTestLogger(t, sink,
+		WithClock(clock), WithSinkConcurrency(1), WithSinkQueue(1, OverflowDrop),
+		WithBackpressureMonitor(time.Minute, nil),
+	)
+	logger.Log(LevelInfo, "running")
+	<-sink.started
+	logger.Log(LevelInfo, "queued")
+	logger.Log(LevelInfo, "dropped")
+	close(sink.release)
+	waitForEmptyQueue(t, logger)
+
+	// No window has passed yet, and more than two windows clears the drops
+	if got := logger.BackpressureState(); got != BackpressureOverloaded {
+		t.Errorf("state %s, want %s", got, BackpressureOverloaded)
+	}
+	clock.Advance(3 * time.Minute)
+	if got := logger.BackpressureState(); got != BackpressureOK {
+		t.Errorf("state %s after a quiet window, want %s", got, BackpressureOK)
+	}
+	logger.Close()
+}
+
+func TestBackpressureStateSharedWithClones(t *testing.T) {
+	sink := &gateSink{started: make(chan struct{}, 100), release: make(chan struct{})}
+	var transitions []string
+	logger := newPoolTestLogger(t, sink,
+		WithSinkConcurrency(1), WithSinkQueue(10, OverflowDrop),
+		WithBackpressureMonitor(time.Minute, func(old, new BackpressureState) {
+			transitions = append(transitions, old.String()+">"+new.String())
+		}),
+	)
+	clone := logger.WithFields(map[string]interface{}{"req": 1})
+	logger.Log(LevelInfo, "running")
+	<-sink.started
+
+	for i := 0; i < 5; i++ {
+		clone.Log(LevelInfo, "queued")
+	}
+	if got := logger.BackpressureState(); got != BackpressureDegraded {
+		t.Errorf("original state %s after the clone filled the queue, want %s", got, BackpressureDegraded)
+	}
+	if got := clone.BackpressureState(); got != BackpressureDegraded {
+		t.Errorf("clone state %s, want %s", got, BackpressureDegraded)
+	}
+	if want := []string{"OK>DEGRADED"}; !reflect.DeepEqual(transitions, want) {
+		t.Errorf("transitions %q, want %q reported once", transitions, want)
+	}
+
+	close(sink.release)
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBackpressureStateWithoutPool(t *testing.T) {
+	logger := newPoolTestLogger(t, &slowSink{})
+	for i := 0; i < 100; i++ {
+		logger.Log(LevelInfo, "inline")
+	}
+	if got := logger.BackpressureState(); got != BackpressureOK {
+		t.Errorf("state %s for inline sinks, want %s", got, BackpressureOK)
+	}
+}
+
+func TestBackpressureStateString(t *testing.T) {
+	tests := []struct {
+		state BackpressureState
+		want  string
+	}{
+		{BackpressureOK, "OK"},
+		{BackpressureDegraded, "DEGRADED"},
+		{BackpressureOverloaded, "OVERLOADED"},
+		{BackpressureState(7), "UNKNOWN"},
+	}
+	for _, tt := range tests {
+		if got := tt.state.String(); got != tt.want {
+			t.Errorf("BackpressureState(%d).String() = %q, want %q", int(tt.state), got, tt.want)
+		}
+	}
+}
+
+func TestWithBackpressureMonitorInvalid(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	if err := logger.Configure(WithBackpressureMonitor(0, nil)); err == nil {
+		t.Error("Configure accepted a zero window")
+	}
+}
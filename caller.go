@@ -0,0 +1,98 @@
+package notifyme
+
+import (
+	"errors"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// WithCallerFunction adds the name of the calling function, such as
+// "main.(*Server).handle", to every entry. Text output shows it after the
+// file and line, JSON output as "func" and ECS output as
+// log.origin.function.
+func WithCallerFunction() Option {
+	return func(l *Logger) error {
+		l.opts.callerFunction = true
+		return nil
+	}
+}
+
+// WithCallerTrimPrefix strips prefix from caller paths and shows the rest
+// of the path, e.g. "internal/db/conn.go:42" instead of "conn.go:42", so
+// entries do not leak absolute build paths. Callers outside the prefix keep
+// the base-name form. The trimmed path is also what sinks see in
+// Entry.Caller.File.
+func WithCallerTrimPrefix(prefix string) Option {
+	return func(l *Logger) error {
+		if prefix == "" {
+			return errors.New("notifyme: caller trim prefix must not be empty")
+		}
+		if !strings.HasSuffix(prefix, "/") {
+			prefix += "/"
+		}
+		l.opts.callerTrim = prefix
+		return nil
+	}
+}
+
+// WithCallerRelativeTo shows caller paths relative to dir, typically the
+// module root, like WithCallerTrimPrefix with the absolute form of dir
+func WithCallerRelativeTo(dir string) Option {
+	return func(l *Logger) error {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		return WithCallerTrimPrefix(filepath.ToSlash(abs))(l)
+	}
+}
+
+// trimCaller applies the configured caller trim prefix
+func (l *Logger) trimCaller(caller Caller) Caller {
+	if l.opts.callerTrim == "" || !strings.HasPrefix(caller.File, l.opts.callerTrim) {
+		return caller
+	}
+	caller.File = strings.TrimPrefix(caller.File, l.opts.callerTrim)
+	caller.trimmed = true
+	return caller
+}
+
+// maxCachedCallers bounds the resolved call sites kept by callerAt
+const maxCachedCallers = 4096
+
+// callerCache maps the program counters of logging call sites to their
+// resolved location, since resolving a frame allocates and a program logs
+// from a limited number of places
+var (
+	callerCache   = make(map[uintptr]Caller)
+	callerCacheMu sync.RWMutex
+)
+
+// callerAt returns the source location depth frames above its caller,
+// counted like runtime.Caller. Frames are resolved with
+// runtime.CallersFrames so inlined calls report the function that was
+// inlined rather than the one it was inlined into. Resolved locations are
+// cached by program counter.
+func callerAt(depth int) Caller {
+	var pcs [1]uintptr
+	if runtime.Callers(depth+2, pcs[:]) == 0 {
+		return Caller{File: "???"}
+	}
+	callerCacheMu.RLock()
+	caller, ok := callerCache[pcs[0]]
+	callerCacheMu.RUnlock()
+	if ok {
+		return caller
+	}
+	// A separate slice keeps pcs from escaping on cache hits
+	frame, _ := runtime.CallersFrames([]uintptr{pcs[0]}).Next()
+	caller = Caller{File: frame.File, Line: frame.Line, Function: frame.Function}
+	callerCacheMu.Lock()
+	if len(callerCache) < maxCachedCallers {
+		callerCache[pcs[0]] = caller
+	}
+	callerCacheMu.Unlock()
+	return caller
+}
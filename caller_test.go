@@ -0,0 +1,162 @@
+package notifyme
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const callerTestPackage = "github.com/AmosSParker/NotifyMe"
+
+// callerTestType has a method that logs, to check method names
+type callerTestType struct {
+	logger *Logger
+}
+
+func (c *callerTestType) logFromMethod() {
+	c.logger.Log(LevelInfo, "from method")
+}
+
+// logFromHelper logs from a plain function
+func logFromHelper(logger *Logger) {
+	logger.Log(LevelInfo, "from helper")
+}
+
+func TestWithCallerFunction(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(*Logger)
+		want string
+	}{
+		{"test function", func(l *Logger) { l.Log(LevelInfo, "direct") }, callerTestPackage + ".TestWithCallerFunction.func1"},
+		{"function", logFromHelper, callerTestPackage + ".logFromHelper"},
+		{"method", func(l *Logger) { (&callerTestType{l}).logFromMethod() }, callerTestPackage + ".(*callerTestType).logFromMethod"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+			ring, _ := NewRingSink(1)
+			logger.AddSink(ring)
+			if err := logger.Configure(WithCallerFunction()); err != nil {
+				t.Fatal(err)
+			}
+			tt.log(logger)
+			if got := ring.Entries()[0].Caller.Function; got != tt.want {
+				t.Errorf("function = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCallerFunctionOutput(t *testing.T) {
+	want := callerTestPackage + ".TestCallerFunctionOutput"
+	var text, encoded bytes.Buffer
+	textLogger := newWriterLogger(LevelInfo, &text)
+	jsonLogger := newWriterLogger(LevelInfo, &encoded)
+	if err := textLogger.Configure(WithCallerFunction()); err != nil {
+		t.Fatal(err)
+	}
+	if err := jsonLogger.Configure(WithCallerFunction(), WithFormat(FormatJSON)); err != nil {
+		t.Fatal(err)
+	}
+	textLogger.Log(LevelInfo, "text")
+	jsonLogger.Log(LevelInfo, "json")
+
+	if !strings.Contains(text.String(), "caller_test.go:") || !strings.Contains(text.String(), " "+want+": [INFO]") {
+		t.Errorf("text line %q lacks the function after the file and line", text.String())
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(encoded.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["func"] != want {
+		t.Errorf("JSON func = %v, want %s", doc["func"], want)
+	}
+}
+
+func TestCallerFunctionOffByDefault(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newWriterLogger(LevelInfo, &buf)
+	ring, _ := NewRingSink(1)
+	logger.AddSink(ring)
+	logger.Log(LevelInfo, "plain")
+	if got := ring.Entries()[0].Caller.Function; got != "" {
+		t.Errorf("function = %q without WithCallerFunction", got)
+	}
+	if strings.Contains(buf.String(), "TestCallerFunctionOffByDefault") {
+		t.Errorf("text line %q shows the function", buf.String())
+	}
+}
+
+func TestWithCallerTrimPrefix(t *testing.T) {
+	dir := callerDir(t)
+	parent, module := filepath.Dir(dir), filepath.Base(dir)
+	tests := []struct {
+		name string
+		opt  Option
+		want string
+	}{
+		{"prefix", WithCallerTrimPrefix(parent + "/"), module + "/caller_test.go"},
+		{"prefix without slash", WithCallerTrimPrefix(parent), module + "/caller_test.go"},
+		{"relative to dir", WithCallerRelativeTo(filepath.Join(dir, "..")), module + "/caller_test.go"},
+		{"own directory", WithCallerTrimPrefix(dir), "caller_test.go"},
+		{"caller outside the prefix", WithCallerTrimPrefix("/elsewhere"), "caller_test.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, format := range []Format{FormatText, FormatJSON} {
+				var buf bytes.Buffer
+				logger := newWriterLogger(LevelInfo, &buf)
+				ring, _ := NewRingSink(2)
+				logger.AddSink(ring)
+				if err := logger.Configure(tt.opt, WithFormat(format)); err != nil {
+					t.Fatal(err)
+				}
+				_, _, line, _ := runtime.Caller(0)
+				logger.Log(LevelInfo, "logged")
+				logger.NewEvent(LevelInfo).Msg("event")
+
+				want := tt.want + ":" + strconv.Itoa(line+1)
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("format %d output %q lacks caller %s", format, buf.String(), want)
+				}
+				if strings.Contains(buf.String(), parent) {
+					t.Errorf("format %d output %q leaks the build path", format, buf.String())
+				}
+				if got := ring.Entries()[0].Caller.String(); got != want {
+					t.Errorf("sink caller = %s, want %s", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWithCallerTrimPrefixEvent(t *testing.T) {
+	dir := callerDir(t)
+	var buf bytes.Buffer
+	logger := newWriterLogger(LevelInfo, &buf)
+	if err := logger.Configure(WithCallerTrimPrefix(filepath.Dir(dir)), WithFormat(FormatJSON)); err != nil {
+		t.Fatal(err)
+	}
+	_, _, line, _ := runtime.Caller(0)
+	logger.NewEvent(LevelInfo).Msg("event")
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Base(dir) + "/caller_test.go:" + strconv.Itoa(line+1); doc["caller"] != want {
+		t.Errorf("caller = %v, want %s", doc["caller"], want)
+	}
+}
+
+func TestWithCallerTrimPrefixInvalid(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	if err := logger.Configure(WithCallerTrimPrefix("")); err == nil {
+		t.Error("Configure accepted an empty prefix")
+	}
+}
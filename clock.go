@@ -0,0 +1,57 @@
+package notifyme
+
+import (
+	"errors"
+	"time"
+)
+
+// Clock supplies the current time and tickers to a logger, so tests can
+// control entry timestamps and periodic output such as WithHeartbeat
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers ticks on C like time.Ticker until it is stopped
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// WithClock makes the logger read the time from clock and take its
+// tickers from it. Apply it before WithHeartbeat, which starts its ticker
+// right away. Clones keep the clock.
+func WithClock(clock Clock) Option {
+	return func(l *Logger) error {
+		if clock == nil {
+			return errors.New("notifyme: clock must not be nil")
+		}
+		l.opts.clock = clock
+		l.now = clock.Now
+		return nil
+	}
+}
+
+// systemTicker adapts a time.Ticker to Ticker
+type systemTicker struct {
+	ticker *time.Ticker
+}
+
+// C returns the channel the ticks are delivered on
+func (t systemTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+// Stop turns the ticker off
+func (t systemTicker) Stop() {
+	t.ticker.Stop()
+}
+
+// newTicker returns a ticker from the configured clock, or a time.Ticker.
+// It must be called with the logger mutex held.
+func (l *Logger) newTicker(d time.Duration) Ticker {
+	if l.opts.clock != nil {
+		return l.opts.clock.NewTicker(d)
+	}
+	return systemTicker{ticker: time.NewTicker(d)}
+}
@@ -0,0 +1,133 @@
+package notifyme
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock that only moves when Advance is called
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+// fakeTicker is a Ticker of a fakeClock
+type fakeTicker struct {
+	clock   *fakeClock
+	c       chan time.Time
+	period  time.Duration
+	next    time.Time
+	stopped bool
+	// done is closed by Stop, so a tick nobody waits for is not sent
+	done chan struct{}
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTicker{clock: c, c: make(chan time.Time), period: d, next: c.now.Add(d), done: make(chan struct{})}
+	c.tickers = append(c.tickers, t)
+	return t
+}
+
+// Advance moves the clock forward by d, delivering every tick that falls
+// due and waiting until each one has been received or its ticker stopped
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	end := c.now.Add(d)
+	c.mu.Unlock()
+	for {
+		c.mu.Lock()
+		var due *fakeTicker
+		for _, t := range c.tickers {
+			if !t.stopped && !t.next.After(end) && (due == nil || t.next.Before(due.next)) {
+				due = t
+			}
+		}
+		if due == nil {
+			c.now = end
+			c.mu.Unlock()
+			return
+		}
+		c.now = due.next
+		due.next = due.next.Add(due.period)
+		tick := c.now
+		c.mu.Unlock()
+		select {
+		case due.c <- tick:
+		case <-due.done:
+		}
+	}
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	if !t.stopped {
+		t.stopped = true
+		close(t.done)
+	}
+	t.clock.mu.Unlock()
+}
+
+// running reports how many tickers have not been stopped
+func (c *fakeClock) running() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for _, t := range c.tickers {
+		if !t.stopped {
+			n++
+		}
+	}
+	return n
+}
+
+func TestWithClock(t *testing.T) {
+	start := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	clock := newFakeClock(start)
+	var buf bytes.Buffer
+	logger := newWriterLogger(LevelInfo, &buf)
+	if err := logger.Configure(WithClock(clock), WithTimeZone(time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Log(LevelInfo, "first")
+	clock.Advance(90 * time.Second)
+	logger.Clone().Log(LevelInfo, "second")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{"INFO: 2024/05/06 07:08:09 ", "INFO: 2024/05/06 07:09:39 "}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d: %q", len(lines), len(want), buf.String())
+	}
+	for i, prefix := range want {
+		if !strings.HasPrefix(lines[i], prefix) {
+			t.Errorf("line %d = %q, want prefix %q", i, lines[i], prefix)
+		}
+	}
+}
+
+func TestWithClockNil(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	if err := logger.Configure(WithClock(nil)); err == nil {
+		t.Error("WithClock(nil) did not fail")
+	}
+}
@@ -0,0 +1,25 @@
+package notifyme
+
+// WithCompactLevels switches text output to single-letter level labels,
+// e.g. "I 2009/01/23 01:23:23 main.go:12: [I] message", to save bytes in
+// high-volume logs. Sinks still receive the numeric level.
+func WithCompactLevels() Option {
+	return func(l *Logger) error {
+		l.opts.compactLevels = true
+		for _, level := range []int{LevelInfo, LevelWarn, LevelError, LevelCritical} {
+			logger, _ := l.levelLogger(level)
+			logger.SetPrefix(l.levelLabel(level) + " ")
+		}
+		return nil
+	}
+}
+
+// levelLabel returns the level name used in text output. It must be called
+// with the logger mutex held.
+func (l *Logger) levelLabel(level int) string {
+	name := levelName(level)
+	if l.opts.compactLevels {
+		return name[:1]
+	}
+	return name
+}
@@ -0,0 +1,54 @@
+package notifyme
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWithCompactLevels(t *testing.T) {
+	tests := []struct {
+		level  int
+		prefix string
+		label  string
+	}{
+		{LevelInfo, "I ", "[I] "},
+		{LevelWarn, "W ", "[W] "},
+		{LevelError, "E ", "[E] "},
+		{LevelCritical, "C ", "[C] "},
+	}
+	for _, tt := range tests {
+		t.Run(levelName(tt.level), func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newWriterLogger(LevelInfo, &buf)
+			if err := logger.Configure(WithCompactLevels()); err != nil {
+				t.Fatal(err)
+			}
+			logger.Log(tt.level, "message")
+			line := buf.String()
+			if !strings.HasPrefix(line, tt.prefix) || !strings.Contains(line, tt.label+"message") {
+				t.Errorf("line = %q, want prefix %q and label %q", line, tt.prefix, tt.label)
+			}
+			if strings.Contains(line, levelName(tt.level)) {
+				t.Errorf("line %q still contains the full level name", line)
+			}
+		})
+	}
+}
+
+func TestCompactLevelsKeepJSONLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newWriterLogger(LevelInfo, &buf)
+	if err := logger.Configure(WithCompactLevels(), WithFormat(FormatJSON)); err != nil {
+		t.Fatal(err)
+	}
+	logger.Log(LevelWarn, "message")
+	var doc map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["level"] != "WARN" {
+		t.Errorf("JSON level = %v, want WARN", doc["level"])
+	}
+}
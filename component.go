@@ -0,0 +1,95 @@
+package notifyme
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// componentLevels holds per-component level overrides. Keys are either exact
+// component names ("db") or wildcard prefixes ("db.*").
+var (
+	componentLevels   = make(map[string]int)
+	componentLevelsMu sync.RWMutex
+)
+
+// componentLevelsGen counts changes to componentLevels, so named loggers
+// know when the level they resolved is out of date
+var componentLevelsGen atomic.Uint64
+
+// resolvedLevel is the component override a named logger found for its
+// name at a generation of componentLevels
+type resolvedLevel struct {
+	gen   uint64
+	level int
+	ok    bool
+}
+
+// SetComponentLevel overrides the level for loggers created with Named.
+// The name may be exact ("db.pool") or a wildcard prefix ("db.*") matching
+// every component below it. Exact matches win over wildcards, and longer
+// wildcards win over shorter ones.
+func SetComponentLevel(name string, level int) {
+	componentLevelsMu.Lock()
+	defer componentLevelsMu.Unlock()
+	componentLevels[name] = level
+	componentLevelsGen.Add(1)
+}
+
+// Named returns a copy of the logger for the given component. Naming an
+// already named logger joins the names with a dot, e.g. "db.pool".
+func (l *Logger) Named(name string) *Logger {
+	clone := l.Clone()
+	if clone.name != "" {
+		name = clone.name + "." + name
+	}
+	clone.name = name
+	return clone
+}
+
+// effectiveLevel returns the level set by WithContext, if any, then the
+// component override for this logger, falling back to its own level. The
+// override is resolved once per change of the overrides. It does not need
+// the logger mutex.
+func (l *Logger) effectiveLevel() int {
+	if l.ctxLevelSet {
+		return l.ctxLevel
+	}
+	if l.name == "" {
+		return int(l.level.Load())
+	}
+	gen := componentLevelsGen.Load()
+	resolved := l.component.Load()
+	if resolved == nil || resolved.gen != gen {
+		level, ok := componentLevel(l.name)
+		resolved = &resolvedLevel{gen: gen, level: level, ok: ok}
+		l.component.Store(resolved)
+	}
+	if resolved.ok {
+		return resolved.level
+	}
+	return int(l.level.Load())
+}
+
+// componentLevel looks up the most specific override for a component
+func componentLevel(name string) (int, bool) {
+	componentLevelsMu.RLock()
+	defer componentLevelsMu.RUnlock()
+
+	if level, ok := componentLevels[name]; ok {
+		return level, true
+	}
+	bestLen := -1
+	bestLevel := 0
+	for pattern, level := range componentLevels {
+		if !strings.HasSuffix(pattern, "*") {
+			continue
+		}
+		prefix := strings.TrimSuffix(pattern, "*")
+		if strings.HasPrefix(name, prefix) && len(prefix) > bestLen {
+			bestLen = len(prefix)
+			bestLevel = level
+		}
+	}
+	return bestLevel, bestLen >= 0
+}
@@ -0,0 +1,133 @@
+package notifyme
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+// setComponentLevels sets overrides for the test and removes them when it
+// finishes
+func setComponentLevels(t *testing.T, levels map[string]int) {
+	for name, level := range levels {
+		SetComponentLevel(name, level)
+	}
+	t.Cleanup(func() {
+		componentLevelsMu.Lock()
+		defer componentLevelsMu.Unlock()
+		for name := range levels {
+			delete(componentLevels, name)
+		}
+		componentLevelsGen.Add(1)
+	})
+}
+
+func TestComponentLevels(t *testing.T) {
+	setComponentLevels(t, map[string]int{
+		"ctest.http":      LevelError,
+		"ctest.db.*":      LevelInfo,
+		"ctest.db.pool.*": LevelCritical,
+		"ctest.db.pool":   LevelWarn,
+	})
+	tests := []struct {
+		component string
+		want      int
+	}{
+		{"ctest.http", LevelError},
+		{"ctest.http.client", LevelWarn},
+		{"ctest.db.query", LevelInfo},
+		{"ctest.db.pool", LevelWarn},
+		{"ctest.db.pool.conn", LevelCritical},
+		{"ctest.cache", LevelWarn},
+	}
+	for _, tt := range tests {
+		t.Run(tt.component, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newWriterLogger(LevelWarn, &buf)
+			named := logger
+			for _, part := range strings.Split(tt.component, ".") {
+				named = named.Named(part)
+			}
+			if got := named.effectiveLevel(); got != tt.want {
+				t.Errorf("effective level = %d, want %d", got, tt.want)
+			}
+			for level := LevelInfo; level <= LevelCritical; level++ {
+				buf.Reset()
+				named.Log(level, "entry")
+				if written := buf.Len() > 0; written != (level >= tt.want) {
+					t.Errorf("level %d written = %v, want %v", level, written, level >= tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestComponentLevelsFilterPerComponent(t *testing.T) {
+	setComponentLevels(t, map[string]int{"ftest.db": LevelInfo, "ftest.http": LevelError})
+	var buf bytes.Buffer
+	app := newWriterLogger(LevelWarn, &buf).Named("ftest")
+	db, http := app.Named("db"), app.Named("http")
+
+	app.Log(LevelInfo, "app info")
+	db.Log(LevelInfo, "db info")
+	http.Log(LevelWarn, "http warn")
+	http.Log(LevelError, "http error")
+
+	out := buf.String()
+	for _, want := range []string{"db info", "http error"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q: %q", want, out)
+		}
+	}
+	for _, unwanted := range []string{"app info", "http warn"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("output contains %q: %q", unwanted, out)
+		}
+	}
+}
+
+func TestComponentLevelUnnamedLogger(t *testing.T) {
+	setComponentLevels(t, map[string]int{"*": LevelCritical})
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	if got := logger.effectiveLevel(); got != LevelInfo {
+		t.Errorf("unnamed logger level = %d, want its own INFO", got)
+	}
+	if got := logger.Named("any").effectiveLevel(); got != LevelCritical {
+		t.Errorf("named logger level = %d, want the wildcard CRITICAL", got)
+	}
+}
+
+func TestComponentLevelResolvedOnce(t *testing.T) {
+	setComponentLevels(t, map[string]int{"rtest.*": LevelError})
+	db := newWriterLogger(LevelWarn, &bytes.Buffer{}).Named("rtest").Named("db")
+	if got := db.effectiveLevel(); got != LevelError {
+		t.Fatalf("level = %d, want the wildcard ERROR", got)
+	}
+	resolved := db.component.Load()
+	db.effectiveLevel()
+	if db.component.Load() != resolved {
+		t.Error("the override was looked up again without a change")
+	}
+
+	setComponentLevels(t, map[string]int{"rtest.db": LevelInfo})
+	if got := db.effectiveLevel(); got != LevelInfo {
+		t.Errorf("level = %d after a new override, want INFO", got)
+	}
+}
+
+func TestComponentLevelWithContext(t *testing.T) {
+	setComponentLevels(t, map[string]int{"wtest.db": LevelError})
+	var buf bytes.Buffer
+	db := newWriterLogger(LevelWarn, &buf).Named("wtest").Named("db")
+	request := db.WithContext(ContextWithLevel(context.Background(), LevelInfo))
+
+	request.Log(LevelInfo, "request info")
+	db.Log(LevelWarn, "db warn")
+	if out := buf.String(); !strings.Contains(out, "request info") || strings.Contains(out, "db warn") {
+		t.Errorf("output = %q, want only the request entry", out)
+	}
+	if got := request.Named("pool").effectiveLevel(); got != LevelInfo {
+		t.Errorf("level below the request logger = %d, want the context INFO", got)
+	}
+}
@@ -0,0 +1,119 @@
+package notifyme
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// LogConfig writes an INFO entry describing the logger's effective
+// configuration as fields: levels, format, outputs, sinks and limits. It is
+// meant to be called once at startup and is written regardless of the
+// level, so a misconfigured level cannot hide it.
+func (l *Logger) LogConfig() {
+	caller := callerAt(1)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.opts.callerFunction {
+		caller.Function = ""
+	}
+	l.writeEntry(l.newEntry(LevelInfo, "Logger configuration", caller, l.configFields()))
+}
+
+// configFields describes the configuration. It must be called with the
+// logger mutex held.
+func (l *Logger) configFields() []Field {
+	fields := []Field{
+		{Key: "min_level", Value: levelName(l.effectiveLevel())},
+		{Key: "writer_level", Value: levelName(l.writerLevel)},
+		{Key: "format", Value: formatName(l.opts.format)},
+		{Key: "output", Value: outputName(l.infoLogger.Writer())},
+	}
+	if len(l.levelFiles) > 0 {
+		files := make([]string, 0, len(l.levelFiles))
+		for _, lf := range l.levelFiles {
+			files = append(files, fmt.Sprintf("%s>=%s", lf.file.path, levelName(lf.minLevel)))
+		}
+		fields = append(fields, Field{Key: "level_files", Value: files})
+	}
+	sinks := make([]string, 0, len(l.sinks))
+	for _, attached := range l.sinks {
+		sinks = append(sinks, fmt.Sprintf("%T", attached.sink))
+	}
+	fields = append(fields, Field{Key: "sinks", Value: sinks})
+	if l.opts.sinkConcurrency > 0 {
+		fields = append(fields, Field{Key: "sink_concurrency", Value: l.opts.sinkConcurrency})
+	}
+	if l.opts.sinkTimeout > 0 {
+		fields = append(fields, Field{Key: "sink_timeout", Value: l.opts.sinkTimeout.String()})
+	}
+	if l.opts.writeDeadline > 0 {
+		fields = append(fields, Field{Key: "write_deadline", Value: l.opts.writeDeadline.String()})
+	}
+	if len(l.routes) > 0 {
+		fields = append(fields, Field{Key: "routes", Value: len(l.routes)})
+	}
+	fields = append(fields, Field{Key: "sampling", Value: l.sampler != nil})
+	if r := l.limiter; r != nil {
+		r.mu.Lock()
+		levels := make([]int, 0, len(r.buckets))
+		for level := range r.buckets {
+			levels = append(levels, level)
+		}
+		sort.Ints(levels)
+		limits := make([]string, 0, len(levels))
+		for _, level := range levels {
+			b := r.buckets[level]
+			limits = append(limits, fmt.Sprintf("%s=%g/s burst %g", levelName(level), b.rate, b.burst))
+		}
+		r.mu.Unlock()
+		fields = append(fields, Field{Key: "rate_limits", Value: limits})
+	}
+	if l.opts.maxMessageLength > 0 {
+		fields = append(fields, Field{Key: "max_message_length", Value: l.opts.maxMessageLength})
+	}
+	if l.opts.maxFields > 0 {
+		fields = append(fields, Field{Key: "max_fields", Value: l.opts.maxFields})
+	}
+	if l.opts.location != nil {
+		fields = append(fields, Field{Key: "time_zone", Value: l.opts.location.String()})
+	}
+	return fields
+}
+
+// formatName returns the name of an output format
+func formatName(format Format) string {
+	switch format {
+	case FormatText:
+		return "text"
+	case FormatJSON:
+		return "json"
+	case FormatECS:
+		return "ecs"
+	case FormatProto:
+		return "proto"
+	case FormatRFC5424:
+		return "rfc5424"
+	default:
+		return fmt.Sprintf("format(%d)", int(format))
+	}
+}
+
+// outputName describes the primary output writer
+func outputName(w io.Writer) string {
+	switch w := w.(type) {
+	case *reopenableFile:
+		return w.path
+	case *os.File:
+		switch w {
+		case os.Stdout:
+			return "stdout"
+		case os.Stderr:
+			return "stderr"
+		}
+		return w.Name()
+	default:
+		return fmt.Sprintf("%T", w)
+	}
+}
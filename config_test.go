@@ -0,0 +1,56 @@
+package notifyme
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLogConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want map[string]interface{}
+	}{
+		{"defaults", nil, map[string]interface{}{
+			"min_level": "ERROR", "writer_level": "INFO", "format": "text",
+			"output": "*bytes.Buffer", "sinks": []string{"*notifyme.RingSink"}, "sampling": false,
+		}},
+		{"configured", []Option{
+			WithFormat(FormatJSON), WithSinkTimeout(2 * time.Second), WithRateLimitBucket(LevelInfo, 5, 10),
+			WithMaxFields(20), WithTimeZone(time.UTC),
+		}, map[string]interface{}{
+			"format": "json", "sink_timeout": "2s", "rate_limits": []string{"INFO=5/s burst 10"},
+			"max_fields": 20, "time_zone": "UTC",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := newWriterLogger(LevelError, &bytes.Buffer{})
+			ring, _ := NewRingSink(1)
+			logger.AddSink(ring)
+			if err := logger.Configure(tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			logger.LogConfig()
+
+			entries := ring.Entries()
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries, want the configuration despite the ERROR level", len(entries))
+			}
+			if entries[0].Level != LevelInfo || entries[0].Message != "Logger configuration" {
+				t.Errorf("entry %s %q", levelName(entries[0].Level), entries[0].Message)
+			}
+			got := make(map[string]interface{}, len(entries[0].Fields))
+			for _, field := range entries[0].Fields {
+				got[field.Key] = field.Value
+			}
+			for key, want := range tt.want {
+				if !reflect.DeepEqual(got[key], want) {
+					t.Errorf("%s = %#v, want %#v", key, got[key], want)
+				}
+			}
+		})
+	}
+}
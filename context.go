@@ -0,0 +1,44 @@
+package notifyme
+
+import "context"
+
+// contextLevelKey is the context key of a request-scoped level
+type contextLevelKey struct{}
+
+// ContextWithLevel returns a context carrying a level for loggers derived
+// with WithContext, e.g. to log INFO for a single tenant's requests while
+// the service logs at WARN
+func ContextWithLevel(ctx context.Context, level int) context.Context {
+	return context.WithValue(ctx, contextLevelKey{}, level)
+}
+
+// WithContextLevelFloor sets the most verbose level a context may set
+// through WithContext. Contexts asking for more are held at the floor.
+func WithContextLevelFloor(level int) Option {
+	return func(l *Logger) error {
+		l.opts.contextFloor = level
+		return nil
+	}
+}
+
+// WithContext returns a copy of the logger for the request carried by ctx.
+// If ctx has a level from ContextWithLevel that is more verbose than the
+// logger's, the copy uses it, but never goes below the floor set with
+// WithContextLevelFloor. The context level takes precedence over
+// component overrides from SetComponentLevel. The original logger is not
+// changed.
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	clone := l.Clone()
+	level, ok := ctx.Value(contextLevelKey{}).(int)
+	if !ok {
+		return clone
+	}
+	if level < clone.opts.contextFloor {
+		level = clone.opts.contextFloor
+	}
+	if level < clone.effectiveLevel() {
+		clone.ctxLevel = level
+		clone.ctxLevelSet = true
+	}
+	return clone
+}
@@ -0,0 +1,75 @@
+package notifyme
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestWithContextLevel(t *testing.T) {
+	tests := []struct {
+		name  string
+		base  int
+		opts  []Option
+		ctx   context.Context
+		level int
+	}{
+		{"no level in context", LevelWarn, nil, context.Background(), LevelWarn},
+		{"raises verbosity", LevelError, nil, ContextWithLevel(context.Background(), LevelInfo), LevelInfo},
+		{"does not lower verbosity", LevelInfo, nil, ContextWithLevel(context.Background(), LevelError), LevelInfo},
+		{"held at the floor", LevelCritical, []Option{WithContextLevelFloor(LevelWarn)}, ContextWithLevel(context.Background(), LevelInfo), LevelWarn},
+		{"above the floor", LevelCritical, []Option{WithContextLevelFloor(LevelWarn)}, ContextWithLevel(context.Background(), LevelError), LevelError},
+		{"wrong value type ignored", LevelError, nil, context.WithValue(context.Background(), contextLevelKey{}, "INFO"), LevelError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			base := newWriterLogger(tt.base, &buf)
+			ring, _ := NewRingSink(10)
+			base.AddSink(ring)
+			if err := base.Configure(tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			request := base.WithContext(tt.ctx)
+			for _, level := range []int{LevelInfo, LevelWarn, LevelError, LevelCritical} {
+				request.Log(level, "request")
+				base.Log(level, "base")
+			}
+
+			var requestLevels, baseLevels []int
+			for _, entry := range ring.Entries() {
+				if entry.Message == "request" {
+					requestLevels = append(requestLevels, entry.Level)
+				} else {
+					baseLevels = append(baseLevels, entry.Level)
+				}
+			}
+			if len(requestLevels) == 0 || requestLevels[0] != tt.level {
+				t.Errorf("request logger wrote levels %v, want %s and above", requestLevels, levelName(tt.level))
+			}
+			if len(baseLevels) == 0 || baseLevels[0] != tt.base {
+				t.Errorf("base logger wrote levels %v, want %s and above", baseLevels, levelName(tt.base))
+			}
+			if got := base.effectiveLevel(); got != tt.base {
+				t.Errorf("base level changed to %s", levelName(got))
+			}
+		})
+	}
+}
+
+func TestWithContextLevelPerRequest(t *testing.T) {
+	base := newWriterLogger(LevelError, &bytes.Buffer{})
+	ring, _ := NewRingSink(10)
+	base.AddSink(ring)
+	debugged := base.WithContext(ContextWithLevel(context.Background(), LevelInfo))
+	other := base.WithContext(context.Background())
+
+	debugged.Log(LevelInfo, "debugged tenant")
+	other.Log(LevelInfo, "other tenant")
+	base.Log(LevelInfo, "service")
+
+	entries := ring.Entries()
+	if len(entries) != 1 || entries[0].Message != "debugged tenant" {
+		t.Errorf("entries = %+v, want only the debugged tenant's", entries)
+	}
+}
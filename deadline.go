@@ -0,0 +1,68 @@
+package notifyme
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// WithWriteDeadline bounds each write to the primary output to d, so a
+// hung network file system cannot freeze the logging call. The write runs
+// in a background goroutine; when it is still running after d the entry is
+// reported to the error handler and sent to the fallback writer, if any.
+// While a timed-out write is still stuck, later entries go straight to the
+// fallback path instead of piling up more writes on the same output. Zero
+// disables the deadline.
+func WithWriteDeadline(d time.Duration) Option {
+	return func(l *Logger) error {
+		if d < 0 {
+			return errors.New("notifyme: write deadline must not be negative")
+		}
+		l.opts.writeDeadline = d
+		if d > 0 && l.opts.writeSlot == nil {
+			l.opts.writeSlot = make(chan struct{}, 1)
+		}
+		return nil
+	}
+}
+
+// writePrimaryWithDeadline renders the entry and writes it to the primary
+// output within the write deadline. It must be called with the logger mutex
+// held.
+func (l *Logger) writePrimaryWithDeadline(logger *log.Logger, entry Entry) {
+	var data []byte
+	if l.opts.format != FormatText {
+		var err error
+		if data, err = l.encode(entry); err != nil {
+			l.errorHandler()(fmt.Errorf("notifyme: encoding entry: %w", err))
+			return
+		}
+	} else {
+		data = []byte(logger.Prefix() + l.formatText(entry) + "\n")
+	}
+
+	select {
+	case l.opts.writeSlot <- struct{}{}:
+	default:
+		l.writeFallback(string(data), errors.New("notifyme: previous write still blocked"))
+		return
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := logger.Writer().Write(data)
+		<-l.opts.writeSlot
+		done <- err
+	}()
+
+	timer := time.NewTimer(l.opts.writeDeadline)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		if err != nil {
+			l.writeFallback(string(data), err)
+		}
+	case <-timer.C:
+		l.writeFallback(string(data), fmt.Errorf("notifyme: write exceeded deadline of %v", l.opts.writeDeadline))
+	}
+}
@@ -0,0 +1,116 @@
+package notifyme
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingWriter blocks every Write until release is closed
+type blockingWriter struct {
+	release chan struct{}
+	mu      sync.Mutex
+	buf     bytes.Buffer
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *blockingWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestWithWriteDeadline(t *testing.T) {
+	tests := []struct {
+		name     string
+		blocked  bool
+		entries  int
+		reported []string
+	}{
+		{"fast writer", false, 2, nil},
+		{"write past the deadline", true, 1, []string{"exceeded deadline of 20ms"}},
+		{"later entries skip the stuck output", true, 3, []string{
+			"exceeded deadline of 20ms", "previous write still blocked", "previous write still blocked",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &blockingWriter{release: make(chan struct{})}
+			if !tt.blocked {
+				close(out.release)
+			}
+			var fallback bytes.Buffer
+			var reported []string
+			logger := newWriterLogger(LevelInfo, out)
+			err := logger.Configure(
+				WithWriteDeadline(20*time.Millisecond),
+				WithFallbackWriter(&fallback),
+				WithErrorHandler(func(err error) { reported = append(reported, err.Error()) }),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < tt.entries; i++ {
+				logger.Log(LevelError, "mount check")
+			}
+
+			if len(reported) != len(tt.reported) {
+				t.Fatalf("reported %q, want %d errors", reported, len(tt.reported))
+			}
+			for i, want := range tt.reported {
+				if !strings.Contains(reported[i], want) {
+					t.Errorf("error %d = %q, want it to mention %q", i, reported[i], want)
+				}
+			}
+			primary := strings.Count(out.String(), "mount check")
+			fellBack := strings.Count(fallback.String(), "mount check")
+			if tt.blocked {
+				if primary != 0 || fellBack != tt.entries {
+					t.Errorf("%d lines written, %d sent to the fallback, want 0 and %d", primary, fellBack, tt.entries)
+				}
+				close(out.release)
+			} else if primary != tt.entries || fellBack != 0 {
+				t.Errorf("%d lines written, %d sent to the fallback, want %d and 0", primary, fellBack, tt.entries)
+			}
+		})
+	}
+}
+
+func TestWithWriteDeadlineRecovers(t *testing.T) {
+	out := &blockingWriter{release: make(chan struct{})}
+	logger := newWriterLogger(LevelInfo, out)
+	if err := logger.Configure(WithWriteDeadline(20*time.Millisecond), WithErrorHandler(func(error) {})); err != nil {
+		t.Fatal(err)
+	}
+	logger.Log(LevelError, "stuck")
+	close(out.release)
+
+	// The stuck write frees the output once it completes
+	deadline := time.Now().Add(time.Second)
+	for !strings.Contains(out.String(), "stuck") && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	for i := 0; i < 100; i++ {
+		logger.Log(LevelError, "recovered")
+		if strings.Contains(out.String(), "recovered") {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Errorf("output = %q, want writes to resume once the stuck write finished", out.String())
+}
+
+func TestWithWriteDeadlineInvalid(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	if err := logger.Configure(WithWriteDeadline(-time.Second)); err == nil {
+		t.Error("Configure accepted a negative deadline")
+	}
+}
@@ -0,0 +1,241 @@
+package notifyme
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// WithMaxDepth limits how deeply nested maps, slices, structs and pointers
+// are expanded in text and JSON output. Containers nested deeper than n
+// levels are rendered as "{...}" or "[...]", and values that refer back to
+// one of their parents are rendered as "<cycle>". Zero disables the limit.
+func WithMaxDepth(n int) Option {
+	return func(l *Logger) error {
+		if n < 0 {
+			return errors.New("notifyme: max depth must not be negative")
+		}
+		l.opts.maxDepth = n
+		return nil
+	}
+}
+
+// formatNested renders a value like %v, stopping at the configured depth and
+// on reference cycles. It must be called with the logger mutex held.
+func (l *Logger) formatNested(value interface{}) string {
+	var b strings.Builder
+	l.writeNested(&b, reflect.ValueOf(value), 0, make(map[uintptr]bool))
+	return b.String()
+}
+
+// writeNested writes v at the given depth. visiting holds the addresses of
+// the maps, slices and pointers currently being expanded.
+func (l *Logger) writeNested(b *strings.Builder, v reflect.Value, depth int, visiting map[uintptr]bool) {
+	if !v.IsValid() {
+		b.WriteString("<nil>")
+		return
+	}
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			b.WriteString("<nil>")
+			return
+		}
+		v = v.Elem()
+	}
+	if v.CanInterface() && !isNilPointer(v) {
+		switch iv := v.Interface().(type) {
+		case []byte:
+			b.WriteString(l.formatBytes(iv))
+			return
+		case error:
+			b.WriteString(iv.Error())
+			return
+		case fmt.Stringer:
+			b.WriteString(iv.String())
+			return
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct, reflect.Ptr:
+	default:
+		fmt.Fprintf(b, "%v", valueInterface(v))
+		return
+	}
+
+	if (v.Kind() == reflect.Map || v.Kind() == reflect.Slice || v.Kind() == reflect.Ptr) && v.IsNil() {
+		fmt.Fprintf(b, "%v", valueInterface(v))
+		return
+	}
+	if v.Kind() == reflect.Map || v.Kind() == reflect.Slice || v.Kind() == reflect.Ptr {
+		addr := v.Pointer()
+		if visiting[addr] {
+			b.WriteString("<cycle>")
+			return
+		}
+		visiting[addr] = true
+		defer delete(visiting, addr)
+	}
+
+	if v.Kind() == reflect.Ptr {
+		b.WriteByte('&')
+		l.writeNested(b, v.Elem(), depth, visiting)
+		return
+	}
+	if depth >= l.opts.maxDepth {
+		if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+			b.WriteString("[...]")
+		} else {
+			b.WriteString("{...}")
+		}
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(valueInterface(keys[i])) < fmt.Sprint(valueInterface(keys[j]))
+		})
+		b.WriteString("map[")
+		for i, key := range keys {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			l.writeNested(b, key, depth+1, visiting)
+			b.WriteByte(':')
+			l.writeNested(b, v.MapIndex(key), depth+1, visiting)
+		}
+		b.WriteByte(']')
+	case reflect.Slice, reflect.Array:
+		b.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			l.writeNested(b, v.Index(i), depth+1, visiting)
+		}
+		b.WriteByte(']')
+	case reflect.Struct:
+		b.WriteByte('{')
+		for i := 0; i < v.NumField(); i++ {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			l.writeNested(b, v.Field(i), depth+1, visiting)
+		}
+		b.WriteByte('}')
+	}
+}
+
+// isNilPointer reports whether v is a nil pointer. Error and String are
+// not called on those since they usually dereference their receiver; the
+// value is rendered as <nil>, as fmt does, or null in JSON.
+func isNilPointer(v reflect.Value) bool {
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// valueInterface returns the value for printing, falling back to its kind
+// for unexported struct fields that cannot be converted to an interface
+func valueInterface(v reflect.Value) interface{} {
+	if v.CanInterface() {
+		return v.Interface()
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return v.Complex()
+	case reflect.String:
+		return v.String()
+	default:
+		return "<" + v.Type().String() + ">"
+	}
+}
+
+// limitDepth converts a value into plain maps, slices and scalars for JSON
+// encoding, summarizing containers below the configured depth and breaking
+// reference cycles the same way formatNested does. Struct fields keep their
+// Go names. It must be called with the logger mutex held.
+func (l *Logger) limitDepth(value interface{}) interface{} {
+	return l.limitValue(reflect.ValueOf(value), 0, make(map[uintptr]bool))
+}
+
+func (l *Logger) limitValue(v reflect.Value, depth int, visiting map[uintptr]bool) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.CanInterface() && !isNilPointer(v) {
+		switch iv := v.Interface().(type) {
+		case []byte:
+			return l.formatBytes(iv)
+		case error:
+			return iv.Error()
+		case fmt.Stringer:
+			return iv.String()
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		addr := v.Pointer()
+		if visiting[addr] {
+			return "<cycle>"
+		}
+		visiting[addr] = true
+		defer delete(visiting, addr)
+	case reflect.Array, reflect.Struct:
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		return fmt.Sprintf("%v", valueInterface(v))
+	default:
+		return valueInterface(v)
+	}
+
+	if v.Kind() == reflect.Ptr {
+		return l.limitValue(v.Elem(), depth, visiting)
+	}
+	if depth >= l.opts.maxDepth {
+		if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+			return "[...]"
+		}
+		return "{...}"
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		out := make(map[string]interface{}, v.Len())
+		for _, key := range v.MapKeys() {
+			out[fmt.Sprint(valueInterface(key))] = l.limitValue(v.MapIndex(key), depth+1, visiting)
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			out[i] = l.limitValue(v.Index(i), depth+1, visiting)
+		}
+		return out
+	default:
+		out := make(map[string]interface{}, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			out[v.Type().Field(i).Name] = l.limitValue(v.Field(i), depth+1, visiting)
+		}
+		return out
+	}
+}
@@ -0,0 +1,154 @@
+package notifyme
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// nestedMap returns a map nested depth levels deep around "leaf"
+func nestedMap(depth int) map[string]interface{} {
+	var value interface{} = "leaf"
+	for i := 0; i < depth; i++ {
+		value = map[string]interface{}{"k": value}
+	}
+	return value.(map[string]interface{})
+}
+
+type depthPoint struct {
+	X, Y int
+	Tags []string
+}
+
+func TestWithMaxDepthText(t *testing.T) {
+	cyclic := map[string]interface{}{"name": "root"}
+	cyclic["self"] = cyclic
+	tests := []struct {
+		name  string
+		depth int
+		value interface{}
+		want  string
+	}{
+		{"within the limit", 5, nestedMap(3), "map[k:map[k:map[k:leaf]]]"},
+		{"cut at depth 2", 2, nestedMap(5), "map[k:map[k:{...}]]"},
+		{"cut at depth 1", 1, nestedMap(5), "map[k:{...}]"},
+		{"slice", 1, [][]int{{1, 2}}, "[[...]]"},
+		{"struct", 1, depthPoint{1, 2, []string{"a"}}, "{1 2 [...]}"},
+		{"pointer", 2, &depthPoint{1, 2, []string{"a"}}, "&{1 2 [a]}"},
+		{"cycle", 10, cyclic, "map[name:root self:<cycle>]"},
+		{"bytes", 1, map[string][]byte{"b": {0xab}}, "map[b:ab]"},
+		{"scalar", 1, 42, "42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newWriterLogger(LevelInfo, &buf)
+			if err := logger.Configure(WithMaxDepth(tt.depth)); err != nil {
+				t.Fatal(err)
+			}
+			logger.With("data", tt.value).Log(LevelInfo, "nested")
+			if got := strings.TrimSuffix(buf.String(), "\n"); !strings.HasSuffix(got, " data="+tt.want) {
+				t.Errorf("line = %q, want data=%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithMaxDepthJSON(t *testing.T) {
+	cyclic := map[string]interface{}{"name": "root"}
+	cyclic["self"] = cyclic
+	tests := []struct {
+		name  string
+		depth int
+		value interface{}
+		want  string
+	}{
+		{"within the limit", 5, nestedMap(2), `{"k":{"k":"leaf"}}`},
+		{"cut at depth 2", 2, nestedMap(5), `{"k":{"k":"{...}"}}`},
+		{"slice", 1, [][]int{{1, 2}}, `["[...]"]`},
+		{"struct", 1, depthPoint{1, 2, []string{"a"}}, `{"Tags":"[...]","X":1,"Y":2}`},
+		{"cycle", 10, cyclic, `{"name":"root","self":"<cycle>"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newWriterLogger(LevelInfo, &buf)
+			if err := logger.Configure(WithFormat(FormatJSON), WithMaxDepth(tt.depth)); err != nil {
+				t.Fatal(err)
+			}
+			logger.With("data", tt.value).Log(LevelInfo, "nested")
+			var doc map[string]json.RawMessage
+			if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+				t.Fatalf("output %q is not JSON: %v", buf.String(), err)
+			}
+			if got, want := canonicalJSONText(t, doc["data"]), canonicalJSONText(t, []byte(tt.want)); got != want {
+				t.Errorf("data = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+// depthError and depthName dereference their receiver, so calling their
+// methods on a nil pointer panics
+type depthError struct{ msg string }
+
+func (e *depthError) Error() string { return e.msg }
+
+type depthName struct{ name string }
+
+func (n *depthName) String() string { return n.name }
+
+func TestWithMaxDepthTypedNil(t *testing.T) {
+	value := map[string]interface{}{"err": (*depthError)(nil), "name": (*depthName)(nil)}
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{FormatText, " data=map[err:<nil> name:<nil>]"},
+		{FormatJSON, `"data":{"err":null,"name":null}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newWriterLogger(LevelInfo, &buf)
+			if err := logger.Configure(WithFormat(tt.format), WithMaxDepth(3)); err != nil {
+				t.Fatal(err)
+			}
+			logger.With("data", value).Log(LevelInfo, "nested")
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("output %q lacks %s", buf.String(), tt.want)
+			}
+		})
+	}
+
+	// Without a depth limit, a JSON error field is rendered by jsonValue
+	var buf bytes.Buffer
+	logger := newWriterLogger(LevelInfo, &buf)
+	if err := logger.Configure(WithFormat(FormatJSON)); err != nil {
+		t.Fatal(err)
+	}
+	logger.With("err", (*depthError)(nil)).Log(LevelInfo, "failed")
+	if !strings.Contains(buf.String(), `"err":null`) {
+		t.Errorf("output %q lacks \"err\":null", buf.String())
+	}
+}
+
+// canonicalJSONText decodes and re-encodes JSON, so equal values compare
+// equal regardless of escaping
+func canonicalJSONText(t *testing.T, data []byte) string {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	out, _ := json.Marshal(v)
+	return string(out)
+}
+
+func TestWithMaxDepthNegative(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	if err := logger.Configure(WithMaxDepth(-1)); err == nil {
+		t.Error("Configure accepted a negative depth")
+	}
+}
@@ -0,0 +1,102 @@
+package notifyme
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ecsVersion is the Elastic Common Schema version FormatECS conforms to
+const ecsVersion = "8.11.0"
+
+// ecsLevelName returns the ECS log.level value for a level
+func ecsLevelName(level int) string {
+	switch level {
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	case LevelCritical:
+		return "critical"
+	default:
+		return strings.ToLower(levelName(level))
+	}
+}
+
+// formatECS renders an entry following the ecs-logging layout: the
+// @timestamp, log.level, message and ecs.version keys come first and
+// dotted, everything else is nested, e.g. {"log":{"logger":"db"}}. Field
+// keys containing dots are nested the same way. When the entry has an event
+// time it becomes @timestamp and the log time is reported as event.created.
+// It must be called with the logger mutex held.
+func (l *Logger) formatECS(entry Entry) ([]byte, error) {
+	timestamp := entry.Time
+	if !entry.EventTime.IsZero() {
+		timestamp = entry.EventTime
+	}
+
+	obj := jsonObject{}
+	obj.add("@timestamp", l.formatRFC3339(timestamp.UTC()))
+	obj.add("log.level", ecsLevelName(entry.Level))
+	obj.add("message", entry.Message)
+	obj.add("ecs.version", ecsVersion)
+
+	// Fields go in first so they cannot displace the standard fields below,
+	// which fall back to dotted keys if a field took their parent's name
+	nested := make(map[string]interface{})
+	for _, field := range entry.Fields {
+		setPath(nested, field.Key, l.jsonValue(field.Value))
+	}
+	setPath(nested, "log.origin.file.name", filepath.Base(entry.Caller.File))
+	setPath(nested, "log.origin.file.line", entry.Caller.Line)
+	if entry.Caller.Function != "" {
+		setPath(nested, "log.origin.function", entry.Caller.Function)
+	}
+	if entry.Name != "" {
+		setPath(nested, "log.logger", entry.Name)
+	}
+	if l.opts.severities != nil {
+		setPath(nested, "event.severity", entry.Severity)
+	}
+	if !entry.EventTime.IsZero() {
+		setPath(nested, "event.created", l.formatRFC3339(entry.Time.UTC()))
+	}
+	if entry.TimesSeen > 1 {
+		setPath(nested, "times_seen", entry.TimesSeen)
+	}
+
+	keys := make([]string, 0, len(nested))
+	for key := range nested {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		obj.add(key, nested[key])
+	}
+	return obj.bytes()
+}
+
+// setPath stores value in m under a dotted path, creating nested maps for
+// each segment. If a segment is already taken by a non-map value, the rest
+// of the path is kept as a dotted key at that level instead.
+func setPath(m map[string]interface{}, path string, value interface{}) {
+	segments := strings.Split(path, ".")
+	for i, segment := range segments[:len(segments)-1] {
+		child, ok := m[segment]
+		if !ok {
+			next := make(map[string]interface{})
+			m[segment] = next
+			m = next
+			continue
+		}
+		next, ok := child.(map[string]interface{})
+		if !ok {
+			m[strings.Join(segments[i:], ".")] = value
+			return
+		}
+		m = next
+	}
+	m[segments[len(segments)-1]] = value
+}
@@ -0,0 +1,115 @@
+package notifyme
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// logECS logs one entry with the ECS format and returns the decoded
+// document and the raw line
+func logECS(t *testing.T, log func(*Logger)) (map[string]interface{}, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	logger := newWriterLogger(LevelInfo, &buf)
+	logger.now = func() time.Time { return time.Date(2024, 3, 9, 14, 5, 6, 0, time.FixedZone("CET", 3600)) }
+	if err := logger.Configure(WithFormat(FormatECS)); err != nil {
+		t.Fatal(err)
+	}
+	log(logger)
+	var doc map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output %q is not JSON: %v", buf.String(), err)
+	}
+	return doc, buf.String()
+}
+
+// ecsPath returns the value at a dotted path in a decoded document
+func ecsPath(doc map[string]interface{}, dotted string) interface{} {
+	var value interface{} = doc
+	for _, segment := range strings.Split(dotted, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[segment]
+	}
+	return value
+}
+
+func TestECSRequiredFields(t *testing.T) {
+	doc, line := logECS(t, func(l *Logger) {
+		l.Named("db").With("user.id", "42", "http.request.method", "GET").Log(LevelWarn, "slow query")
+	})
+	if want := `{"@timestamp":"2024-03-09T13:05:06Z","log.level":"warn","message":"slow query","ecs.version":"8.11.0",`; !strings.HasPrefix(line, want) {
+		t.Errorf("line = %q, want prefix %q", line, want)
+	}
+
+	tests := []struct {
+		path string
+		want interface{}
+	}{
+		{"log.logger", "db"},
+		{"log.origin.file.name", "ecs_test.go"},
+		{"user.id", "42"},
+		{"http.request.method", "GET"},
+	}
+	for _, tt := range tests {
+		if got := ecsPath(doc, tt.path); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+	if line, ok := ecsPath(doc, "log.origin.file.line").(float64); !ok || line <= 0 {
+		t.Errorf("log.origin.file.line = %v, want a line number", ecsPath(doc, "log.origin.file.line"))
+	}
+}
+
+func TestECSLevels(t *testing.T) {
+	tests := []struct {
+		level int
+		want  string
+	}{
+		{LevelInfo, "info"},
+		{LevelWarn, "warn"},
+		{LevelError, "error"},
+		{LevelCritical, "critical"},
+	}
+	for _, tt := range tests {
+		doc, _ := logECS(t, func(l *Logger) { l.Log(tt.level, "m") })
+		if doc["log.level"] != tt.want {
+			t.Errorf("log.level for %s = %v, want %s", levelName(tt.level), doc["log.level"], tt.want)
+		}
+	}
+}
+
+func TestECSEventTime(t *testing.T) {
+	eventTime := time.Date(2024, 3, 9, 10, 0, 0, 0, time.UTC)
+	doc, _ := logECS(t, func(l *Logger) { l.LogAt(eventTime, LevelInfo, "backfilled") })
+	if doc["@timestamp"] != "2024-03-09T10:00:00Z" || ecsPath(doc, "event.created") != "2024-03-09T13:05:06Z" {
+		t.Errorf("@timestamp = %v and event.created = %v", doc["@timestamp"], ecsPath(doc, "event.created"))
+	}
+}
+
+func TestECSFieldCollision(t *testing.T) {
+	doc, _ := logECS(t, func(l *Logger) { l.With("log", "plain", "message", "field").Log(LevelInfo, "entry") })
+	if doc["message"] != "entry" {
+		t.Errorf("message = %v, want the entry message", doc["message"])
+	}
+	if doc["log"] != "plain" || doc["log.origin.file.name"] != "ecs_test.go" {
+		t.Errorf("document = %v, want the log field kept and the origin under a dotted key", doc)
+	}
+}
+
+func TestSetPath(t *testing.T) {
+	m := map[string]interface{}{}
+	setPath(m, "a.b.c", 1)
+	setPath(m, "a.b.d", 2)
+	setPath(m, "x", "leaf")
+	setPath(m, "x.y", 3)
+	got, _ := json.Marshal(m)
+	if want := `{"a":{"b":{"c":1,"d":2}},"x":"leaf","x.y":3}`; string(got) != want {
+		t.Errorf("setPath built %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,260 @@
+package notifyme
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults used by ElasticSink when the config leaves them unset
+const (
+	defaultElasticBatchSize     = 100
+	defaultElasticFlushInterval = 5 * time.Second
+)
+
+// ElasticConfig configures an ElasticSink
+type ElasticConfig struct {
+	// Endpoint is the base URL of the cluster, e.g. https://localhost:9200
+	Endpoint string
+	// Index is the target index name. It is passed through time.Format with
+	// the entry's UTC time, so "logs-2006.01.02" yields daily indices. Names
+	// containing layout tokens (digits, "Jan", "Mon", ...) are substituted
+	// too, so keep literal parts free of them.
+	Index string
+	// Username and Password enable basic authentication when set
+	Username string
+	Password string
+	// APIKey is sent as an "ApiKey" authorization header when set
+	APIKey string
+	// BatchSize is the number of entries that triggers a flush
+	BatchSize int
+	// FlushInterval is the maximum time entries wait before being sent
+	FlushInterval time.Duration
+	// Client is the HTTP client used for requests; configure its transport
+	// for custom TLS. Defaults to http.DefaultClient.
+	Client *http.Client
+	// OnError receives errors from background flushes. Defaults to stderr.
+	OnError func(error)
+}
+
+// ElasticSink sends entries to OpenSearch or Elasticsearch using the _bulk
+// API, batching them and flushing on size or interval
+type ElasticSink struct {
+	config  ElasticConfig
+	mu      sync.Mutex
+	pending []Entry
+	closed  bool
+	flushCh chan struct{}
+	done    chan struct{}
+	wg      sync.WaitGroup
+	stop    sync.Once
+}
+
+// errElasticClosed is returned by Write after Close
+var errElasticClosed = errors.New("notifyme: elastic sink is closed")
+
+// NewElasticSink creates an ElasticSink and starts its background flusher
+func NewElasticSink(config ElasticConfig) (*ElasticSink, error) {
+	if config.Endpoint == "" {
+		return nil, errors.New("notifyme: elastic endpoint must not be empty")
+	}
+	if config.Index == "" {
+		return nil, errors.New("notifyme: elastic index must not be empty")
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultElasticBatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaultElasticFlushInterval
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	if config.OnError == nil {
+		config.OnError = reportError
+	}
+	config.Endpoint = strings.TrimRight(config.Endpoint, "/")
+
+	s := &ElasticSink{
+		config:  config,
+		flushCh: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.run()
+	return s, nil
+}
+
+// Write queues the entry, waking the flusher once a full batch is pending.
+// It fails once the sink is closed.
+func (s *ElasticSink) Write(entry Entry) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return errElasticClosed
+	}
+	s.pending = append(s.pending, entry)
+	full := len(s.pending) >= s.config.BatchSize
+	s.mu.Unlock()
+
+	if full {
+		select {
+		case s.flushCh <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Flush sends all pending entries synchronously
+func (s *ElasticSink) Flush() error {
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	return s.send(batch)
+}
+
+// Close stops the background flusher and sends any remaining entries.
+// Calling it again does nothing.
+func (s *ElasticSink) Close() error {
+	var err error
+	s.stop.Do(func() {
+		s.mu.Lock()
+		s.closed = true
+		s.mu.Unlock()
+		close(s.done)
+		s.wg.Wait()
+		err = s.Flush()
+	})
+	return err
+}
+
+// run flushes pending entries on every interval tick or batch signal
+func (s *ElasticSink) run() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.flushCh:
+		case <-s.done:
+			return
+		}
+		if err := s.Flush(); err != nil {
+			s.config.OnError(err)
+		}
+	}
+}
+
+// send posts a batch of entries to the _bulk endpoint
+func (s *ElasticSink) send(batch []Entry) error {
+	body, err := s.bulkBody(batch)
+	if err != nil || len(body) == 0 {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.config.Endpoint+"/_bulk", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if s.config.APIKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+s.config.APIKey)
+	} else if s.config.Username != "" {
+		req.SetBasicAuth(s.config.Username, s.config.Password)
+	}
+
+	resp, err := s.config.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("notifyme: elastic bulk request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("notifyme: reading elastic bulk response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("notifyme: elastic bulk request returned %s: %s", resp.Status, respBody)
+	}
+
+	var result struct {
+		Errors bool `json:"errors"`
+	}
+	if err := json.Unmarshal(respBody, &result); err == nil && result.Errors {
+		return fmt.Errorf("notifyme: elastic bulk request had item errors: %s", respBody)
+	}
+	return nil
+}
+
+// bulkBody renders the batch as NDJSON action/document pairs. A document
+// that cannot be encoded is sent with its fields as text instead, so it
+// does not hold back the rest of the batch.
+func (s *ElasticSink) bulkBody(batch []Entry) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, entry := range batch {
+		doc, err := json.Marshal(elasticDocument(entry, defaultJSONValue))
+		if err != nil {
+			doc, err = json.Marshal(elasticDocument(entry, defaultTextValue))
+		}
+		if err != nil {
+			s.config.OnError(fmt.Errorf("notifyme: encoding elastic document: %w", err))
+			continue
+		}
+		action := map[string]map[string]string{
+			"index": {"_index": s.indexName(entry.Time)},
+		}
+		if err := enc.Encode(action); err != nil {
+			return nil, err
+		}
+		buf.Write(doc)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// indexName resolves the date pattern in the configured index name
+func (s *ElasticSink) indexName(t time.Time) string {
+	return t.UTC().Format(s.config.Index)
+}
+
+// elasticDocument converts an entry into the indexed document, passing
+// field values through value. Fields are added at the top level; the
+// standard keys take precedence over fields with the same name.
+func elasticDocument(entry Entry, value func(interface{}) interface{}) map[string]interface{} {
+	doc := make(map[string]interface{}, len(entry.Fields)+8)
+	for _, field := range entry.Fields {
+		doc[field.Key] = value(field.Value)
+	}
+	doc["@timestamp"] = entry.Time.UTC().Format(time.RFC3339Nano)
+	doc["level"] = levelName(entry.Level)
+	doc["severity"] = entry.Severity
+	doc["message"] = entry.Message
+	doc["caller"] = entry.Caller.String()
+	if entry.Caller.Function != "" {
+		doc["func"] = entry.Caller.Function
+	}
+	if !entry.EventTime.IsZero() {
+		doc["event_ts"] = entry.EventTime.UTC().Format(time.RFC3339Nano)
+	}
+	if entry.Name != "" {
+		doc["logger"] = entry.Name
+	}
+	if entry.TimesSeen > 1 {
+		doc["times_seen"] = entry.TimesSeen
+	}
+	return doc
+}
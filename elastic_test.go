@@ -0,0 +1,292 @@
+package notifyme
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// bulkRequest is a request received by elasticServer
+type bulkRequest struct {
+	path   string
+	header http.Header
+	lines  []map[string]interface{}
+}
+
+// elasticServer records _bulk requests and answers with response
+type elasticServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []bulkRequest
+	received chan struct{}
+}
+
+func newElasticServer(t *testing.T, response string) *elasticServer {
+	s := &elasticServer{received: make(chan struct{}, 10)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := bulkRequest{path: r.URL.Path, header: r.Header}
+		body, _ := io.ReadAll(r.Body)
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		for scanner.Scan() {
+			var line map[string]interface{}
+			if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+				t.Errorf("bulk line %q is not JSON: %v", scanner.Text(), err)
+			}
+			req.lines = append(req.lines, line)
+		}
+		if !bytes.HasSuffix(body, []byte("\n")) {
+			t.Errorf("bulk body does not end with a newline: %q", body)
+		}
+		s.mu.Lock()
+		s.requests = append(s.requests, req)
+		s.mu.Unlock()
+		io.WriteString(w, response)
+		s.received <- struct{}{}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// newTestElasticSink creates a sink that only flushes when asked to
+func newTestElasticSink(t *testing.T, s *elasticServer, config ElasticConfig) *ElasticSink {
+	config.Endpoint = s.URL + "/"
+	config.Client = s.Client()
+	if config.Index == "" {
+		config.Index = "logs"
+	}
+	if config.FlushInterval == 0 {
+		config.FlushInterval = time.Hour
+	}
+	if config.OnError == nil {
+		config.OnError = func(err error) { t.Errorf("unexpected flush error: %v", err) }
+	}
+	sink, err := NewElasticSink(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sink.Close() })
+	return sink
+}
+
+func TestElasticBulkBody(t *testing.T) {
+	server := newElasticServer(t, `{"errors":false}`)
+	sink := newTestElasticSink(t, server, ElasticConfig{Index: "logs-2006.01.02"})
+	day := time.Date(2024, 3, 9, 23, 30, 0, 0, time.UTC)
+	entries := []Entry{
+		{
+			Level: LevelWarn, Message: "slow query", Time: day, Severity: LevelWarn,
+			Caller: Caller{File: "db.go", Line: 7}, Name: "db",
+			Fields: []Field{{Key: "table", Value: "users"}, {Key: "rows", Value: 3}, {Key: "message", Value: "hidden"}},
+		},
+		{
+			Level: LevelError, Message: "next day", Time: day.Add(time.Hour), Severity: LevelError,
+			Caller: Caller{File: "db.go", Line: 9}, TimesSeen: 4,
+		},
+	}
+	for _, entry := range entries {
+		if err := sink.Write(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sink.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(server.requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(server.requests))
+	}
+	req := server.requests[0]
+	if req.path != "/_bulk" || req.header.Get("Content-Type") != "application/x-ndjson" {
+		t.Errorf("request to %s with content type %q", req.path, req.header.Get("Content-Type"))
+	}
+	want := []map[string]interface{}{
+		{"index": map[string]interface{}{"_index": "logs-2024.03.09"}},
+		{
+			"@timestamp": "2024-03-09T23:30:00Z", "level": "WARN", "severity": float64(LevelWarn),
+			"message": "slow query", "caller": "db.go:7", "logger": "db", "table": "users", "rows": float64(3),
+		},
+		{"index": map[string]interface{}{"_index": "logs-2024.03.10"}},
+		{
+			"@timestamp": "2024-03-10T00:30:00Z", "level": "ERROR", "severity": float64(LevelError),
+			"message": "next day", "caller": "db.go:9", "times_seen": float64(4),
+		},
+	}
+	if len(req.lines) != len(want) {
+		t.Fatalf("got %d bulk lines, want %d: %v", len(req.lines), len(want), req.lines)
+	}
+	for i := range want {
+		got, _ := json.Marshal(req.lines[i])
+		exp, _ := json.Marshal(want[i])
+		if !bytes.Equal(got, exp) {
+			t.Errorf("bulk line %d = %s, want %s", i, got, exp)
+		}
+	}
+}
+
+func TestElasticIndexName(t *testing.T) {
+	at := time.Date(2024, 12, 31, 23, 0, 0, 0, time.FixedZone("EST", -5*3600))
+	tests := []struct {
+		index string
+		want  string
+	}{
+		{"logs", "logs"},
+		{"logs-2006.01.02", "logs-2025.01.01"},
+		{"logs-2006.01", "logs-2025.01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.index, func(t *testing.T) {
+			sink := &ElasticSink{config: ElasticConfig{Index: tt.index}}
+			if got := sink.indexName(at); got != tt.want {
+				t.Errorf("indexName = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestElasticAuthentication(t *testing.T) {
+	tests := []struct {
+		name   string
+		config ElasticConfig
+		want   string
+	}{
+		{"none", ElasticConfig{}, ""},
+		{"basic", ElasticConfig{Username: "u", Password: "p"}, "Basic dTpw"},
+		{"api key", ElasticConfig{APIKey: "k", Username: "u"}, "ApiKey k"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newElasticServer(t, `{"errors":false}`)
+			sink := newTestElasticSink(t, server, tt.config)
+			sink.Write(Entry{Message: "m"})
+			if err := sink.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			if got := server.requests[0].header.Get("Authorization"); got != tt.want {
+				t.Errorf("Authorization = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestElasticFlushOnBatchSize(t *testing.T) {
+	server := newElasticServer(t, `{"errors":false}`)
+	sink := newTestElasticSink(t, server, ElasticConfig{BatchSize: 3})
+	for i := 0; i < 3; i++ {
+		sink.Write(Entry{Message: "m"})
+	}
+	select {
+	case <-server.received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("a full batch was not sent")
+	}
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if n := len(server.requests[0].lines); n != 6 {
+		t.Errorf("batch had %d lines, want 6", n)
+	}
+}
+
+func TestElasticFlushOnInterval(t *testing.T) {
+	server := newElasticServer(t, `{"errors":false}`)
+	sink := newTestElasticSink(t, server, ElasticConfig{FlushInterval: 10 * time.Millisecond})
+	sink.Write(Entry{Message: "m"})
+	select {
+	case <-server.received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("pending entries were not sent on the interval")
+	}
+}
+
+func TestElasticErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     string
+	}{
+		{"item errors", `{"errors":true,"items":[]}`, "item errors"},
+		{"non-JSON response", `ok`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newElasticServer(t, tt.response)
+			sink := newTestElasticSink(t, server, ElasticConfig{})
+			sink.Write(Entry{Message: "m"})
+			err := sink.Flush()
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Flush error = %v, want none", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Flush error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestElasticUnencodableFields(t *testing.T) {
+	server := newElasticServer(t, `{"errors":false}`)
+	sink := newTestElasticSink(t, server, ElasticConfig{})
+	sink.Write(Entry{Message: "odd", Fields: []Field{
+		{Key: "err", Value: errors.New("boom")},
+		{Key: "nan", Value: math.NaN()},
+		{Key: "fn", Value: func() {}},
+	}})
+	sink.Write(Entry{Message: "plain"})
+	if err := sink.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	lines := server.requests[0].lines
+	if len(lines) != 4 {
+		t.Fatalf("got %d bulk lines, want 4", len(lines))
+	}
+	if doc := lines[1]; doc["err"] != "boom" || doc["nan"] != "NaN" {
+		t.Errorf("document = %v", doc)
+	}
+	if lines[3]["message"] != "plain" {
+		t.Errorf("second document = %v", lines[3])
+	}
+}
+
+func TestElasticClose(t *testing.T) {
+	server := newElasticServer(t, `{"errors":false}`)
+	sink := newTestElasticSink(t, server, ElasticConfig{})
+	sink.Write(Entry{Message: "pending"})
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(server.requests) != 1 {
+		t.Fatalf("Close sent %d requests, want 1", len(server.requests))
+	}
+	if err := sink.Close(); err != nil {
+		t.Errorf("second Close = %v", err)
+	}
+	if err := sink.Write(Entry{Message: "late"}); !errors.Is(err, errElasticClosed) {
+		t.Errorf("Write after Close = %v, want %v", err, errElasticClosed)
+	}
+}
+
+func TestNewElasticSinkInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		config ElasticConfig
+	}{
+		{"no endpoint", ElasticConfig{Index: "logs"}},
+		{"no index", ElasticConfig{Endpoint: "http://localhost:9200"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewElasticSink(tt.config); err == nil {
+				t.Error("NewElasticSink accepted the config")
+			}
+		})
+	}
+}
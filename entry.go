@@ -0,0 +1,185 @@
+package notifyme
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Entry represents a single log event. It is built once per logging call
+// and the same value is rendered to the primary output and passed to sinks.
+type Entry struct {
+	Level   int
+	Message string
+	Time    time.Time
+	Caller  Caller
+	Fields  []Field
+	// EventTime is when the logged event happened, if it was given to LogAt
+	EventTime time.Time
+	// Severity is the level translated by WithSeverityMapping
+	Severity int
+	// Name is the component name of a logger created with Named
+	Name string
+	// TimesSeen is the number of entries this one represents: itself plus
+	// any siblings dropped by sampling since the previous entry with the
+	// same sampling key. It is 1 for entries that were not sampled.
+	TimesSeen int
+}
+
+// Caller is the source location of a logging call
+type Caller struct {
+	File string
+	Line int
+	// Function is the fully qualified function name, set only when the
+	// logger was configured with WithCallerFunction
+	Function string
+	// trimmed is set when WithCallerTrimPrefix shortened File, which is then
+	// shown in full instead of by its base name
+	trimmed bool
+}
+
+// String returns the caller as "file.go:line" using the file's base name,
+// or the whole path if it was shortened by WithCallerTrimPrefix
+func (c Caller) String() string {
+	if c.trimmed {
+		return fmt.Sprintf("%s:%d", c.File, c.Line)
+	}
+	return fmt.Sprintf("%s:%d", filepath.Base(c.File), c.Line)
+}
+
+// Field is a structured key/value pair attached to an entry
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// setField sets key to value in fields, replacing the value of an existing
+// field with the same key in place and appending otherwise, so every key
+// appears once and the last value set wins
+func setField(fields []Field, key string, value interface{}) []Field {
+	for i := range fields {
+		if fields[i].Key == key {
+			fields[i].Value = value
+			return fields
+		}
+	}
+	return append(fields, Field{Key: key, Value: value})
+}
+
+// dedupeFields returns fields with every key kept once, at its first
+// position and with its last value
+func dedupeFields(fields []Field) []Field {
+	var out []Field
+	for _, field := range fields {
+		out = setField(out, field.Key, field.Value)
+	}
+	return out
+}
+
+// WithFields returns a copy of the logger that attaches the given fields to
+// every entry. Fields are added in key order after any the logger already
+// carries; a key the logger already has keeps its position and takes the
+// new value.
+func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	clone := l.Clone()
+	for _, key := range keys {
+		clone.fields = setField(clone.fields, key, fields[key])
+	}
+	return clone
+}
+
+// badKey is the key With gives a value missing its key
+const badKey = "!BADKEY"
+
+// With returns a copy of the logger that attaches the alternating key/value
+// pairs to every entry, e.g. logger.With("user", id, "attempt", n).Log(...).
+// Fields are added in the given order, except that a key the logger
+// already has keeps its position and takes the new value. Keys that are
+// not strings are formatted with fmt.Sprint. A trailing value without a
+// key is kept under "!BADKEY" so the mistake shows up in the output.
+func (l *Logger) With(keyvals ...interface{}) *Logger {
+	clone := l.Clone()
+	for i := 0; i < len(keyvals); i += 2 {
+		if i+1 == len(keyvals) {
+			clone.fields = setField(clone.fields, badKey, keyvals[i])
+			break
+		}
+		key, ok := keyvals[i].(string)
+		if !ok {
+			key = fmt.Sprint(keyvals[i])
+		}
+		clone.fields = setField(clone.fields, key, keyvals[i+1])
+	}
+	return clone
+}
+
+// formatText renders an entry as a text line, without the level prefix of
+// the output. It must be called with the logger mutex held.
+func (l *Logger) formatText(entry Entry) string {
+	var text string
+	if l.opts.textTemplate != nil {
+		text = l.renderTemplate(entry)
+	} else {
+		var b strings.Builder
+		b.WriteString(l.formatTime(entry.Time))
+		b.WriteByte(' ')
+		writeCaller(&b, entry.Caller)
+		b.WriteString(": [")
+		b.WriteString(l.levelLabel(entry.Level))
+		b.WriteString("] ")
+		writeMessage(&b, entry)
+		l.writeFields(&b, entry)
+		text = b.String()
+	}
+	if l.opts.sanitizeControl {
+		text = sanitizeControlChars(text)
+	}
+	if l.opts.escapeNonASCII {
+		text = escapeNonASCII(text)
+	}
+	return l.replaceNewlines(text)
+}
+
+// writeCaller writes the caller location and, if set, its function
+func writeCaller(b *strings.Builder, caller Caller) {
+	b.WriteString(caller.String())
+	if caller.Function != "" {
+		b.WriteByte(' ')
+		b.WriteString(caller.Function)
+	}
+}
+
+// writeMessage writes the message, preceded by the logger name if any
+func writeMessage(b *strings.Builder, entry Entry) {
+	if entry.Name != "" {
+		b.WriteString(entry.Name)
+		b.WriteString(": ")
+	}
+	b.WriteString(entry.Message)
+}
+
+// writeFields writes the fields and the event time and sample count as
+// " key=value" pairs. It must be called with the logger mutex held.
+func (l *Logger) writeFields(b *strings.Builder, entry Entry) {
+	for _, field := range entry.Fields {
+		b.WriteByte(' ')
+		b.WriteString(field.Key)
+		b.WriteByte('=')
+		b.WriteString(l.formatValue(field.Value))
+	}
+	if !entry.EventTime.IsZero() {
+		b.WriteString(" event_ts=")
+		b.WriteString(l.formatTime(entry.EventTime))
+	}
+	if entry.TimesSeen > 1 {
+		fmt.Fprintf(b, " times_seen=%d", entry.TimesSeen)
+	}
+}
@@ -0,0 +1,368 @@
+package notifyme
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncodeEntryText(t *testing.T) {
+	at := time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC)
+	tests := []struct {
+		name  string
+		entry Entry
+		want  string
+	}{
+		{
+			"message only",
+			Entry{Level: LevelInfo, Message: "started", Time: at, Caller: Caller{File: "/src/app/main.go", Line: 12}},
+			"INFO: 2024/03/09 14:05:06 main.go:12: [INFO] started\n",
+		},
+		{
+			"fields",
+			Entry{
+				Level: LevelError, Message: "query failed", Time: at, Caller: Caller{File: "db.go", Line: 3},
+				Fields: []Field{{Key: "table", Value: "users"}, {Key: "attempt", Value: 2}},
+			},
+			"ERROR: 2024/03/09 14:05:06 db.go:3: [ERROR] query failed table=users attempt=2\n",
+		},
+		{
+			"function",
+			Entry{Level: LevelWarn, Message: "slow", Time: at, Caller: Caller{File: "db.go", Line: 3, Function: "app.Query"}},
+			"WARN: 2024/03/09 14:05:06 db.go:3 app.Query: [WARN] slow\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeEntry(tt.entry, FormatText)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("EncodeEntry = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeEntryUnknownFormat(t *testing.T) {
+	if _, err := EncodeEntry(Entry{}, Format(99)); err == nil {
+		t.Error("EncodeEntry accepted an unknown format")
+	}
+}
+
+func TestCallerString(t *testing.T) {
+	tests := []struct {
+		caller Caller
+		want   string
+	}{
+		{Caller{File: "/src/app/main.go", Line: 7}, "main.go:7"},
+		{Caller{File: "main.go", Line: 0}, "main.go:0"},
+		{Caller{File: "app/main.go", Line: 7, trimmed: true}, "app/main.go:7"},
+	}
+	for _, tt := range tests {
+		if got := tt.caller.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.caller, got, tt.want)
+		}
+	}
+}
+
+func TestSinksReceiveRenderedEntry(t *testing.T) {
+	var buf bytes.Buffer
+	at := time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC)
+	logger := newWriterLogger(LevelInfo, &buf)
+	logger.now = func() time.Time { return at }
+	ring, _ := NewRingSink(1)
+	logger.AddSink(ring)
+
+	logger.WithFields(map[string]interface{}{"user": "ann", "attempt": 1}).Log(LevelWarn, "login failed", 3)
+
+	entries := ring.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	entry := entries[0]
+	if entry.Level != LevelWarn || entry.Message != "login failed 3" || !entry.Time.Equal(at) || entry.TimesSeen != 1 {
+		t.Errorf("entry = %+v", entry)
+	}
+	if !strings.HasSuffix(entry.Caller.File, "entry_test.go") || entry.Caller.Line == 0 {
+		t.Errorf("caller = %+v, want this test file", entry.Caller)
+	}
+	if want := []Field{{Key: "attempt", Value: 1}, {Key: "user", Value: "ann"}}; !reflect.DeepEqual(entry.Fields, want) {
+		t.Errorf("fields = %v, want %v", entry.Fields, want)
+	}
+	encoded, err := EncodeEntry(entry, FormatText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != string(encoded) {
+		t.Errorf("primary output %q differs from the encoded sink entry %q", buf.String(), encoded)
+	}
+}
+
+func TestSetField(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []Field
+		key    string
+		want   []Field
+	}{
+		{"append", []Field{{Key: "a", Value: 1}}, "b", []Field{{Key: "a", Value: 1}, {Key: "b", Value: "new"}}},
+		{"replace in place", []Field{{Key: "a", Value: 1}, {Key: "b", Value: 2}}, "a", []Field{{Key: "a", Value: "new"}, {Key: "b", Value: 2}}},
+		{"empty", nil, "a", []Field{{Key: "a", Value: "new"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := setField(tt.fields, tt.key, "new"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("setField = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteEntry(t *testing.T) {
+	at := time.Date(2023, 7, 1, 8, 30, 0, 0, time.UTC)
+	relayed := Entry{
+		Level:     LevelError,
+		Message:   "payment declined",
+		Time:      at,
+		Caller:    Caller{File: "/srv/billing/charge.go", Line: 88},
+		Name:      "billing",
+		Fields:    []Field{{Key: "order", Value: "A-17"}, {Key: "amount", Value: 12.5}},
+		TimesSeen: 1,
+	}
+	tests := []struct {
+		name   string
+		format Format
+		want   string
+	}{
+		{"text", FormatText, "ERROR: 2023/07/01 08:30:00 charge.go:88: [ERROR] billing: payment declined order=A-17 amount=12.5\n"},
+		{"json", FormatJSON, `{"ts":"2023-07-01T08:30:00Z","level":"ERROR","msg":"payment declined","caller":"charge.go:88","logger":"billing","order":"A-17","amount":12.5}` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newWriterLogger(LevelInfo, &buf)
+			logger.now = func() time.Time { return time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC) }
+			ring, _ := NewRingSink(1)
+			logger.AddSink(ring)
+			if err := logger.Configure(WithFormat(tt.format), WithTimeZone(time.UTC)); err != nil {
+				t.Fatal(err)
+			}
+			logger.With("relay", "edge-1").WriteEntry(relayed)
+
+			if buf.String() != tt.want {
+				t.Errorf("output %q, want %q", buf.String(), tt.want)
+			}
+			if entries := ring.Entries(); len(entries) != 1 || !reflect.DeepEqual(entries[0], relayed) {
+				t.Errorf("sink got %+v, want %+v", entries, relayed)
+			}
+		})
+	}
+}
+
+func TestWriteEntryFilteringAndRepeatedKeys(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newWriterLogger(LevelWarn, &buf)
+	ring, _ := NewRingSink(2)
+	logger.AddSink(ring)
+
+	logger.WriteEntry(Entry{Level: LevelInfo, Message: "filtered"})
+	logger.WriteEntry(Entry{Level: LevelWarn, Message: "kept", Fields: []Field{
+		{Key: "k", Value: 1}, {Key: "other", Value: true}, {Key: "k", Value: 2},
+	}})
+
+	entries := ring.Entries()
+	if len(entries) != 1 || entries[0].Message != "kept" {
+		t.Fatalf("entries = %+v, want only the WARN entry", entries)
+	}
+	if want := []Field{{Key: "k", Value: 2}, {Key: "other", Value: true}}; !reflect.DeepEqual(entries[0].Fields, want) {
+		t.Errorf("fields = %v, want %v", entries[0].Fields, want)
+	}
+}
+
+func TestRepeatedKeysLastWriterWins(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(*Logger)
+		want string
+	}{
+		{"child overrides default", func(l *Logger) { l.With("region", "child").Log(LevelInfo, "m") }, "child"},
+		{"call overrides child", func(l *Logger) {
+			l.With("region", "child").NewEvent(LevelInfo).Str("region", "call").Msg("m")
+		}, "call"},
+		{"repeated within a call", func(l *Logger) {
+			l.NewEvent(LevelInfo).Str("region", "first").Str("region", "second").Msg("m")
+		}, "second"},
+		{"repeated within With", func(l *Logger) { l.With("region", "a", "region", "b").Log(LevelInfo, "m") }, "b"},
+		{"default only", func(l *Logger) { l.Log(LevelInfo, "m") }, "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, format := range []Format{FormatText, FormatJSON} {
+				var buf bytes.Buffer
+				base := newWriterLogger(LevelInfo, &buf)
+				if err := base.Configure(WithFormat(format)); err != nil {
+					t.Fatal(err)
+				}
+				logger := base.WithFields(map[string]interface{}{"region": "default", "zone": 1})
+				tt.log(logger)
+
+				out := buf.String()
+				if format == FormatText {
+					if strings.Count(out, "region=") != 1 || !strings.Contains(out, " region="+tt.want+" ") {
+						t.Errorf("text line %q, want region=%s once", out, tt.want)
+					}
+					continue
+				}
+				keys := jsonObjectKeys(t, buf.Bytes())
+				if want := []string{"ts", "level", "msg", "caller", "region", "zone"}; !reflect.DeepEqual(keys, want) {
+					t.Errorf("JSON keys = %v, want %v", keys, want)
+				}
+				if !strings.Contains(out, `"region":"`+tt.want+`"`) {
+					t.Errorf("JSON line %q, want region %s", out, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestJSONSkipsFieldsNamedLikeStandardKeys(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newWriterLogger(LevelInfo, &buf)
+	if err := logger.Configure(WithFormat(FormatJSON)); err != nil {
+		t.Fatal(err)
+	}
+	logger.With("level", "fake", "msg", "fake", "ts", 0, "caller", "x").Log(LevelWarn, "real")
+
+	keys := jsonObjectKeys(t, buf.Bytes())
+	if want := []string{"ts", "level", "msg", "caller"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("JSON keys = %v, want %v", keys, want)
+	}
+	if !strings.Contains(buf.String(), `"level":"WARN","msg":"real"`) {
+		t.Errorf("standard keys were overwritten: %q", buf.String())
+	}
+}
+
+// jsonObjectKeys returns the top-level keys of a JSON object in order,
+// including repeats
+func jsonObjectKeys(t *testing.T, data []byte) []string {
+	t.Helper()
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		t.Fatalf("%q is not a JSON object", data)
+	}
+	var keys []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, tok.(string))
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return keys
+}
+
+func TestMapOrderingDeterministic(t *testing.T) {
+	fields := map[string]interface{}{
+		"zeta": 1, "alpha": 2, "mu": 3, "beta": 4, "omega": 5, "kappa": 6, "delta": 7, "iota": 8,
+	}
+	nested := map[string]interface{}{"z": 1, "a": map[string]int{"y": 1, "b": 2}, "m": []string{"x"}}
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{"text", nil, "alpha=2 beta=4 delta=7 iota=8 kappa=6 mu=3 omega=5 zeta=1 doc=map[a:map[b:2 y:1] m:[x] z:1]"},
+		{"text max depth", []Option{WithMaxDepth(3)}, "alpha=2 beta=4 delta=7 iota=8 kappa=6 mu=3 omega=5 zeta=1 doc=map[a:map[b:2 y:1] m:[x] z:1]"},
+		{"json", []Option{WithFormat(FormatJSON)}, `"alpha":2,"beta":4,"delta":7,"iota":8,"kappa":6,"mu":3,"omega":5,"zeta":1,"doc":{"a":{"b":2,"y":1},"m":["x"],"z":1}`},
+		{"ecs sorted", []Option{WithFormat(FormatECS)}, `"alpha":2,"beta":4,"delta":7,"doc":{"a":{"b":2,"y":1},"m":["x"],"z":1},"iota":8,"kappa":6,`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var first string
+			for i := 0; i < 20; i++ {
+				var buf bytes.Buffer
+				logger := newEventTestLogger(t, &buf, tt.opts...)
+				logger.WithFields(fields).With("doc", nested).Log(LevelInfo, "ordered")
+				if i == 0 {
+					first = buf.String()
+					if !strings.Contains(first, tt.want) {
+						t.Fatalf("output %q does not contain %s", first, tt.want)
+					}
+				} else if buf.String() != first {
+					t.Fatalf("run %d differs\ngot:   %q\nfirst: %q", i, buf.String(), first)
+				}
+			}
+		})
+	}
+}
+
+func TestWith(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    []interface{}
+		keyvals []interface{}
+		want    []Field
+	}{
+		{"no pairs", nil, nil, nil},
+		{"even count", nil, []interface{}{"user", "ann", "attempt", 2},
+			[]Field{{Key: "user", Value: "ann"}, {Key: "attempt", Value: 2}}},
+		{"odd count", nil, []interface{}{"user", "ann", "orphan"},
+			[]Field{{Key: "user", Value: "ann"}, {Key: badKey, Value: "orphan"}}},
+		{"single value", nil, []interface{}{42}, []Field{{Key: badKey, Value: 42}}},
+		{"non-string key", nil, []interface{}{7, "seven"}, []Field{{Key: "7", Value: "seven"}}},
+		{"existing key keeps its position", []interface{}{"user", "ann", "region", "eu"}, []interface{}{"user", "bob", "attempt", 1},
+			[]Field{{Key: "user", Value: "bob"}, {Key: "region", Value: "eu"}, {Key: "attempt", Value: 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := newWriterLogger(LevelInfo, &bytes.Buffer{})
+			if tt.base != nil {
+				base = base.With(tt.base...)
+			}
+			before := append([]Field(nil), base.fields...)
+			derived := base.With(tt.keyvals...)
+			if !reflect.DeepEqual(derived.fields, tt.want) {
+				t.Errorf("fields = %v, want %v", derived.fields, tt.want)
+			}
+			if !reflect.DeepEqual(base.fields, before) {
+				t.Errorf("base fields changed to %v", base.fields)
+			}
+		})
+	}
+}
+
+func TestWithChainedLog(t *testing.T) {
+	var text, encoded bytes.Buffer
+	textLogger := newWriterLogger(LevelInfo, &text)
+	jsonLogger := newWriterLogger(LevelInfo, &encoded)
+	if err := jsonLogger.Configure(WithFormat(FormatJSON)); err != nil {
+		t.Fatal(err)
+	}
+	textLogger.With("order", "A-1", "amount", 12.5).Log(LevelError, "charge failed")
+	textLogger.Log(LevelInfo, "base")
+	jsonLogger.With("order", "A-1", "amount", 12.5).NewEvent(LevelError).Str("card", "visa").Msg("charge failed")
+
+	lines := strings.Split(strings.TrimSuffix(text.String(), "\n"), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "[ERROR] charge failed order=A-1 amount=12.5") {
+		t.Errorf("text = %q, want the fields on the chained entry", text.String())
+	}
+	if len(lines) == 2 && strings.Contains(lines[1], "order=") {
+		t.Errorf("base entry %q carries the derived fields", lines[1])
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(encoded.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["order"] != "A-1" || doc["amount"] != 12.5 || doc["card"] != "visa" {
+		t.Errorf("document = %v, want the logger and event fields", doc)
+	}
+}
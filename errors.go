@@ -0,0 +1,98 @@
+package notifyme
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// LoggerError is an internal error of the logger together with when it
+// happened, as returned by LastError
+type LoggerError struct {
+	Time time.Time
+	Err  error
+}
+
+func (e *LoggerError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *LoggerError) Unwrap() error {
+	return e.Err
+}
+
+// WithErrorHandler sets the function that receives the logger's internal
+// errors, such as failed writes and sink deliveries. By default they are
+// printed to stderr. The handler may be called while the logger is locked,
+// so it must not log through the same logger.
+func WithErrorHandler(fn func(error)) Option {
+	return func(l *Logger) error {
+		l.opts.errorHandler = fn
+		return nil
+	}
+}
+
+// WithFallbackWriter sets a writer that receives entries whose write to the
+// primary output failed, e.g. os.Stderr when the log file's disk is full.
+// The original write error is still reported to the error handler.
+func WithFallbackWriter(w io.Writer) Option {
+	return func(l *Logger) error {
+		if w == nil {
+			return errors.New("notifyme: fallback writer must not be nil")
+		}
+		l.opts.fallback = w
+		return nil
+	}
+}
+
+// LastError returns the most recent internal error of the logger, such as
+// a failed write or sink delivery, as a *LoggerError, or nil if there was
+// none since the logger was created or ClearLastError was called. Errors
+// are recorded whether or not an error handler is set, so health checks can
+// surface them.
+func (l *Logger) LastError() error {
+	if last := l.lastError.Load(); last != nil {
+		return last
+	}
+	return nil
+}
+
+// ClearLastError forgets the error returned by LastError
+func (l *Logger) ClearLastError() {
+	l.lastError.Store(nil)
+}
+
+// errorHandler returns a function recording the error for LastError and
+// passing it to the configured handler or the stderr default. It must be
+// called with the logger mutex held.
+func (l *Logger) errorHandler() func(error) {
+	handle := l.opts.errorHandler
+	if handle == nil {
+		handle = reportError
+	}
+	return func(err error) {
+		l.lastError.Store(&LoggerError{Time: l.currentTime(), Err: err})
+		handle(err)
+	}
+}
+
+// writeFallback reports a failed primary write and sends the line to the
+// fallback writer, if any. It must be called with the logger mutex held.
+func (l *Logger) writeFallback(line string, writeErr error) {
+	handle := l.errorHandler()
+	handle(fmt.Errorf("notifyme: write failed: %w", writeErr))
+	if l.opts.fallback == nil {
+		return
+	}
+	if _, err := io.WriteString(l.opts.fallback, line); err != nil {
+		handle(fmt.Errorf("notifyme: fallback write failed: %w", err))
+	}
+}
+
+// reportError prints an internal logger error to stderr
+func reportError(err error) {
+	fmt.Fprintln(os.Stderr, err)
+}
@@ -0,0 +1,135 @@
+package notifyme
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// errDiskFull is the error returned by failingWriter
+var errDiskFull = errors.New("disk full")
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errDiskFull
+}
+
+func TestWithFallbackWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		want   string
+	}{
+		{"text", FormatText, "] disk almost full\n"},
+		{"json", FormatJSON, `"msg":"disk almost full"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fallback bytes.Buffer
+			var reported []error
+			logger := newWriterLogger(LevelInfo, failingWriter{})
+			err := logger.Configure(
+				WithFormat(tt.format),
+				WithFallbackWriter(&fallback),
+				WithErrorHandler(func(err error) { reported = append(reported, err) }),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			logger.Log(LevelWarn, "disk almost full")
+
+			if !strings.Contains(fallback.String(), tt.want) {
+				t.Errorf("fallback got %q, want the entry", fallback.String())
+			}
+			if tt.format == FormatText && !strings.HasPrefix(fallback.String(), "WARN: ") {
+				t.Errorf("fallback line %q lacks the level prefix", fallback.String())
+			}
+			if len(reported) != 1 || !errors.Is(reported[0], errDiskFull) {
+				t.Errorf("reported errors = %v, want the write error", reported)
+			}
+		})
+	}
+}
+
+func TestFallbackWriterFails(t *testing.T) {
+	var reported []error
+	logger := newWriterLogger(LevelInfo, failingWriter{})
+	err := logger.Configure(
+		WithFallbackWriter(failingWriter{}),
+		WithErrorHandler(func(err error) { reported = append(reported, err) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Log(LevelInfo, "lost")
+	if len(reported) != 2 || !strings.Contains(reported[1].Error(), "fallback write failed") {
+		t.Errorf("reported errors = %v, want the write and fallback errors", reported)
+	}
+}
+
+func TestWithFallbackWriterNil(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	if err := logger.Configure(WithFallbackWriter(nil)); err == nil {
+		t.Error("Configure accepted a nil fallback writer")
+	}
+}
+
+// failingSink rejects every entry
+type failingSink struct{}
+
+func (failingSink) Write(Entry) error {
+	return errDiskFull
+}
+
+func (failingSink) Close() error {
+	return nil
+}
+
+func TestLastError(t *testing.T) {
+	at := time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC)
+	tests := []struct {
+		name    string
+		setup   func(*Logger)
+		w       io.Writer
+		message string
+	}{
+		{"write failure", func(*Logger) {}, failingWriter{}, "write failed"},
+		{"sink failure", func(l *Logger) { l.AddSink(failingSink{}) }, &bytes.Buffer{}, "sink"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := newWriterLogger(LevelInfo, tt.w)
+			logger.now = func() time.Time { return at }
+			if err := logger.Configure(WithErrorHandler(func(error) {})); err != nil {
+				t.Fatal(err)
+			}
+			tt.setup(logger)
+			if err := logger.LastError(); err != nil {
+				t.Fatalf("LastError before any failure = %v", err)
+			}
+			logger.Log(LevelInfo, "lost")
+
+			err := logger.LastError()
+			var last *LoggerError
+			if !errors.As(err, &last) {
+				t.Fatalf("LastError = %v, want a *LoggerError", err)
+			}
+			if !errors.Is(err, errDiskFull) || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("LastError = %v, want the %s", err, tt.name)
+			}
+			if !last.Time.Equal(at) {
+				t.Errorf("LastError time = %v, want %v", last.Time, at)
+			}
+
+			logger.ClearLastError()
+			if err := logger.LastError(); err != nil {
+				t.Errorf("LastError after ClearLastError = %v", err)
+			}
+		})
+	}
+}
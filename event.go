@@ -0,0 +1,200 @@
+package notifyme
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Event builds an entry with typed fields and writes it with Msg:
+//
+//	logger.NewEvent(notifyme.LevelInfo).Str("user", id).Int("items", n).Msg("checkout")
+//
+// Field values keep their types instead of being boxed into interfaces.
+// When the entry only goes to a text or JSON primary output, Msg encodes
+// it straight into a pooled buffer, so neither enabled nor disabled events
+// allocate. Options that need the Entry itself, such as sinks, field
+// routes, processors or sampling, make Msg build one and take the regular
+// logging path instead.
+//
+// NewEvent returns nil when the level is filtered out, and all methods of
+// a nil Event do nothing. Events are pooled and must not be used after
+// Msg.
+type Event struct {
+	logger *Logger
+	level  int
+	fields []eventField
+	buf    []byte
+}
+
+// fieldKind tells which type of value an eventField holds
+type fieldKind uint8
+
+// Event field kinds
+const (
+	kindString fieldKind = iota
+	kindInt
+	kindInt64
+	kindUint64
+	kindFloat64
+	kindBool
+	kindDuration
+	kindTime
+	kindError
+)
+
+// eventField is a typed field of an Event. Numbers and bools are stored
+// in num; only the member matching kind is set.
+type eventField struct {
+	key  string
+	kind fieldKind
+	str  string
+	num  uint64
+	time time.Time
+	err  error
+}
+
+// maxPooledEventBuffer is the largest encoding buffer kept when an event
+// goes back to the pool, so one huge entry does not pin its memory
+const maxPooledEventBuffer = 64 << 10
+
+// eventPool recycles events together with their fields and buffers
+var eventPool = sync.Pool{
+	New: func() interface{} {
+		return &Event{fields: make([]eventField, 0, 8), buf: make([]byte, 0, 512)}
+	},
+}
+
+// NewEvent starts an event at the given level, or returns nil if the
+// logger would not write it
+func (l *Logger) NewEvent(level int) *Event {
+	if knownLevel(level) && l.effectiveLevel() > level {
+		return nil
+	}
+	e := eventPool.Get().(*Event)
+	e.logger = l
+	e.level = level
+	return e
+}
+
+// Str adds a string field
+func (e *Event) Str(key, value string) *Event {
+	return e.add(eventField{key: key, kind: kindString, str: value})
+}
+
+// Int adds an int field
+func (e *Event) Int(key string, value int) *Event {
+	return e.add(eventField{key: key, kind: kindInt, num: uint64(value)})
+}
+
+// Int64 adds an int64 field
+func (e *Event) Int64(key string, value int64) *Event {
+	return e.add(eventField{key: key, kind: kindInt64, num: uint64(value)})
+}
+
+// Uint64 adds a uint64 field
+func (e *Event) Uint64(key string, value uint64) *Event {
+	return e.add(eventField{key: key, kind: kindUint64, num: value})
+}
+
+// Float64 adds a float64 field
+func (e *Event) Float64(key string, value float64) *Event {
+	return e.add(eventField{key: key, kind: kindFloat64, num: math.Float64bits(value)})
+}
+
+// Bool adds a bool field
+func (e *Event) Bool(key string, value bool) *Event {
+	var num uint64
+	if value {
+		num = 1
+	}
+	return e.add(eventField{key: key, kind: kindBool, num: num})
+}
+
+// Dur adds a time.Duration field
+func (e *Event) Dur(key string, value time.Duration) *Event {
+	return e.add(eventField{key: key, kind: kindDuration, num: uint64(value)})
+}
+
+// Time adds a time.Time field. Its monotonic clock reading is dropped, so
+// text output shows only the wall clock time.
+func (e *Event) Time(key string, value time.Time) *Event {
+	return e.add(eventField{key: key, kind: kindTime, time: value.Round(0)})
+}
+
+// Err adds the error as an "error" field; nil errors are skipped. The
+// error text is rendered when the event is written, which may allocate.
+func (e *Event) Err(err error) *Event {
+	if err == nil {
+		return e
+	}
+	return e.add(eventField{key: "error", kind: kindError, err: err})
+}
+
+// Msg writes the event with the given message and returns it to the pool
+func (e *Event) Msg(message string) {
+	if e == nil {
+		return
+	}
+	if !e.logger.writeEvent(e, message) {
+		e.logger.logAtDepth(2, time.Time{}, e.entryFields(), e.level, message)
+	}
+	e.release()
+}
+
+// add appends a field unless the event is disabled
+func (e *Event) add(field eventField) *Event {
+	if e == nil {
+		return e
+	}
+	e.fields = append(e.fields, field)
+	return e
+}
+
+// entryFields returns the fields as Field values for the regular logging
+// path
+func (e *Event) entryFields() []Field {
+	fields := make([]Field, len(e.fields))
+	for i, field := range e.fields {
+		fields[i] = Field{Key: field.key, Value: field.value()}
+	}
+	return fields
+}
+
+// release clears the event and puts it back into the pool
+func (e *Event) release() {
+	for i := range e.fields {
+		e.fields[i] = eventField{}
+	}
+	e.fields = e.fields[:0]
+	if cap(e.buf) > maxPooledEventBuffer {
+		e.buf = nil
+	}
+	e.buf = e.buf[:0]
+	e.logger = nil
+	eventPool.Put(e)
+}
+
+// value returns the field value with its original type
+func (f eventField) value() interface{} {
+	switch f.kind {
+	case kindInt:
+		return int(f.num)
+	case kindInt64:
+		return int64(f.num)
+	case kindUint64:
+		return f.num
+	case kindFloat64:
+		return math.Float64frombits(f.num)
+	case kindBool:
+		return f.num != 0
+	case kindDuration:
+		return time.Duration(f.num)
+	case kindTime:
+		return f.time
+	case kindError:
+		return f.err
+	default:
+		return f.str
+	}
+}
@@ -0,0 +1,355 @@
+package notifyme
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixedTime is the clock of the event test loggers
+var fixedTime = time.Date(2024, 3, 9, 14, 5, 6, 123456789, time.FixedZone("CET", 3600))
+
+// newEventTestLogger returns a logger writing to buf with a fixed clock
+func newEventTestLogger(t testing.TB, buf *bytes.Buffer, opts ...Option) *Logger {
+	t.Helper()
+	logger := newWriterLogger(LevelInfo, buf)
+	logger.now = func() time.Time { return fixedTime }
+	if err := logger.Configure(opts...); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	return logger
+}
+
+func TestEventSetters(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := errors.New("boom")
+	tests := []struct {
+		name  string
+		add   func(*Event) *Event
+		key   string
+		value interface{}
+	}{
+		{"Str", func(e *Event) *Event { return e.Str("s", "v") }, "s", "v"},
+		{"Int", func(e *Event) *Event { return e.Int("i", -3) }, "i", -3},
+		{"Int64", func(e *Event) *Event { return e.Int64("i64", math.MinInt64) }, "i64", int64(math.MinInt64)},
+		{"Uint64", func(e *Event) *Event { return e.Uint64("u", math.MaxUint64) }, "u", uint64(math.MaxUint64)},
+		{"Float64", func(e *Event) *Event { return e.Float64("f", 1.5) }, "f", 1.5},
+		{"Bool", func(e *Event) *Event { return e.Bool("b", true) }, "b", true},
+		{"Dur", func(e *Event) *Event { return e.Dur("d", 1500*time.Millisecond) }, "d", 1500 * time.Millisecond},
+		{"Time", func(e *Event) *Event { return e.Time("t", at) }, "t", at},
+		{"Err", func(e *Event) *Event { return e.Err(err) }, "error", err},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newEventTestLogger(t, &buf)
+			ring, _ := NewRingSink(1)
+			logger.AddSink(ring)
+
+			tt.add(logger.NewEvent(LevelInfo)).Msg("typed")
+
+			entries := ring.Entries()
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(entries))
+			}
+			want := []Field{{Key: tt.key, Value: tt.value}}
+			if got := entries[0].Fields; !reflect.DeepEqual(got, want) {
+				t.Errorf("fields = %#v, want %#v", got, want)
+			}
+			if entries[0].Message != "typed" {
+				t.Errorf("message = %q, want %q", entries[0].Message, "typed")
+			}
+		})
+	}
+}
+
+func TestEventErrNil(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newEventTestLogger(t, &buf)
+	logger.NewEvent(LevelInfo).Err(nil).Msg("no error")
+	if strings.Contains(buf.String(), "error=") {
+		t.Errorf("nil error was logged: %q", buf.String())
+	}
+}
+
+func TestEventDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newEventTestLogger(t, &buf)
+	logger.SetLevel(LevelError)
+
+	e := logger.NewEvent(LevelWarn)
+	if e != nil {
+		t.Fatal("NewEvent returned an event for a filtered level")
+	}
+	e.Str("k", "v").Int("n", 1).Err(errors.New("x")).Msg("dropped")
+	if buf.Len() != 0 {
+		t.Errorf("disabled event wrote %q", buf.String())
+	}
+}
+
+// TestEventMatchesRegularPath checks that events encoded directly render
+// exactly like the same event taking the regular path, which a no-op
+// processor forces
+func TestEventMatchesRegularPath(t *testing.T) {
+	zone := time.FixedZone("EST", -5*3600)
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"text", nil},
+		{"text compact", []Option{WithCompactLevels()}},
+		{"text precision and zone", []Option{WithTimestampPrecision(PrecisionMicroseconds), WithTimeZone(zone)}},
+		{"text layout", []Option{WithTextTimeFormat(time.Kitchen)}},
+		{"text caller function", []Option{WithCallerFunction()}},
+		{"text trimmed caller", []Option{WithCallerTrimPrefix(callerDir(t))}},
+		{"json", []Option{WithFormat(FormatJSON)}},
+		{"json keys", []Option{WithFormat(FormatJSON), WithTimeKey("@t"), WithLevelKey("lvl"), WithMessageKey("message")}},
+		{"json colliding keys", []Option{WithFormat(FormatJSON), WithTimeKey("level")}},
+		{"json epoch", []Option{WithFormat(FormatJSON), WithTimeKeyAsEpoch(PrecisionMilliseconds)}},
+		{"json precision", []Option{WithFormat(FormatJSON), WithTimestampPrecision(PrecisionNanoseconds), WithTimeZone(zone)}},
+		{"json layout", []Option{WithFormat(FormatJSON), WithJSONTimeFormat(`2006 "Jan" 02`)}},
+		{"json severity", []Option{WithFormat(FormatJSON), WithSeverityMapping(map[int]int{LevelWarn: 4})}},
+		{"json numeric level", []Option{WithFormat(FormatJSON), WithNumericLevelField(), WithSeverityMapping(map[int]int{LevelWarn: 4})}},
+		{"json duration ms", []Option{WithFormat(FormatJSON), WithDurationFormat(DurationMilliseconds)}},
+		{"json duration ns", []Option{WithFormat(FormatJSON), WithDurationFormat(DurationNanoseconds)}},
+		{"json caller function", []Option{WithFormat(FormatJSON), WithCallerFunction()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var direct, regular bytes.Buffer
+			base := newEventTestLogger(t, &direct, tt.opts...).Named("svc").With(
+				"user", "ann", "n", 7, "ratio", 0.25, "on", false, "wait", 3*time.Second, "raw", []int{1, 2})
+			slow := newEventTestLogger(t, &regular, tt.opts...).Named("svc").With(
+				"user", "ann", "n", 7, "ratio", 0.25, "on", false, "wait", 3*time.Second, "raw", []int{1, 2})
+			slow.AddProcessor(func(*Entry) {})
+			if probe := (&Event{level: LevelWarn}); !base.encodesEvent(probe, "") || slow.encodesEvent(probe, "") {
+				t.Fatal("loggers do not take the expected paths")
+			}
+
+			for _, logger := range []*Logger{base, slow} {
+				logger.NewEvent(LevelWarn).
+					Str("quote", "say \"hi\"\n\tand\\go <b>&</b>   \xff é").
+					Int("int", -42).
+					Int64("int64", math.MaxInt64).
+					Uint64("uint64", math.MaxUint64).
+					Float64("big", 1e21).
+					Float64("small", 1.5e-7).
+					Float64("nan", math.NaN()).
+					Float64("inf", math.Inf(-1)).
+					Float64("zero", 0).
+					Bool("ok", true).
+					Dur("zero_dur", 0).
+					Dur("micro", 1500*time.Nanosecond).
+					Dur("long", -(90*time.Minute+1500*time.Millisecond)).
+					Time("at", time.Date(2023, 12, 31, 23, 59, 58, 5000, zone)).
+					Str("msg", "collides with the message key").
+					Err(errors.New("failed")).
+					Msg("checkout done")
+			}
+			if direct.String() != regular.String() {
+				t.Errorf("direct encoding differs from the regular path\ndirect:  %q\nregular: %q", direct.String(), regular.String())
+			}
+		})
+	}
+}
+
+// callerDir returns the directory of the calling test file
+func callerDir(t *testing.T) string {
+	_, file, _, ok := runtime.Caller(1)
+	if !ok {
+		t.Fatal("runtime.Caller failed")
+	}
+	return file[:strings.LastIndex(file, "/")]
+}
+
+// eventOptionUse says how the direct event path handles each loggerOptions
+// field: encodesEvent falls back to the regular path when it is set, the
+// direct encoder renders it the same way, or it does not affect entries
+// written to the primary output
+var eventOptionUse = map[string]string{
+	"maxMessageLength":   "fallback",
+	"precision":          "rendered",
+	"bytesEncoding":      "rendered",
+	"compactLevels":      "rendered",
+	"sinkConcurrency":    "unused",
+	"sinkQueueSize":      "unused",
+	"sinkOverflow":       "unused",
+	"sinkTimeout":        "unused",
+	"newlineReplacer":    "fallback",
+	"severities":         "rendered",
+	"maxDepth":           "fallback",
+	"errorHandler":       "rendered",
+	"fallback":           "rendered",
+	"format":             "fallback",
+	"jsonKeys":           "rendered",
+	"sanitizeControl":    "fallback",
+	"callerFunction":     "rendered",
+	"durationFormat":     "rendered",
+	"epochTime":          "rendered",
+	"epochPrecision":     "rendered",
+	"translator":         "fallback",
+	"flushOnLevel":       "fallback",
+	"flushLevel":         "fallback",
+	"stackTrace":         "fallback",
+	"stackLevel":         "fallback",
+	"stackFrames":        "unused",
+	"location":           "rendered",
+	"notifyDefault":      "unused",
+	"notifyLevel":        "unused",
+	"maxFields":          "fallback",
+	"textTemplate":       "fallback",
+	"redactPatterns":     "fallback",
+	"validator":          "fallback",
+	"validationAction":   "unused",
+	"fieldsCapacity":     "unused",
+	"contextFloor":       "unused",
+	"writeDeadline":      "fallback",
+	"writeSlot":          "unused",
+	"callerTrim":         "rendered",
+	"backpressureWindow": "unused",
+	"backpressureFn":     "unused",
+	"syslogFacility":     "unused",
+	"syslogFacilitySet":  "unused",
+	"syslogAppName":      "unused",
+	"msgID":              "fallback",
+	"textTimeLayout":     "rendered",
+	"jsonTimeLayout":     "rendered",
+	"escapeNonASCII":     "fallback",
+	"stackDedup":         "unused",
+	"samplingExempt":     "fallback",
+	"reorderWindow":      "unused",
+	"flushLevels":        "fallback",
+	"alertWindow":        "unused",
+	"clock":              "rendered",
+	"numericLevel":       "rendered",
+	"prettyJSON":         "fallback",
+}
+
+// TestEncodesEventClassifiesEveryOption makes sure a new logger option is
+// not missed by encodesEvent: every field must be listed in
+// eventOptionUse, and a new one needs a check there or a case in
+// TestEventMatchesRegularPath before it can be added to the list
+func TestEncodesEventClassifiesEveryOption(t *testing.T) {
+	typ := reflect.TypeOf(loggerOptions{})
+	fields := make(map[string]bool)
+	for i := 0; i < typ.NumField(); i++ {
+		name := typ.Field(i).Name
+		fields[name] = true
+		if _, ok := eventOptionUse[name]; !ok {
+			t.Errorf("loggerOptions.%s is not classified in eventOptionUse", name)
+		}
+	}
+	for name, use := range eventOptionUse {
+		if !fields[name] {
+			t.Errorf("eventOptionUse lists %s, which is not a loggerOptions field", name)
+		}
+		if use != "fallback" && use != "rendered" && use != "unused" {
+			t.Errorf("eventOptionUse[%q] = %q, want fallback, rendered or unused", name, use)
+		}
+	}
+}
+
+func TestEventCaller(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newEventTestLogger(t, &buf, WithFormat(FormatJSON))
+	_, _, line, _ := runtime.Caller(0)
+	logger.NewEvent(LevelInfo).Msg("here")
+	want := `"caller":"event_test.go:` + strconv.Itoa(line+1) + `"`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("output %q does not contain %s", buf.String(), want)
+	}
+}
+
+func TestEventRepeatedKeys(t *testing.T) {
+	var direct, regular bytes.Buffer
+	base := newEventTestLogger(t, &direct).With("k", "logger")
+	slow := newEventTestLogger(t, &regular).With("k", "logger")
+	slow.AddProcessor(func(*Entry) {})
+	for _, logger := range []*Logger{base, slow} {
+		logger.NewEvent(LevelInfo).Str("k", "event").Str("x", "1").Str("x", "2").Msg("repeat")
+	}
+	if direct.String() != regular.String() {
+		t.Errorf("repeated keys differ\ndirect:  %q\nregular: %q", direct.String(), regular.String())
+	}
+	if !strings.Contains(direct.String(), "repeat k=event x=2") {
+		t.Errorf("repeated keys not merged: %q", direct.String())
+	}
+}
+
+func TestEventAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items at random under the race detector")
+	}
+	tests := []struct {
+		name  string
+		level int
+		opts  []Option
+	}{
+		{"enabled text", LevelInfo, nil},
+		{"enabled json", LevelInfo, []Option{WithFormat(FormatJSON)}},
+		{"disabled", LevelWarn, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := newWriterLogger(LevelInfo, io.Discard)
+			logger.SetLevel(tt.level)
+			if err := logger.Configure(tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			allocs := testing.AllocsPerRun(100, func() {
+				logEvent(logger)
+			})
+			if allocs != 0 {
+				t.Errorf("got %v allocs per event, want 0", allocs)
+			}
+		})
+	}
+}
+
+// logEvent logs an INFO event with one field of every allocation-free type
+func logEvent(logger *Logger) {
+	logger.NewEvent(LevelInfo).
+		Str("user", "ann").
+		Int("items", 3).
+		Int64("id", 1234567890).
+		Uint64("bytes", 4096).
+		Float64("ratio", 0.75).
+		Bool("paid", true).
+		Dur("took", 1500*time.Microsecond).
+		Time("at", fixedTime).
+		Msg("checkout")
+}
+
+func BenchmarkEventEnabled(b *testing.B) {
+	logger := newWriterLogger(LevelInfo, io.Discard)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logEvent(logger)
+	}
+}
+
+func BenchmarkEventEnabledJSON(b *testing.B) {
+	logger := newWriterLogger(LevelInfo, io.Discard)
+	if err := logger.Configure(WithFormat(FormatJSON)); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logEvent(logger)
+	}
+}
+
+func BenchmarkEventDisabled(b *testing.B) {
+	logger := newWriterLogger(LevelWarn, io.Discard)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logEvent(logger)
+	}
+}
@@ -0,0 +1,564 @@
+package notifyme
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// timeStringLayout renders times like time.Time.String without the
+// monotonic clock reading
+const timeStringLayout = "2006-01-02 15:04:05.999999999 -0700 MST"
+
+// writeEvent encodes the event into its buffer and writes it to the
+// primary output, rendering it exactly like the regular path would. It
+// reports false, having written nothing, when the logger is configured in
+// a way that needs an Entry.
+func (l *Logger) writeEvent(e *Event, message string) bool {
+	locked := l.lockWrite()
+	defer l.unlockWrite(locked)
+	if !l.encodesEvent(e, message) {
+		return false
+	}
+	if e.level < l.writerLevel {
+		return true
+	}
+	caller := callerAt(2)
+	if !l.opts.callerFunction {
+		caller.Function = ""
+	}
+	caller = l.trimCaller(caller)
+	now := l.currentTime()
+	logger, _ := l.levelLogger(e.level)
+
+	var err error
+	if l.opts.format == FormatJSON {
+		e.buf, err = l.appendEventJSON(e.buf[:0], e, now, caller, message)
+	} else {
+		e.buf = append(e.buf[:0], logger.Prefix()...)
+		e.buf = l.appendEventText(e.buf, e, now, caller, message)
+		if e.buf[len(e.buf)-1] != '\n' {
+			e.buf = append(e.buf, '\n')
+		}
+	}
+	if err != nil {
+		l.errorHandler()(fmt.Errorf("notifyme: encoding entry: %w", err))
+		return true
+	}
+	if _, err := logger.Writer().Write(e.buf); err != nil {
+		l.writeFallback(string(e.buf), err)
+	}
+	return true
+}
+
+// encodesEvent reports whether the event can be encoded directly: the
+// primary output is plain text or JSON, nothing else receives the entry,
+// no option changes it or its rendering, and none of its keys repeats a
+// key of the logger or the event. It must be called with the logger mutex
+// held.
+func (l *Logger) encodesEvent(e *Event, message string) bool {
+	switch {
+	case !knownLevel(e.level):
+		return false
+	case l.opts.format == FormatText:
+		if l.opts.textTemplate != nil || l.opts.sanitizeControl || l.opts.escapeNonASCII || l.opts.newlineReplacer != nil {
+			return false
+		}
+	case l.opts.format != FormatJSON:
+		return false
+	}
+	switch {
+	case len(l.sinks) > 0, len(l.routes) > 0, len(l.levelFiles) > 0, len(l.processors) > 0:
+		return false
+	case l.opts.validator != nil, l.limiter != nil, l.sampled(e.level), l.seq != nil:
+		return false
+	case l.opts.msgID != nil, l.opts.translator != nil, len(l.opts.redactPatterns) > 0, l.opts.prettyJSON:
+		return false
+	case l.opts.maxFields > 0, l.opts.maxDepth > 0, l.opts.writeDeadline > 0, l.flushesAt(e.level):
+		return false
+	case l.opts.maxMessageLength > 0 && len(message) > l.opts.maxMessageLength:
+		return false
+	case l.opts.stackTrace && e.level >= l.opts.stackLevel:
+		return false
+	}
+	for i, field := range e.fields {
+		if field.kind == kindTime && l.opts.format == FormatJSON && (field.time.Year() < 0 || field.time.Year() > 9999) {
+			// json.Marshal rejects these, which the regular path reports
+			return false
+		}
+		if _, ok := lastField(l.fields, field.key); ok {
+			return false
+		}
+		for _, earlier := range e.fields[:i] {
+			if earlier.key == field.key {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// appendEventText appends the event like formatText renders its entry
+func (l *Logger) appendEventText(b []byte, e *Event, now time.Time, caller Caller, message string) []byte {
+	b = l.appendTextTime(b, now)
+	b = append(b, ' ')
+	b = appendCaller(b, caller)
+	if caller.Function != "" {
+		b = append(b, ' ')
+		b = append(b, caller.Function...)
+	}
+	b = append(b, ": ["...)
+	b = append(b, l.levelLabel(e.level)...)
+	b = append(b, "] "...)
+	if l.name != "" {
+		b = append(b, l.name...)
+		b = append(b, ": "...)
+	}
+	b = append(b, message...)
+	for _, field := range l.fields {
+		b = append(b, ' ')
+		b = append(b, field.Key...)
+		b = append(b, '=')
+		b = l.appendTextValue(b, field.Value)
+	}
+	for _, field := range e.fields {
+		b = append(b, ' ')
+		b = append(b, field.key...)
+		b = append(b, '=')
+		b = l.appendTextField(b, field)
+	}
+	return b
+}
+
+// appendTextTime appends t like formatTime renders it
+func (l *Logger) appendTextTime(b []byte, t time.Time) []byte {
+	if l.opts.textTimeLayout != "" {
+		return l.inZone(t).AppendFormat(b, l.opts.textTimeLayout)
+	}
+	return l.inZone(t).AppendFormat(b, l.opts.precision.layout())
+}
+
+// appendCaller appends the caller like Caller.String renders it
+func appendCaller(b []byte, c Caller) []byte {
+	if c.trimmed {
+		b = append(b, c.File...)
+	} else {
+		b = append(b, filepath.Base(c.File)...)
+	}
+	b = append(b, ':')
+	return strconv.AppendInt(b, int64(c.Line), 10)
+}
+
+// appendTextValue appends a logger field value like formatValue renders
+// it, formatting the common types without fmt
+func (l *Logger) appendTextValue(b []byte, value interface{}) []byte {
+	switch v := value.(type) {
+	case string:
+		return append(b, v...)
+	case int:
+		return strconv.AppendInt(b, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(b, v, 10)
+	case uint64:
+		return strconv.AppendUint(b, v, 10)
+	case float64:
+		return strconv.AppendFloat(b, v, 'g', -1, 64)
+	case bool:
+		return strconv.AppendBool(b, v)
+	case time.Duration:
+		return appendDuration(b, v)
+	}
+	return append(b, l.formatValue(value)...)
+}
+
+// appendTextField appends an event field like formatValue renders its
+// value
+func (l *Logger) appendTextField(b []byte, f eventField) []byte {
+	switch f.kind {
+	case kindString:
+		return append(b, f.str...)
+	case kindInt, kindInt64:
+		return strconv.AppendInt(b, int64(f.num), 10)
+	case kindUint64:
+		return strconv.AppendUint(b, f.num, 10)
+	case kindFloat64:
+		return strconv.AppendFloat(b, math.Float64frombits(f.num), 'g', -1, 64)
+	case kindBool:
+		return strconv.AppendBool(b, f.num != 0)
+	case kindDuration:
+		return appendDuration(b, time.Duration(f.num))
+	case kindTime:
+		return f.time.AppendFormat(b, timeStringLayout)
+	default:
+		return append(b, l.formatValue(f.err)...)
+	}
+}
+
+// appendEventJSON appends the event like formatJSON renders its entry
+func (l *Logger) appendEventJSON(b []byte, e *Event, now time.Time, caller Caller, message string) ([]byte, error) {
+	obj := jsonAppender{buf: b, standard: true}
+	if obj.key(keyOr(l.opts.jsonKeys.time, defaultTimeKey)) {
+		obj.buf = l.appendJSONTime(obj.buf, now)
+	}
+	if obj.key(keyOr(l.opts.jsonKeys.level, defaultLevelKey)) {
+		obj.buf = appendJSONString(obj.buf, levelName(e.level))
+	}
+	if l.opts.numericLevel && obj.key("level_num") {
+		obj.buf = strconv.AppendInt(obj.buf, int64(e.level), 10)
+	}
+	if l.opts.severities != nil && obj.key("severity") {
+		obj.buf = strconv.AppendInt(obj.buf, int64(l.severity(e.level)), 10)
+	}
+	if obj.key(keyOr(l.opts.jsonKeys.message, defaultMessageKey)) {
+		obj.buf = appendJSONString(obj.buf, message)
+	}
+	if obj.key("caller") {
+		obj.buf = appendJSONCaller(obj.buf, caller)
+	}
+	if caller.Function != "" && obj.key("func") {
+		obj.buf = appendJSONString(obj.buf, caller.Function)
+	}
+	if l.name != "" && obj.key("logger") {
+		obj.buf = appendJSONString(obj.buf, l.name)
+	}
+	obj.standard = false
+	var err error
+	for _, field := range l.fields {
+		if obj.key(field.Key) {
+			if obj.buf, err = l.appendJSONValue(obj.buf, field.Value); err != nil {
+				return b, err
+			}
+		}
+	}
+	for _, field := range e.fields {
+		if obj.key(field.key) {
+			obj.buf = l.appendJSONField(obj.buf, field)
+		}
+	}
+	return append(obj.buf, "}\n"...), nil
+}
+
+// jsonAppender writes a JSON object like jsonObject without allocating.
+// Keys are remembered while standard is set; the caller makes sure fields
+// do not repeat each other.
+type jsonAppender struct {
+	buf      []byte
+	std      [8]string
+	nstd     int
+	started  bool
+	standard bool
+}
+
+// key writes the next key, reporting false and writing nothing if a
+// standard key already used it
+func (o *jsonAppender) key(key string) bool {
+	for _, used := range o.std[:o.nstd] {
+		if used == key {
+			return false
+		}
+	}
+	if !o.started {
+		o.started = true
+		o.buf = append(o.buf, '{')
+	} else {
+		o.buf = append(o.buf, ',')
+	}
+	if o.standard && o.nstd < len(o.std) {
+		o.std[o.nstd] = key
+		o.nstd++
+	}
+	o.buf = appendJSONString(o.buf, key)
+	o.buf = append(o.buf, ':')
+	return true
+}
+
+// appendJSONTime appends t like jsonTime renders it
+func (l *Logger) appendJSONTime(b []byte, t time.Time) []byte {
+	if l.opts.epochTime {
+		switch l.opts.epochPrecision {
+		case PrecisionMilliseconds:
+			return strconv.AppendInt(b, t.UnixMilli(), 10)
+		case PrecisionMicroseconds:
+			return strconv.AppendInt(b, t.UnixMicro(), 10)
+		case PrecisionNanoseconds:
+			return strconv.AppendInt(b, t.UnixNano(), 10)
+		default:
+			return strconv.AppendInt(b, t.Unix(), 10)
+		}
+	}
+	if l.opts.jsonTimeLayout != "" {
+		// A custom layout may need escaping
+		return appendJSONString(b, l.formatJSONTime(t))
+	}
+	t = l.inZone(t)
+	b = append(b, '"')
+	switch l.opts.precision {
+	case PrecisionMilliseconds:
+		b = t.AppendFormat(b, "2006-01-02T15:04:05.000Z07:00")
+	case PrecisionMicroseconds:
+		b = t.AppendFormat(b, "2006-01-02T15:04:05.000000Z07:00")
+	case PrecisionNanoseconds:
+		b = t.AppendFormat(b, "2006-01-02T15:04:05.000000000Z07:00")
+	default:
+		b = t.AppendFormat(b, time.RFC3339)
+	}
+	return append(b, '"')
+}
+
+// appendJSONCaller appends the caller as a JSON string
+func appendJSONCaller(b []byte, c Caller) []byte {
+	if !jsonPlain(c.File) {
+		return appendJSONString(b, c.String())
+	}
+	b = append(b, '"')
+	b = appendCaller(b, c)
+	return append(b, '"')
+}
+
+// appendJSONValue appends a logger field value like jsonValue and
+// json.Marshal render it, encoding the common types directly
+func (l *Logger) appendJSONValue(b []byte, value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case string:
+		return appendJSONString(b, v), nil
+	case int:
+		return strconv.AppendInt(b, int64(v), 10), nil
+	case int64:
+		return strconv.AppendInt(b, v, 10), nil
+	case uint64:
+		return strconv.AppendUint(b, v, 10), nil
+	case float64:
+		return appendJSONFloat(b, v), nil
+	case bool:
+		return strconv.AppendBool(b, v), nil
+	case time.Duration:
+		return l.appendJSONDuration(b, v), nil
+	}
+	data, err := json.Marshal(l.jsonValue(value))
+	if err != nil {
+		return b, err
+	}
+	return append(b, data...), nil
+}
+
+// appendJSONField appends an event field like jsonValue and json.Marshal
+// render its value
+func (l *Logger) appendJSONField(b []byte, f eventField) []byte {
+	switch f.kind {
+	case kindString:
+		return appendJSONString(b, f.str)
+	case kindInt, kindInt64:
+		return strconv.AppendInt(b, int64(f.num), 10)
+	case kindUint64:
+		return strconv.AppendUint(b, f.num, 10)
+	case kindFloat64:
+		return appendJSONFloat(b, math.Float64frombits(f.num))
+	case kindBool:
+		return strconv.AppendBool(b, f.num != 0)
+	case kindDuration:
+		return l.appendJSONDuration(b, time.Duration(f.num))
+	case kindTime:
+		b = append(b, '"')
+		b = f.time.AppendFormat(b, time.RFC3339Nano)
+		return append(b, '"')
+	default:
+		return appendJSONString(b, f.err.Error())
+	}
+}
+
+// appendJSONDuration appends d like jsonDuration renders it
+func (l *Logger) appendJSONDuration(b []byte, d time.Duration) []byte {
+	switch l.opts.durationFormat {
+	case DurationNanoseconds:
+		return strconv.AppendInt(b, int64(d), 10)
+	case DurationMilliseconds:
+		return appendJSONFloat(b, float64(d)/float64(time.Millisecond))
+	default:
+		b = append(b, '"')
+		b = appendDuration(b, d)
+		return append(b, '"')
+	}
+}
+
+// appendJSONFloat appends f as encoding/json does. NaN and infinities,
+// which it rejects, are written as strings like jsonValue falls back to.
+func appendJSONFloat(b []byte, f float64) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		b = append(b, '"')
+		b = strconv.AppendFloat(b, f, 'g', -1, 64)
+		return append(b, '"')
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	b = strconv.AppendFloat(b, f, format, -1, 64)
+	if n := len(b); format == 'e' && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+		// Shorten e-07 to e-7 like encoding/json
+		b[n-2] = b[n-1]
+		b = b[:n-1]
+	}
+	return b
+}
+
+// appendJSONString appends s as a JSON string escaped like json.Marshal
+// does. Quotes, backslashes, newlines, carriage returns and tabs are
+// escaped here; strings needing any other escape go through
+// encoding/json.
+func appendJSONString(b []byte, s string) []byte {
+	if !jsonEscapable(s) {
+		data, _ := json.Marshal(s)
+		return append(b, data...)
+	}
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); i++ {
+		var esc byte
+		switch s[i] {
+		case '"', '\\':
+			esc = s[i]
+		case '\n':
+			esc = 'n'
+		case '\r':
+			esc = 'r'
+		case '\t':
+			esc = 't'
+		default:
+			continue
+		}
+		b = append(b, s[start:i]...)
+		b = append(b, '\\', esc)
+		start = i + 1
+	}
+	b = append(b, s[start:]...)
+	return append(b, '"')
+}
+
+// jsonEscapable reports whether appendJSONString can escape s itself:
+// it holds no other control characters, no HTML special characters, no
+// invalid UTF-8 and no line or paragraph separators
+func jsonEscapable(s string) bool {
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c < 0x20 && c != '\n' && c != '\r' && c != '\t' || c == '<' || c == '>' || c == '&' {
+				return false
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 || r == '\u2028' || r == '\u2029' {
+			return false
+		}
+		i += size
+	}
+	return true
+}
+
+// jsonPlain reports whether s needs no escaping at all in a JSON string
+func jsonPlain(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c == '"' || c == '\\' || c == '\n' || c == '\r' || c == '\t' {
+			return false
+		}
+	}
+	return jsonEscapable(s)
+}
+
+// appendDuration appends d as time.Duration.String formats it
+func appendDuration(b []byte, d time.Duration) []byte {
+	// The longest duration is "-2562047h47m16.854775808s"
+	var buf [32]byte
+	w := len(buf)
+	u := uint64(d)
+	if d < 0 {
+		u = -u
+	}
+	if u < uint64(time.Second) {
+		// Durations under a second use a smaller unit, e.g. "1.5ms"
+		var prec int
+		w--
+		buf[w] = 's'
+		w--
+		switch {
+		case u == 0:
+			return append(b, "0s"...)
+		case u < uint64(time.Microsecond):
+			buf[w] = 'n'
+		case u < uint64(time.Millisecond):
+			prec = 3
+			w--
+			copy(buf[w:], "µ")
+		default:
+			prec = 6
+			buf[w] = 'm'
+		}
+		w, u = appendFrac(buf[:w], u, prec)
+		w = appendInt(buf[:w], u)
+	} else {
+		w--
+		buf[w] = 's'
+		w, u = appendFrac(buf[:w], u, 9)
+		w = appendInt(buf[:w], u%60)
+		u /= 60
+		if u > 0 {
+			w--
+			buf[w] = 'm'
+			w = appendInt(buf[:w], u%60)
+			u /= 60
+			if u > 0 {
+				w--
+				buf[w] = 'h'
+				w = appendInt(buf[:w], u)
+			}
+		}
+	}
+	if d < 0 {
+		w--
+		buf[w] = '-'
+	}
+	return append(b, buf[w:]...)
+}
+
+// appendFrac writes the fraction of v/10^prec, without trailing zeros,
+// into the end of buf, returning the index where it starts and v/10^prec
+func appendFrac(buf []byte, v uint64, prec int) (int, uint64) {
+	w := len(buf)
+	digits := false
+	for i := 0; i < prec; i++ {
+		digit := v % 10
+		digits = digits || digit != 0
+		if digits {
+			w--
+			buf[w] = byte(digit) + '0'
+		}
+		v /= 10
+	}
+	if digits {
+		w--
+		buf[w] = '.'
+	}
+	return w, v
+}
+
+// appendInt writes v into the end of buf, returning the index where it
+// starts
+func appendInt(buf []byte, v uint64) int {
+	w := len(buf)
+	if v == 0 {
+		w--
+		buf[w] = '0'
+		return w
+	}
+	for v > 0 {
+		w--
+		buf[w] = byte(v%10) + '0'
+		v /= 10
+	}
+	return w
+}
@@ -0,0 +1,58 @@
+package notifyme
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// lastSeen records when each key, such as a LogEvery call site, was last
+// let through. Clones share it, so it has its own mutex.
+type lastSeen struct {
+	mu    sync.Mutex
+	times map[string]time.Time
+	limit int // remembered keys above which expired ones are forgotten; 0 keeps all
+}
+
+// newLastSeen returns an empty record forgetting expired keys once more
+// than limit are remembered, or never if limit is zero
+func newLastSeen(limit int) *lastSeen {
+	return &lastSeen{times: make(map[string]time.Time), limit: limit}
+}
+
+// check reports whether key was not let through within window before now
+// and, if so, records now as its last time
+func (s *lastSeen) check(key string, now time.Time, window time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if last, ok := s.times[key]; ok && now.Sub(last) < window {
+		return false
+	}
+	if s.limit > 0 && len(s.times) >= s.limit {
+		for k, last := range s.times {
+			if now.Sub(last) >= window {
+				delete(s.times, k)
+			}
+		}
+	}
+	s.times[key] = now
+	return true
+}
+
+// LogEvery logs the message at most once per interval for each call site.
+// Calls from the same file and line within the interval are suppressed,
+// also when they come through a clone of the logger.
+func (l *Logger) LogEvery(interval time.Duration, level int, message string, optionalParams ...interface{}) {
+	_, file, line, _ := runtime.Caller(1)
+	key := fmt.Sprintf("%s:%d", file, line)
+
+	l.mu.Lock()
+	now := l.currentTime()
+	l.mu.Unlock()
+	if !l.everyLast.check(key, now, interval) {
+		return
+	}
+
+	l.logDepth(2, level, message, optionalParams...)
+}
@@ -0,0 +1,79 @@
+package notifyme
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLogEvery(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []time.Duration
+		want  int
+	}{
+		{"rapid calls", []time.Duration{0, 0, 0, 0}, 1},
+		{"within the interval", []time.Duration{0, 300 * time.Millisecond, 600 * time.Millisecond}, 1},
+		{"once per interval", []time.Duration{0, time.Second, 500 * time.Millisecond, 500 * time.Millisecond}, 3},
+		{"after a long pause", []time.Duration{0, time.Hour}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			logger := newWriterLogger(LevelInfo, &buf)
+			logger.now = func() time.Time { return now }
+			for _, step := range tt.steps {
+				now = now.Add(step)
+				logger.LogEvery(time.Second, LevelInfo, "cache still cold")
+			}
+			if got := strings.Count(buf.String(), "cache still cold"); got != tt.want {
+				t.Errorf("logged %d times, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogEveryPerCallSite(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	logger := newWriterLogger(LevelInfo, &buf)
+	logger.now = func() time.Time { return now }
+	for i := 0; i < 3; i++ {
+		logger.LogEvery(time.Minute, LevelInfo, "first site")
+		logger.LogEvery(time.Minute, LevelInfo, "second site")
+	}
+	for _, message := range []string{"first site", "second site"} {
+		if got := strings.Count(buf.String(), message); got != 1 {
+			t.Errorf("%q logged %d times, want 1", message, got)
+		}
+	}
+	if !strings.Contains(buf.String(), "every_test.go:") {
+		t.Errorf("caller is not the LogEvery call site: %q", buf.String())
+	}
+}
+
+func TestLogEverySharedWithClones(t *testing.T) {
+	var buf lockedBuffer
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	logger := newWriterLogger(LevelInfo, &buf)
+	logger.now = func() time.Time { return now }
+	derived := []*Logger{logger, logger.Clone(), logger.WithFields(map[string]interface{}{"req": 1})}
+
+	var wg sync.WaitGroup
+	for _, l := range derived {
+		wg.Add(1)
+		go func(l *Logger) {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				l.LogEvery(time.Minute, LevelInfo, "cache still cold")
+			}
+		}(l)
+	}
+	wg.Wait()
+	if got := strings.Count(buf.String(), "cache still cold"); got != 1 {
+		t.Errorf("logged %d times across clones, want 1", got)
+	}
+}
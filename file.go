@@ -0,0 +1,152 @@
+package notifyme
+
+import (
+	"os"
+	"sync"
+)
+
+// reopenableFile is an append-only file writer whose underlying file can be
+// reopened at the same path, e.g. after logrotate has renamed it. Loggers
+// and their clones share one instance so a reopen is seen by all of them.
+type reopenableFile struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// openReopenableFile opens path for appending, creating it if needed
+func openReopenableFile(path string) (*reopenableFile, error) {
+	file, err := openLogFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &reopenableFile{path: path, file: file}, nil
+}
+
+// openLogFile opens a log file for appending
+func openLogFile(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+}
+
+// Write writes to the currently open file
+func (f *reopenableFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Write(p)
+}
+
+// Sync commits the current file's contents to stable storage
+func (f *reopenableFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Sync()
+}
+
+// Close closes the currently open file
+func (f *reopenableFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+// Reopen opens the path again and closes the previous file
+func (f *reopenableFile) Reopen() error {
+	file, err := openLogFile(f.path)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	old := f.file
+	f.file = file
+	f.mu.Unlock()
+	return old.Close()
+}
+
+// Reopen closes and reopens the log file the logger was created with and
+// any files added with WithLevelFile, so writing continues at the original
+// paths after an external rotation. The main output is left alone for
+// loggers writing to stdout or another non-file writer.
+//
+// To integrate with logrotate, call it when the process receives SIGHUP:
+//
+//	sighup := make(chan os.Signal, 1)
+//	signal.Notify(sighup, syscall.SIGHUP)
+//	go func() {
+//		for range sighup {
+//			if err := logger.Reopen(); err != nil {
+//				log.Printf("reopen failed: %v", err)
+//			}
+//		}
+//	}()
+func (l *Logger) Reopen() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var firstErr error
+	if file, ok := l.infoLogger.Writer().(*reopenableFile); ok {
+		firstErr = file.Reopen()
+	}
+	for _, lf := range l.levelFiles {
+		if err := lf.file.Reopen(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// levelFile is an extra file output for entries at or above a level
+type levelFile struct {
+	minLevel int
+	file     *reopenableFile
+	owner    *Logger // the logger that opened the file and closes it
+}
+
+// WithLevelFile additionally writes entries at or above minLevel to the
+// file at path, in the same format as the primary output. The file is
+// reopened together with the main one by Reopen and closed by Close.
+func WithLevelFile(path string, minLevel int) Option {
+	return func(l *Logger) error {
+		file, err := openReopenableFile(path)
+		if err != nil {
+			return err
+		}
+		l.levelFiles = append(l.levelFiles[:len(l.levelFiles):len(l.levelFiles)], levelFile{minLevel: minLevel, file: file, owner: l})
+		return nil
+	}
+}
+
+// WithSeparateErrorFile additionally writes ERROR and CRITICAL entries to
+// the file at path, the common "everything to app.log, errors also to
+// error.log" setup
+func WithSeparateErrorFile(path string) Option {
+	return WithLevelFile(path, LevelError)
+}
+
+// writeLevelFiles writes the entry to every level file it qualifies for. It
+// must be called with the logger mutex held.
+func (l *Logger) writeLevelFiles(entry Entry) {
+	for _, lf := range l.levelFiles {
+		if entry.Level >= lf.minLevel {
+			l.writeTo(lf.file, entry)
+		}
+	}
+}
+
+// closeFiles closes the level files the logger opened and its main log
+// file, returning the first error
+func (l *Logger) closeFiles(files []levelFile, output *reopenableFile) error {
+	var firstErr error
+	for _, lf := range files {
+		if lf.owner != l {
+			continue
+		}
+		if err := lf.file.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if output != nil {
+		if err := output.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
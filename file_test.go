@@ -0,0 +1,200 @@
+package notifyme
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readLines returns the lines of the file at path
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := strings.TrimSuffix(string(data), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+func TestReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	rotated := filepath.Join(dir, "app.log.1")
+	logger, err := NewLoggerE(LevelInfo, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	logger.Log(LevelInfo, "before rotation")
+	if err := os.Rename(path, rotated); err != nil {
+		t.Fatal(err)
+	}
+	logger.Log(LevelInfo, "after rename")
+	if err := logger.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	logger.Log(LevelInfo, "after reopen")
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{rotated, []string{"before rotation", "after rename"}},
+		{path, []string{"after reopen"}},
+	}
+	for _, tt := range tests {
+		lines := readLines(t, tt.path)
+		if len(lines) != len(tt.want) {
+			t.Fatalf("%s has lines %q, want %q", filepath.Base(tt.path), lines, tt.want)
+		}
+		for i, want := range tt.want {
+			if !strings.HasSuffix(lines[i], want) {
+				t.Errorf("%s line %d = %q, want %q", filepath.Base(tt.path), i, lines[i], want)
+			}
+		}
+	}
+}
+
+func TestReopenSharedWithClones(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	logger, err := NewLoggerE(LevelInfo, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+	clone := logger.Named("worker")
+
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	clone.Log(LevelInfo, "from clone")
+	if lines := readLines(t, path); len(lines) != 1 || !strings.HasSuffix(lines[0], "from clone") {
+		t.Errorf("reopened file has %q, want the clone's entry", lines)
+	}
+}
+
+func TestReopenNonFileWriter(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &strings.Builder{})
+	if err := logger.Reopen(); err != nil {
+		t.Errorf("Reopen of a non-file writer = %v, want nil", err)
+	}
+}
+
+func TestSeparateErrorFile(t *testing.T) {
+	dir := t.TempDir()
+	appLog := filepath.Join(dir, "app.log")
+	errorLog := filepath.Join(dir, "error.log")
+	logger, err := NewLoggerE(LevelInfo, appLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.Configure(WithSeparateErrorFile(errorLog)); err != nil {
+		t.Fatal(err)
+	}
+
+	logged := []struct {
+		level   int
+		message string
+	}{
+		{LevelInfo, "started"},
+		{LevelError, "query failed"},
+		{LevelWarn, "slow query"},
+		{LevelCritical, "disk full"},
+		{LevelInfo, "retrying"},
+	}
+	for _, entry := range logged {
+		logger.Log(entry.level, entry.message)
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{appLog, []string{"INFO: started", "ERROR: query failed", "WARN: slow query", "CRITICAL: disk full", "INFO: retrying"}},
+		{errorLog, []string{"ERROR: query failed", "CRITICAL: disk full"}},
+	}
+	for _, tt := range tests {
+		lines := readLines(t, tt.path)
+		if len(lines) != len(tt.want) {
+			t.Fatalf("%s has %d lines, want %d: %q", filepath.Base(tt.path), len(lines), len(tt.want), lines)
+		}
+		for i, want := range tt.want {
+			prefix, message, _ := strings.Cut(want, ": ")
+			if !strings.HasPrefix(lines[i], prefix+": ") || !strings.HasSuffix(lines[i], "] "+message) {
+				t.Errorf("%s line %d = %q, want %s entry %q", filepath.Base(tt.path), i, lines[i], prefix, message)
+			}
+		}
+	}
+}
+
+func TestLevelFileThreshold(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "warn.log")
+	logger := newWriterLogger(LevelInfo, &strings.Builder{})
+	if err := logger.Configure(WithLevelFile(path, LevelWarn)); err != nil {
+		t.Fatal(err)
+	}
+	for level := LevelInfo; level <= LevelCritical; level++ {
+		logger.Log(level, levelName(level))
+	}
+	logger.Close()
+
+	lines := readLines(t, path)
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want WARN, ERROR and CRITICAL: %q", len(lines), lines)
+	}
+	for i, name := range []string{"WARN", "ERROR", "CRITICAL"} {
+		if !strings.HasPrefix(lines[i], name+": ") {
+			t.Errorf("line %d = %q, want a %s entry", i, lines[i], name)
+		}
+	}
+}
+
+func TestCloseClosesFiles(t *testing.T) {
+	dir := t.TempDir()
+	logger, err := NewLoggerE(LevelInfo, filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.Configure(WithSeparateErrorFile(filepath.Join(dir, "error.log"))); err != nil {
+		t.Fatal(err)
+	}
+	main := logger.output
+	errorFile := logger.levelFiles[0].file
+
+	// A clone shares the files, and closing it leaves them open
+	if err := logger.WithFields(map[string]interface{}{"req": 1}).Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []*reopenableFile{main, errorFile} {
+		if _, err := f.Write([]byte("still open\n")); err != nil {
+			t.Errorf("write to %s after closing a clone: %v", filepath.Base(f.path), err)
+		}
+	}
+
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []*reopenableFile{main, errorFile} {
+		if _, err := f.Write([]byte("closed\n")); !errors.Is(err, os.ErrClosed) {
+			t.Errorf("write to %s after Close = %v, want os.ErrClosed", filepath.Base(f.path), err)
+		}
+	}
+	if err := logger.Close(); err != nil {
+		t.Errorf("second Close = %v", err)
+	}
+}
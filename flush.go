@@ -0,0 +1,76 @@
+package notifyme
+
+import "fmt"
+
+// Flusher is implemented by sinks that buffer entries, such as
+// ElasticSink. Flush sends everything buffered so far.
+type Flusher interface {
+	Flush() error
+}
+
+// WithFlushOnLevel makes every entry at or above level flush the logger
+// before the logging call returns: queued sink deliveries are waited for,
+// sinks implementing Flusher are flushed and log files are synced to disk.
+// This keeps the most important entries from being lost in a crash at the
+// cost of slower logging for those levels.
+func WithFlushOnLevel(level int) Option {
+	return func(l *Logger) error {
+		l.opts.flushOnLevel = true
+		l.opts.flushLevel = level
+		l.opts.flushLevels = nil
+		return nil
+	}
+}
+
+// WithFlushLevels is WithFlushOnLevel for exactly the given levels instead
+// of a threshold, for example WARN and CRITICAL flushing while ERROR, which
+// is reported elsewhere, stays buffered. It replaces any WithFlushOnLevel
+// setting; with no levels nothing flushes.
+func WithFlushLevels(levels ...int) Option {
+	return func(l *Logger) error {
+		flushLevels := make(map[int]bool, len(levels))
+		for _, level := range levels {
+			flushLevels[level] = true
+		}
+		l.opts.flushOnLevel = false
+		l.opts.flushLevels = flushLevels
+		return nil
+	}
+}
+
+// flushesAt reports whether entries at level flush the logger. It must be
+// called with the logger mutex held.
+func (l *Logger) flushesAt(level int) bool {
+	if l.opts.flushLevels != nil {
+		return l.opts.flushLevels[level]
+	}
+	return l.opts.flushOnLevel && level >= l.opts.flushLevel
+}
+
+// flush drains the sink queue, flushes buffering sinks and syncs the
+// output files, reporting failures to the error handler. It must be called
+// with the logger mutex held.
+func (l *Logger) flush() {
+	if pool := l.sinkPool.current(); pool != nil {
+		pool.wait()
+	}
+	for _, attached := range l.sinks {
+		if flusher, ok := attached.sink.(Flusher); ok {
+			if err := flusher.Flush(); err != nil {
+				l.errorHandler()(fmt.Errorf("notifyme: sink flush failed: %w", err))
+			}
+		}
+	}
+	files := make([]*reopenableFile, 0, len(l.levelFiles)+1)
+	if file, ok := l.infoLogger.Writer().(*reopenableFile); ok {
+		files = append(files, file)
+	}
+	for _, lf := range l.levelFiles {
+		files = append(files, lf.file)
+	}
+	for _, file := range files {
+		if err := file.Sync(); err != nil {
+			l.errorHandler()(fmt.Errorf("notifyme: log file sync failed: %w", err))
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package notifyme
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// bufferingSink holds entries until Flush moves them to flushed
+type bufferingSink struct {
+	mu      sync.Mutex
+	pending []string
+	flushed []string
+}
+
+func (s *bufferingSink) Write(entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = append(s.pending, entry.Message)
+	return nil
+}
+
+func (s *bufferingSink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flushed = append(s.flushed, s.pending...)
+	s.pending = nil
+	return nil
+}
+
+func (s *bufferingSink) Close() error {
+	return nil
+}
+
+// Flushed returns the messages flushed so far
+func (s *bufferingSink) Flushed() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.flushed...)
+}
+
+func TestWithFlushOnLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		level   int
+		flushes bool
+	}{
+		{"below threshold", []Option{WithFlushOnLevel(LevelError)}, LevelInfo, false},
+		{"warn below threshold", []Option{WithFlushOnLevel(LevelError)}, LevelWarn, false},
+		{"at threshold", []Option{WithFlushOnLevel(LevelError)}, LevelError, true},
+		{"above threshold", []Option{WithFlushOnLevel(LevelError)}, LevelCritical, true},
+		{"off by default", nil, LevelCritical, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &bufferingSink{}
+			logger := newPoolTestLogger(t, sink, tt.opts...)
+			logger.Log(LevelInfo, "buffered")
+			logger.Log(tt.level, "severe")
+
+			var want []string
+			if tt.flushes {
+				want = []string{"buffered", "severe"}
+			}
+			if got := sink.Flushed(); !reflect.DeepEqual(got, want) {
+				t.Errorf("flushed %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestWithFlushOnLevelWaitsForSinkQueue(t *testing.T) {
+	sink := &slowSink{delay: 5 * time.Millisecond}
+	logger := newPoolTestLogger(t, sink, WithSinkConcurrency(1), WithFlushOnLevel(LevelError))
+	defer logger.Close()
+	logger.Log(LevelInfo, "queued")
+	logger.Log(LevelError, "severe")
+
+	sink.mu.Lock()
+	got := append([]string(nil), sink.messages...)
+	sink.mu.Unlock()
+	if want := []string{"queued", "severe"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("delivered %q when the ERROR call returned, want %q", got, want)
+	}
+}
+
+func TestWithFlushLevels(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		flushes map[int]bool
+	}{
+		{"warn and above", []Option{WithFlushLevels(LevelWarn, LevelError, LevelCritical)},
+			map[int]bool{LevelInfo: false, LevelWarn: true, LevelError: true, LevelCritical: true}},
+		{"skipping a level", []Option{WithFlushLevels(LevelWarn, LevelCritical)},
+			map[int]bool{LevelInfo: false, LevelWarn: true, LevelError: false, LevelCritical: true}},
+		{"no levels", []Option{WithFlushLevels()},
+			map[int]bool{LevelInfo: false, LevelWarn: false, LevelError: false, LevelCritical: false}},
+		{"replaces a threshold", []Option{WithFlushOnLevel(LevelInfo), WithFlushLevels(LevelError)},
+			map[int]bool{LevelInfo: false, LevelWarn: false, LevelError: true, LevelCritical: false}},
+		{"replaced by a threshold", []Option{WithFlushLevels(LevelInfo), WithFlushOnLevel(LevelError)},
+			map[int]bool{LevelInfo: false, LevelWarn: false, LevelError: true, LevelCritical: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for level, flushes := range tt.flushes {
+				sink := &bufferingSink{}
+				logger := newPoolTestLogger(t, sink, tt.opts...)
+				logger.Log(level, "entry")
+
+				var want []string
+				if flushes {
+					want = []string{"entry"}
+				}
+				if got := sink.Flushed(); !reflect.DeepEqual(got, want) {
+					t.Errorf("%s: flushed %q, want %q", levelName(level), got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWithFlushLevelsKeepsInfoBuffered(t *testing.T) {
+	sink := &bufferingSink{}
+	logger := newPoolTestLogger(t, sink, WithFlushLevels(LevelWarn))
+	logger.Log(LevelInfo, "one")
+	logger.Log(LevelInfo, "two")
+	if got := sink.Flushed(); len(got) != 0 {
+		t.Fatalf("flushed %q after INFO entries, want them buffered", got)
+	}
+	logger.Log(LevelWarn, "disk almost full")
+	if got, want := sink.Flushed(), []string{"one", "two", "disk almost full"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("flushed %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,358 @@
+package notifyme
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+)
+
+// Format selects how entries are written to the primary output
+type Format int
+
+// Output formats
+const (
+	// FormatText writes one human-readable line per entry (the default)
+	FormatText Format = iota
+	// FormatJSON writes one JSON object per line
+	FormatJSON
+	// FormatECS writes one Elastic Common Schema JSON object per line
+	FormatECS
+	// FormatProto writes length-delimited protobuf messages following
+	// entry.proto; read them back with ProtoReader
+	FormatProto
+	// FormatRFC5424 writes one RFC5424 syslog line per entry
+	FormatRFC5424
+)
+
+// Default key names of the standard fields in JSON output
+const (
+	defaultTimeKey    = "ts"
+	defaultLevelKey   = "level"
+	defaultMessageKey = "msg"
+)
+
+// jsonKeys holds the configurable key names of the standard JSON fields
+type jsonKeys struct {
+	time    string
+	level   string
+	message string
+}
+
+// WithFormat sets the output format of the primary output. Sinks always
+// receive the Entry itself.
+//
+// Output is deterministic in every format. JSON objects start with the
+// standard keys in a fixed order (time, level, level_num, severity, msg,
+// caller, func, logger, event_ts, times_seen), followed by the fields in the order they
+// were added; WithFields adds them in key order. Map values are written
+// with their keys sorted, as in text output. ECS output sorts all keys
+// after @timestamp, log.level, message and ecs.version.
+func WithFormat(format Format) Option {
+	return func(l *Logger) error {
+		if format < FormatText || format > FormatRFC5424 {
+			return errors.New("notifyme: unknown output format")
+		}
+		l.opts.format = format
+		return nil
+	}
+}
+
+// WithTimeKey sets the key of the timestamp in JSON output, e.g. "@timestamp"
+func WithTimeKey(key string) Option {
+	return func(l *Logger) error {
+		if key == "" {
+			return errors.New("notifyme: time key must not be empty")
+		}
+		l.opts.jsonKeys.time = key
+		return nil
+	}
+}
+
+// WithLevelKey sets the key of the level in JSON output, e.g. "log.level"
+func WithLevelKey(key string) Option {
+	return func(l *Logger) error {
+		if key == "" {
+			return errors.New("notifyme: level key must not be empty")
+		}
+		l.opts.jsonKeys.level = key
+		return nil
+	}
+}
+
+// WithMessageKey sets the key of the message in JSON output, e.g. "message"
+func WithMessageKey(key string) Option {
+	return func(l *Logger) error {
+		if key == "" {
+			return errors.New("notifyme: message key must not be empty")
+		}
+		l.opts.jsonKeys.message = key
+		return nil
+	}
+}
+
+// WithNumericLevelField adds the level as a number under "level_num" next
+// to its name in JSON output, for consumers that filter on either. The
+// number is the level constant, e.g. 2 for LevelError, and always matches
+// the name; a mapping set with WithSeverityMapping still only changes the
+// separate "severity" key.
+func WithNumericLevelField() Option {
+	return func(l *Logger) error {
+		l.opts.numericLevel = true
+		return nil
+	}
+}
+
+// WithPrettyJSON indents JSON output like json.MarshalIndent, one key per
+// line, to make entries readable during local development. Each entry then
+// spans several lines, which breaks tools that read one entry per line, so
+// it should not be used in production.
+func WithPrettyJSON() Option {
+	return func(l *Logger) error {
+		l.opts.prettyJSON = true
+		return nil
+	}
+}
+
+// WithTimeKeyAsEpoch writes the JSON timestamps as integer Unix epoch
+// values in the given precision, e.g. 1700000000123 for milliseconds,
+// instead of RFC 3339 strings. It applies to the time key set by
+// WithTimeKey and to event_ts.
+func WithTimeKeyAsEpoch(precision Precision) Option {
+	return func(l *Logger) error {
+		if precision < PrecisionSeconds || precision > PrecisionNanoseconds {
+			return errors.New("notifyme: unknown timestamp precision")
+		}
+		l.opts.epochTime = true
+		l.opts.epochPrecision = precision
+		return nil
+	}
+}
+
+// formatJSON renders an entry as a single JSON line, or indented with
+// WithPrettyJSON. The standard fields
+// come first in a fixed order, followed by the entry's fields; fields named
+// like a standard field are left out. It must be called with the logger
+// mutex held.
+func (l *Logger) formatJSON(entry Entry) ([]byte, error) {
+	obj := jsonObject{}
+	obj.add(keyOr(l.opts.jsonKeys.time, defaultTimeKey), l.jsonTime(entry.Time))
+	obj.add(keyOr(l.opts.jsonKeys.level, defaultLevelKey), levelName(entry.Level))
+	if l.opts.numericLevel {
+		obj.add("level_num", entry.Level)
+	}
+	if l.opts.severities != nil {
+		obj.add("severity", entry.Severity)
+	}
+	obj.add(keyOr(l.opts.jsonKeys.message, defaultMessageKey), entry.Message)
+	obj.add("caller", entry.Caller.String())
+	if entry.Caller.Function != "" {
+		obj.add("func", entry.Caller.Function)
+	}
+	if entry.Name != "" {
+		obj.add("logger", entry.Name)
+	}
+	if !entry.EventTime.IsZero() {
+		obj.add("event_ts", l.jsonTime(entry.EventTime))
+	}
+	if entry.TimesSeen > 1 {
+		obj.add("times_seen", entry.TimesSeen)
+	}
+	for _, field := range entry.Fields {
+		obj.add(field.Key, l.jsonValue(field.Value))
+	}
+	data, err := obj.bytes()
+	if err != nil || !l.opts.prettyJSON {
+		return data, err
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, data, "", "  "); err != nil {
+		return nil, err
+	}
+	return indented.Bytes(), nil
+}
+
+// jsonTime renders a timestamp for JSON output, as an epoch number if
+// WithTimeKeyAsEpoch is set and as a string otherwise
+func (l *Logger) jsonTime(t time.Time) interface{} {
+	if !l.opts.epochTime {
+		return l.formatJSONTime(t)
+	}
+	switch l.opts.epochPrecision {
+	case PrecisionMilliseconds:
+		return t.UnixMilli()
+	case PrecisionMicroseconds:
+		return t.UnixMicro()
+	case PrecisionNanoseconds:
+		return t.UnixNano()
+	default:
+		return t.Unix()
+	}
+}
+
+// formatJSONTime renders a timestamp in the configured time zone with the
+// JSON time format, or as RFC 3339 with the configured fractional-second
+// precision
+func (l *Logger) formatJSONTime(t time.Time) string {
+	if l.opts.jsonTimeLayout != "" {
+		return l.inZone(t).Format(l.opts.jsonTimeLayout)
+	}
+	return l.formatRFC3339(l.inZone(t))
+}
+
+// formatRFC3339 renders a timestamp as RFC 3339 in its own location with
+// the configured fractional-second precision
+func (l *Logger) formatRFC3339(t time.Time) string {
+	switch l.opts.precision {
+	case PrecisionMilliseconds:
+		return t.Format("2006-01-02T15:04:05.000Z07:00")
+	case PrecisionMicroseconds:
+		return t.Format("2006-01-02T15:04:05.000000Z07:00")
+	case PrecisionNanoseconds:
+		return t.Format("2006-01-02T15:04:05.000000000Z07:00")
+	default:
+		return t.Format(time.RFC3339)
+	}
+}
+
+// jsonValue prepares a field value for JSON encoding, applying the bytes
+// encoding and falling back to the text rendering for values that cannot be
+// marshaled. It must be called with the logger mutex held.
+func (l *Logger) jsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return l.formatBytes(v)
+	case time.Duration:
+		return l.jsonDuration(v)
+	case error:
+		if isNilPointer(reflect.ValueOf(v)) {
+			return nil
+		}
+		return v.Error()
+	case json.Marshaler:
+		return v
+	}
+	if l.opts.maxDepth > 0 {
+		return l.limitDepth(value)
+	}
+	if _, err := json.Marshal(value); err != nil {
+		return l.formatValue(value)
+	}
+	return value
+}
+
+// defaultJSONValue prepares a field value for JSON encoding with default
+// options, as EncodeEntry does, for sinks that marshal entries themselves
+func defaultJSONValue(value interface{}) interface{} {
+	var l Logger
+	return l.jsonValue(value)
+}
+
+// defaultTextValue renders a field value as text with default options
+func defaultTextValue(value interface{}) interface{} {
+	var l Logger
+	return l.formatValue(value)
+}
+
+// keyOr returns key, or def when key is empty
+func keyOr(key, def string) string {
+	if key == "" {
+		return def
+	}
+	return key
+}
+
+// jsonObject builds a JSON object whose keys keep insertion order
+type jsonObject struct {
+	buf  bytes.Buffer
+	keys map[string]bool
+	err  error
+}
+
+// add appends a key/value pair to the object. Keys already added are
+// skipped, so the first value for a key wins and the output never repeats
+// a key.
+func (o *jsonObject) add(key string, value interface{}) {
+	if o.err != nil || o.keys[key] {
+		return
+	}
+	if o.keys == nil {
+		o.keys = make(map[string]bool)
+	}
+	o.keys[key] = true
+	if o.buf.Len() == 0 {
+		o.buf.WriteByte('{')
+	} else {
+		o.buf.WriteByte(',')
+	}
+	k, err := json.Marshal(key)
+	if err != nil {
+		o.err = err
+		return
+	}
+	v, err := json.Marshal(value)
+	if err != nil {
+		o.err = err
+		return
+	}
+	o.buf.Write(k)
+	o.buf.WriteByte(':')
+	o.buf.Write(v)
+}
+
+// bytes returns the finished object followed by a newline
+func (o *jsonObject) bytes() ([]byte, error) {
+	if o.err != nil {
+		return nil, o.err
+	}
+	if o.buf.Len() == 0 {
+		o.buf.WriteByte('{')
+	}
+	o.buf.WriteString("}\n")
+	return o.buf.Bytes(), nil
+}
+
+// EncodeEntry encodes an entry as the given format would write it with
+// default options. It is meant for sinks that ship entries elsewhere. Text,
+// JSON, ECS and RFC5424 output end with a newline.
+func EncodeEntry(entry Entry, format Format) ([]byte, error) {
+	if format < FormatText || format > FormatRFC5424 {
+		return nil, errors.New("notifyme: unknown output format")
+	}
+	l := &Logger{opts: loggerOptions{format: format}}
+	if format == FormatText {
+		return []byte(levelName(entry.Level) + ": " + l.formatText(entry) + "\n"), nil
+	}
+	return l.encode(entry)
+}
+
+// encode renders the entry in the configured non-text format. It must be
+// called with the logger mutex held.
+func (l *Logger) encode(entry Entry) ([]byte, error) {
+	switch l.opts.format {
+	case FormatECS:
+		return l.formatECS(entry)
+	case FormatProto:
+		return l.formatProto(entry)
+	case FormatRFC5424:
+		return l.formatRFC5424(entry)
+	default:
+		return l.formatJSON(entry)
+	}
+}
+
+// writeEncoded encodes the entry in the configured non-text format and
+// writes it to w. It must be called with the logger mutex held.
+func (l *Logger) writeEncoded(w io.Writer, entry Entry) {
+	data, err := l.encode(entry)
+	if err != nil {
+		l.errorHandler()(fmt.Errorf("notifyme: encoding entry: %w", err))
+		return
+	}
+	if _, err := w.Write(data); err != nil {
+		l.writeFallback(string(data), err)
+	}
+}
@@ -0,0 +1,215 @@
+package notifyme
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newJSONTestLogger returns a JSON logger writing to buf with a fixed UTC
+// clock
+func newJSONTestLogger(t *testing.T, buf *bytes.Buffer, opts ...Option) *Logger {
+	t.Helper()
+	logger := newWriterLogger(LevelInfo, buf)
+	logger.now = func() time.Time { return time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC) }
+	if err := logger.Configure(append([]Option{WithFormat(FormatJSON)}, opts...)...); err != nil {
+		t.Fatal(err)
+	}
+	return logger
+}
+
+func TestJSONKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []Option
+		prefix string
+	}{
+		{"default", nil, `{"ts":"2024-03-09T14:05:06Z","level":"WARN","msg":"disk slow",`},
+		{
+			"ecs style",
+			[]Option{WithTimeKey("@timestamp"), WithLevelKey("log.level"), WithMessageKey("message")},
+			`{"@timestamp":"2024-03-09T14:05:06Z","log.level":"WARN","message":"disk slow",`,
+		},
+		{"message only", []Option{WithMessageKey("message")}, `{"ts":"2024-03-09T14:05:06Z","level":"WARN","message":"disk slow",`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newJSONTestLogger(t, &buf, tt.opts...)
+			logger.With("disk", "sda").Log(LevelWarn, "disk slow")
+			if !strings.HasPrefix(buf.String(), tt.prefix) {
+				t.Errorf("output = %q, want prefix %q", buf.String(), tt.prefix)
+			}
+			if !strings.HasSuffix(buf.String(), `,"disk":"sda"}`+"\n") {
+				t.Errorf("output = %q, want the field last", buf.String())
+			}
+		})
+	}
+}
+
+func TestJSONKeysOverrideFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newJSONTestLogger(t, &buf, WithMessageKey("message"))
+	logger.With("message", "from field", "msg", "kept").Log(LevelInfo, "from entry")
+	var doc map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["message"] != "from entry" || doc["msg"] != "kept" {
+		t.Errorf("message = %v and msg = %v, want the entry message and the msg field", doc["message"], doc["msg"])
+	}
+	if strings.Count(buf.String(), `"message"`) != 1 {
+		t.Errorf("message key repeated: %q", buf.String())
+	}
+}
+
+func TestJSONKeysEmpty(t *testing.T) {
+	for _, opt := range []Option{WithTimeKey(""), WithLevelKey(""), WithMessageKey("")} {
+		logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+		if err := logger.Configure(opt); err == nil {
+			t.Error("Configure accepted an empty key")
+		}
+	}
+}
+
+func TestWithNumericLevelField(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		severity interface{}
+	}{
+		{"alone", []Option{WithNumericLevelField()}, nil},
+		{"with severity mapping", []Option{WithNumericLevelField(), WithSeverityMapping(map[int]int{LevelError: 3})}, 3.0},
+		{"with level key", []Option{WithNumericLevelField(), WithLevelKey("log.level")}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newJSONTestLogger(t, &buf, tt.opts...)
+			logger.With("level_num", "field").Log(LevelError, "disk full")
+			var doc map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+				t.Fatal(err)
+			}
+			name, _ := doc["level"].(string)
+			if name == "" {
+				name, _ = doc["log.level"].(string)
+			}
+			level, err := ParseLevel(name)
+			if err != nil || doc["level_num"] != float64(level) || level != LevelError {
+				t.Errorf("level = %v and level_num = %v, want ERROR and %d", name, doc["level_num"], LevelError)
+			}
+			if doc["severity"] != tt.severity {
+				t.Errorf("severity = %v, want %v", doc["severity"], tt.severity)
+			}
+			if !strings.Contains(buf.String(), `"ERROR","level_num":2,`) {
+				t.Errorf("output = %q, want level_num right after the level", buf.String())
+			}
+		})
+	}
+}
+
+func TestNumericLevelFieldOmittedByDefault(t *testing.T) {
+	var buf bytes.Buffer
+	newJSONTestLogger(t, &buf).Log(LevelWarn, "disk slow")
+	if strings.Contains(buf.String(), "level_num") {
+		t.Errorf("output = %q, want no level_num without the option", buf.String())
+	}
+}
+
+func TestWithPrettyJSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newJSONTestLogger(t, &buf, WithPrettyJSON())
+	logger.With("disk", "sda").Log(LevelWarn, "disk slow")
+	logger.NewEvent(LevelWarn).Str("disk", "sdb").Msg("disk slow")
+
+	dec := json.NewDecoder(&buf)
+	for _, disk := range []string{"sda", "sdb"} {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			t.Fatal(err)
+		}
+		var want bytes.Buffer
+		if err := json.Indent(&want, raw, "", "  "); err != nil {
+			t.Fatal(err)
+		}
+		if string(raw) != want.String() || !strings.Contains(string(raw), "\n  \"disk\": \""+disk+"\"\n}") {
+			t.Errorf("entry = %q, want it indented one key per line", raw)
+		}
+	}
+}
+
+func TestWithTimeKeyAsEpoch(t *testing.T) {
+	tests := []struct {
+		name      string
+		precision Precision
+		want      int64
+	}{
+		{"seconds", PrecisionSeconds, fixedTime.Unix()},
+		{"milliseconds", PrecisionMilliseconds, fixedTime.UnixMilli()},
+		{"microseconds", PrecisionMicroseconds, fixedTime.UnixMicro()},
+		{"nanoseconds", PrecisionNanoseconds, fixedTime.UnixNano()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"", "@timestamp"} {
+				var buf bytes.Buffer
+				opts := []Option{WithFormat(FormatJSON), WithTimeKeyAsEpoch(tt.precision)}
+				if key != "" {
+					opts = append(opts, WithTimeKey(key))
+				}
+				logger := newEventTestLogger(t, &buf, opts...)
+				logger.LogAt(fixedTime.Add(-time.Minute), LevelInfo, "late")
+
+				doc := decodeJSONNumbers(t, buf.Bytes())
+				timeKey := keyOr(key, defaultTimeKey)
+				if got := doc[timeKey]; got != json.Number(strconv.FormatInt(tt.want, 10)) {
+					t.Errorf("%s = %v, want %d", timeKey, got, tt.want)
+				}
+				if key != "" && doc[defaultTimeKey] != nil {
+					t.Errorf("default time key still written: %s", buf.String())
+				}
+				wantEvent := epochAt(fixedTime.Add(-time.Minute), tt.precision)
+				if got := doc["event_ts"]; got != json.Number(strconv.FormatInt(wantEvent, 10)) {
+					t.Errorf("event_ts = %v, want %d", got, wantEvent)
+				}
+			}
+		})
+	}
+}
+
+// epochAt returns t as a Unix epoch value in the given precision
+func epochAt(t time.Time, precision Precision) int64 {
+	switch precision {
+	case PrecisionMilliseconds:
+		return t.UnixMilli()
+	case PrecisionMicroseconds:
+		return t.UnixMicro()
+	case PrecisionNanoseconds:
+		return t.UnixNano()
+	default:
+		return t.Unix()
+	}
+}
+
+// decodeJSONNumbers decodes a JSON line keeping numbers exact
+func decodeJSONNumbers(t *testing.T, data []byte) map[string]interface{} {
+	t.Helper()
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc map[string]interface{}
+	if err := dec.Decode(&doc); err != nil {
+		t.Fatalf("decoding %q: %v", data, err)
+	}
+	return doc
+}
+
+func TestWithTimeKeyAsEpochInvalid(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	if err := logger.Configure(WithTimeKeyAsEpoch(Precision(-1))); err == nil {
+		t.Error("Configure accepted an unknown precision")
+	}
+}
@@ -0,0 +1,51 @@
+package notifyme
+
+import (
+	"errors"
+	"time"
+)
+
+// heartbeat is a running periodic heartbeat entry
+type heartbeat struct {
+	stop chan struct{}
+	done chan struct{}
+}
+
+// WithHeartbeat logs msg at level every interval until the logger is
+// closed, so pipelines can tell an idle process from a stuck one. Applying
+// it again replaces the previous heartbeat. Clones do not inherit it. The
+// ticker comes from the clock set with WithClock, if any.
+func WithHeartbeat(interval time.Duration, level int, msg string) Option {
+	return func(l *Logger) error {
+		if interval <= 0 {
+			return errors.New("notifyme: heartbeat interval must be positive")
+		}
+		if l.heartbeat != nil {
+			close(l.heartbeat.stop)
+		}
+		hb := &heartbeat{stop: make(chan struct{}), done: make(chan struct{})}
+		l.heartbeat = hb
+		go l.runHeartbeat(hb, l.newTicker(interval), level, msg)
+		return nil
+	}
+}
+
+// runHeartbeat logs the heartbeat on every tick until it is stopped
+func (l *Logger) runHeartbeat(hb *heartbeat, ticker Ticker, level int, msg string) {
+	defer close(hb.done)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C():
+			l.logDepth(1, level, msg)
+		case <-hb.stop:
+			return
+		}
+	}
+}
+
+// stopAndWait ends the heartbeat and waits for its goroutine to exit
+func (hb *heartbeat) stopAndWait() {
+	close(hb.stop)
+	<-hb.done
+}
@@ -0,0 +1,105 @@
+package notifyme
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+// chanSink sends every entry it receives on a channel
+type chanSink chan Entry
+
+func (s chanSink) Write(entry Entry) error {
+	s <- entry
+	return nil
+}
+
+func (s chanSink) Close() error {
+	return nil
+}
+
+func TestHeartbeat(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := newFakeClock(start)
+	logger := newWriterLogger(LevelInfo, io.Discard)
+	entries := make(chanSink, 10)
+	logger.AddSink(entries)
+	if err := logger.Configure(WithClock(clock), WithHeartbeat(10*time.Second, LevelWarn, "alive")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each step waits for its heartbeat, so the entry is timed before the
+	// clock moves on
+	tests := []struct {
+		advance time.Duration
+		beat    bool
+	}{
+		{5 * time.Second, false},
+		{5 * time.Second, true},
+		{10 * time.Second, true},
+		{9 * time.Second, false},
+		{time.Second, true},
+	}
+	elapsed := time.Duration(0)
+	for _, tt := range tests {
+		clock.Advance(tt.advance)
+		elapsed += tt.advance
+		if tt.beat {
+			entry := <-entries
+			if entry.Message != "alive" || entry.Level != LevelWarn {
+				t.Errorf("heartbeat entry = %q at level %d, want %q at WARN", entry.Message, entry.Level, "alive")
+			}
+			if want := start.Add(elapsed); !entry.Time.Equal(want) {
+				t.Errorf("heartbeat at %v, want %v", entry.Time, want)
+			}
+		}
+		select {
+		case entry := <-entries:
+			t.Errorf("unexpected entry %q at %v", entry.Message, entry.Time)
+		default:
+		}
+	}
+
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := clock.running(); n != 0 {
+		t.Errorf("%d tickers still running after Close", n)
+	}
+	clock.Advance(time.Minute)
+	if len(entries) != 0 {
+		t.Errorf("heartbeat logged after Close")
+	}
+}
+
+func TestHeartbeatReplaced(t *testing.T) {
+	clock := newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	logger := newWriterLogger(LevelInfo, io.Discard)
+	entries := make(chanSink, 10)
+	logger.AddSink(entries)
+	if err := logger.Configure(WithClock(clock), WithHeartbeat(time.Second, LevelInfo, "old")); err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.Configure(WithHeartbeat(time.Second, LevelInfo, "new")); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for clock.running() != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := clock.running(); n != 1 {
+		t.Fatalf("%d tickers running, want 1", n)
+	}
+	clock.Advance(time.Second)
+	if entry := <-entries; entry.Message != "new" {
+		t.Errorf("heartbeat = %q, want %q", entry.Message, "new")
+	}
+	logger.Close()
+}
+
+func TestHeartbeatInvalidInterval(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, io.Discard)
+	if err := logger.Configure(WithHeartbeat(0, LevelInfo, "x")); err == nil {
+		t.Error("WithHeartbeat(0) did not fail")
+	}
+}
@@ -0,0 +1,213 @@
+// Package kafkasink provides a notifyme.Sink producing entries to a Kafka
+// topic. It lives in its own module so the core package does not depend on
+// the Kafka client.
+package kafkasink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	notifyme "github.com/AmosSParker/NotifyMe"
+	"github.com/segmentio/kafka-go"
+)
+
+// Defaults used by Sink when the config leaves them unset
+const (
+	defaultBatchSize     = 100
+	defaultFlushInterval = time.Second
+	defaultMaxPending    = 10000
+	defaultWriteTimeout  = 10 * time.Second
+)
+
+// Producer writes messages to Kafka. *kafka.Writer implements it; tests can
+// pass their own.
+type Producer interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// Config configures a Sink
+type Config struct {
+	// Brokers are the bootstrap broker addresses, e.g. "localhost:9092".
+	// They are ignored when Producer is set.
+	Brokers []string
+	// Topic is the topic every entry is produced to
+	Topic string
+	// Key returns the partition key of an entry. Entries without a key are
+	// spread over partitions.
+	Key func(notifyme.Entry) []byte
+	// Format is the payload encoding: notifyme.FormatJSON (the default),
+	// FormatECS or FormatProto
+	Format notifyme.Format
+	// BatchSize is the number of entries that triggers a flush
+	BatchSize int
+	// FlushInterval is the maximum time entries wait before being sent
+	FlushInterval time.Duration
+	// MaxPending bounds the entries waiting to be sent. Entries arriving
+	// while it is reached are dropped and counted, so a slow or unreachable
+	// cluster never blocks the logger.
+	MaxPending int
+	// WriteTimeout bounds each produce request
+	WriteTimeout time.Duration
+	// Producer overrides the kafka.Writer built from Brokers
+	Producer Producer
+	// OnError receives errors from background flushes. Defaults to stderr.
+	OnError func(error)
+}
+
+// Sink produces entries to Kafka, batching them and flushing on size or
+// interval from a background goroutine
+type Sink struct {
+	config  Config
+	mu      sync.Mutex
+	pending []notifyme.Entry
+	dropped atomic.Int64
+	flushCh chan struct{}
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// New creates a Sink and starts its background flusher
+func New(config Config) (*Sink, error) {
+	if config.Topic == "" {
+		return nil, errors.New("kafkasink: topic must not be empty")
+	}
+	if config.Producer == nil && len(config.Brokers) == 0 {
+		return nil, errors.New("kafkasink: brokers must not be empty")
+	}
+	if config.Format == notifyme.FormatText {
+		config.Format = notifyme.FormatJSON
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultBatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaultFlushInterval
+	}
+	if config.MaxPending <= 0 {
+		config.MaxPending = defaultMaxPending
+	}
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = defaultWriteTimeout
+	}
+	if config.Producer == nil {
+		config.Producer = &kafka.Writer{
+			Addr:     kafka.TCP(config.Brokers...),
+			Balancer: &kafka.Hash{},
+		}
+	}
+	if config.OnError == nil {
+		config.OnError = func(err error) { fmt.Fprintln(os.Stderr, err) }
+	}
+	if _, err := notifyme.EncodeEntry(notifyme.Entry{}, config.Format); err != nil {
+		return nil, err
+	}
+
+	s := &Sink{
+		config:  config,
+		flushCh: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.run()
+	return s, nil
+}
+
+// Write queues the entry, waking the flusher once a full batch is pending.
+// It never blocks on Kafka.
+func (s *Sink) Write(entry notifyme.Entry) error {
+	s.mu.Lock()
+	if len(s.pending) >= s.config.MaxPending {
+		s.mu.Unlock()
+		s.dropped.Add(1)
+		return nil
+	}
+	s.pending = append(s.pending, entry)
+	full := len(s.pending) >= s.config.BatchSize
+	s.mu.Unlock()
+
+	if full {
+		select {
+		case s.flushCh <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Dropped returns how many entries were discarded because MaxPending was
+// reached
+func (s *Sink) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Flush sends all pending entries synchronously
+func (s *Sink) Flush() error {
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	return s.send(batch)
+}
+
+// Close stops the background flusher, sends any remaining entries and
+// closes the producer
+func (s *Sink) Close() error {
+	close(s.done)
+	s.wg.Wait()
+	err := s.Flush()
+	if cerr := s.config.Producer.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// run flushes pending entries on every interval tick or batch signal
+func (s *Sink) run() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.flushCh:
+		case <-s.done:
+			return
+		}
+		if err := s.Flush(); err != nil {
+			s.config.OnError(err)
+		}
+	}
+}
+
+// send produces a batch of entries as one request
+func (s *Sink) send(batch []notifyme.Entry) error {
+	msgs := make([]kafka.Message, 0, len(batch))
+	for _, entry := range batch {
+		value, err := notifyme.EncodeEntry(entry, s.config.Format)
+		if err != nil {
+			return fmt.Errorf("kafkasink: encoding entry: %w", err)
+		}
+		msg := kafka.Message{Topic: s.config.Topic, Value: value, Time: entry.Time}
+		if s.config.Key != nil {
+			msg.Key = s.config.Key(entry)
+		}
+		msgs = append(msgs, msg)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.WriteTimeout)
+	defer cancel()
+	if err := s.config.Producer.WriteMessages(ctx, msgs...); err != nil {
+		return fmt.Errorf("kafkasink: producing %d entries failed: %w", len(msgs), err)
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+package kafkasink_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	notifyme "github.com/AmosSParker/NotifyMe"
+	"github.com/AmosSParker/NotifyMe/kafkasink"
+	"github.com/segmentio/kafka-go"
+)
+
+// mockProducer records produced batches and can be made to fail
+type mockProducer struct {
+	mu      sync.Mutex
+	batches [][]kafka.Message
+	err     error
+	closed  bool
+	sent    chan struct{}
+}
+
+func newMockProducer() *mockProducer {
+	return &mockProducer{sent: make(chan struct{}, 100)}
+}
+
+func (p *mockProducer) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	p.mu.Lock()
+	defer func() {
+		p.mu.Unlock()
+		p.sent <- struct{}{}
+	}()
+	if p.err != nil {
+		return p.err
+	}
+	p.batches = append(p.batches, msgs)
+	return nil
+}
+
+func (p *mockProducer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	return nil
+}
+
+// messages returns every message produced so far
+func (p *mockProducer) messages() []kafka.Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var all []kafka.Message
+	for _, batch := range p.batches {
+		all = append(all, batch...)
+	}
+	return all
+}
+
+// waitSent waits for a produce request or fails the test
+func (p *mockProducer) waitSent(t *testing.T) {
+	t.Helper()
+	select {
+	case <-p.sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no produce request")
+	}
+}
+
+func TestSinkMessages(t *testing.T) {
+	at := time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC)
+	entry := notifyme.Entry{
+		Level: notifyme.LevelError, Message: "charge failed", Time: at,
+		Caller: notifyme.Caller{File: "billing.go", Line: 12},
+		Fields: []notifyme.Field{{Key: "user", Value: "ann"}},
+	}
+	userKey := func(e notifyme.Entry) []byte {
+		for _, field := range e.Fields {
+			if field.Key == "user" {
+				return []byte(field.Value.(string))
+			}
+		}
+		return nil
+	}
+	tests := []struct {
+		name   string
+		format notifyme.Format
+		key    func(notifyme.Entry) []byte
+		want   notifyme.Format
+	}{
+		{"default json", notifyme.FormatText, nil, notifyme.FormatJSON},
+		{"json with key", notifyme.FormatJSON, userKey, notifyme.FormatJSON},
+		{"proto", notifyme.FormatProto, userKey, notifyme.FormatProto},
+		{"ecs", notifyme.FormatECS, nil, notifyme.FormatECS},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			producer := newMockProducer()
+			sink, err := kafkasink.New(kafkasink.Config{
+				Topic: "logs", Key: tt.key, Format: tt.format, Producer: producer, FlushInterval: time.Hour,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			sink.Write(entry)
+			if err := sink.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			msgs := producer.messages()
+			if len(msgs) != 1 {
+				t.Fatalf("got %d messages, want 1", len(msgs))
+			}
+			msg := msgs[0]
+			if msg.Topic != "logs" || !msg.Time.Equal(at) {
+				t.Errorf("topic %q time %v, want logs %v", msg.Topic, msg.Time, at)
+			}
+			var wantKey []byte
+			if tt.key != nil {
+				wantKey = []byte("ann")
+			}
+			if !bytes.Equal(msg.Key, wantKey) {
+				t.Errorf("key = %q, want %q", msg.Key, wantKey)
+			}
+			want, _ := notifyme.EncodeEntry(entry, tt.want)
+			if !bytes.Equal(msg.Value, want) {
+				t.Errorf("payload = %q, want %q", msg.Value, want)
+			}
+			if tt.want == notifyme.FormatJSON && !json.Valid(msg.Value) {
+				t.Errorf("payload %q is not JSON", msg.Value)
+			}
+			if !producer.closed {
+				t.Error("Close did not close the producer")
+			}
+		})
+	}
+}
+
+func TestSinkThroughLogger(t *testing.T) {
+	producer := newMockProducer()
+	sink, err := kafkasink.New(kafkasink.Config{Topic: "logs", Format: notifyme.FormatProto, Producer: producer, BatchSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := notifyme.NewWriterLogger(notifyme.LevelInfo, &bytes.Buffer{})
+	logger.AddSink(sink)
+	logger.Log(notifyme.LevelInfo, "first")
+	logger.Log(notifyme.LevelWarn, "second")
+	producer.waitSent(t)
+	logger.Close()
+
+	msgs := producer.messages()
+	if len(msgs) != 2 {
+		t.Fatalf("got %d messages, want 2", len(msgs))
+	}
+	for i, want := range []string{"first", "second"} {
+		decoded, err := notifyme.NewProtoReader(bytes.NewReader(msgs[i].Value)).Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Message != want {
+			t.Errorf("message %d = %q, want %q", i, decoded.Message, want)
+		}
+	}
+}
+
+func TestSinkBatching(t *testing.T) {
+	producer := newMockProducer()
+	sink, err := kafkasink.New(kafkasink.Config{Topic: "logs", Producer: producer, BatchSize: 3, FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	for i := 0; i < 2; i++ {
+		sink.Write(notifyme.Entry{Message: "queued"})
+	}
+	select {
+	case <-producer.sent:
+		t.Fatal("flushed before the batch was full")
+	case <-time.After(20 * time.Millisecond):
+	}
+	sink.Write(notifyme.Entry{Message: "full"})
+	producer.waitSent(t)
+	if got := len(producer.messages()); got != 3 {
+		t.Errorf("first batch had %d messages, want 3", got)
+	}
+}
+
+func TestSinkFlushInterval(t *testing.T) {
+	producer := newMockProducer()
+	sink, err := kafkasink.New(kafkasink.Config{Topic: "logs", Producer: producer, FlushInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	sink.Write(notifyme.Entry{Message: "lonely"})
+	producer.waitSent(t)
+	if got := len(producer.messages()); got != 1 {
+		t.Errorf("got %d messages, want 1", got)
+	}
+}
+
+func TestSinkBackpressure(t *testing.T) {
+	producer := newMockProducer()
+	sink, err := kafkasink.New(kafkasink.Config{
+		Topic: "logs", Producer: producer, MaxPending: 2, BatchSize: 10, FlushInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := sink.Write(notifyme.Entry{Message: "burst"}); err != nil {
+			t.Fatalf("Write = %v, want nil even when full", err)
+		}
+	}
+	if got := sink.Dropped(); got != 3 {
+		t.Errorf("Dropped = %d, want 3", got)
+	}
+	sink.Close()
+	if got := len(producer.messages()); got != 2 {
+		t.Errorf("produced %d messages, want 2", got)
+	}
+}
+
+func TestSinkProducerErrors(t *testing.T) {
+	producer := newMockProducer()
+	producer.err = errors.New("broker unavailable")
+	errs := make(chan error, 1)
+	sink, err := kafkasink.New(kafkasink.Config{
+		Topic: "logs", Producer: producer, BatchSize: 1,
+		OnError: func(err error) { errs <- err },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink.Write(notifyme.Entry{Message: "lost"})
+	select {
+	case err := <-errs:
+		if !errors.Is(err, producer.err) {
+			t.Errorf("OnError got %v, want the producer error", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("producer error was not reported")
+	}
+	sink.Close()
+}
+
+func TestSinkCloseReportsError(t *testing.T) {
+	producer := newMockProducer()
+	producer.err = errors.New("broker unavailable")
+	sink, err := kafkasink.New(kafkasink.Config{Topic: "logs", Producer: producer, FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink.Write(notifyme.Entry{Message: "lost"})
+	if err := sink.Close(); !errors.Is(err, producer.err) {
+		t.Errorf("Close = %v, want the producer error", err)
+	}
+	if !producer.closed {
+		t.Error("Close did not close the producer after a failed flush")
+	}
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config kafkasink.Config
+	}{
+		{"no topic", kafkasink.Config{Brokers: []string{"localhost:9092"}}},
+		{"no brokers", kafkasink.Config{Topic: "logs"}},
+		{"unknown format", kafkasink.Config{Topic: "logs", Producer: newMockProducer(), Format: notifyme.Format(99)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := kafkasink.New(tt.config); err == nil {
+				t.Error("New accepted the config")
+			}
+		})
+	}
+}
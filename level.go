@@ -0,0 +1,85 @@
+package notifyme
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// envLevelStrings holds the custom level names accepted by InitFromEnv,
+// keyed in upper case
+var envLevelStrings atomic.Pointer[map[string]int]
+
+// ParseLevel returns the level named by s, one of INFO, WARN, ERROR or
+// CRITICAL. Case and surrounding spaces are ignored.
+func ParseLevel(s string) (int, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "INFO":
+		return LevelInfo, nil
+	case "WARN":
+		return LevelWarn, nil
+	case "ERROR":
+		return LevelError, nil
+	case "CRITICAL":
+		return LevelCritical, nil
+	default:
+		return 0, fmt.Errorf("notifyme: unknown log level %q", s)
+	}
+}
+
+// LevelName returns the name of the given level as accepted by ParseLevel,
+// or "LEVEL(n)" for levels without a name
+func LevelName(level int) string {
+	return levelName(level)
+}
+
+// ParseLevelOrDefault is like ParseLevel but returns def for empty or
+// unknown input. Unknown input is reported as a WARN through the global
+// logger, if one is initialized, so a typo in a config file does not go
+// unnoticed.
+func ParseLevelOrDefault(s string, def int) int {
+	if strings.TrimSpace(s) == "" {
+		return def
+	}
+	level, err := ParseLevel(s)
+	if err != nil {
+		if logger := globalLogger.Load(); logger != nil {
+			logger.logDepth(2, LevelWarn, fmt.Sprintf("Unknown log level %q, using %s", s, levelName(def)))
+		}
+		return def
+	}
+	return level
+}
+
+// SetEnvLevelStrings registers extra LOG_LEVEL values for InitFromEnv, such
+// as {"verbose": LevelInfo, "quiet": LevelError}, for deployments with
+// their own conventions. Names are matched ignoring case and surrounding
+// spaces and take precedence over the built-in names. Each call replaces
+// the previous mapping; nil removes it. A mapping to an unknown level is
+// rejected and the previous mapping kept.
+func SetEnvLevelStrings(mapping map[string]int) error {
+	if mapping == nil {
+		envLevelStrings.Store(nil)
+		return nil
+	}
+	levels := make(map[string]int, len(mapping))
+	for name, level := range mapping {
+		if !knownLevel(level) {
+			return fmt.Errorf("notifyme: unknown log level %d for LOG_LEVEL value %q", level, name)
+		}
+		levels[strings.ToUpper(strings.TrimSpace(name))] = level
+	}
+	envLevelStrings.Store(&levels)
+	return nil
+}
+
+// parseEnvLevel is ParseLevel with the names registered by
+// SetEnvLevelStrings
+func parseEnvLevel(s string) (int, error) {
+	if levels := envLevelStrings.Load(); levels != nil {
+		if level, ok := (*levels)[strings.ToUpper(strings.TrimSpace(s))]; ok {
+			return level, nil
+		}
+	}
+	return ParseLevel(s)
+}
@@ -0,0 +1,130 @@
+package notifyme
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseLevelOrDefault(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		def   int
+		want  int
+		warns bool
+	}{
+		{"info", "INFO", LevelError, LevelInfo, false},
+		{"lower case", "warn", LevelInfo, LevelWarn, false},
+		{"surrounding spaces", "  critical\n", LevelInfo, LevelCritical, false},
+		{"error", "Error", LevelInfo, LevelError, false},
+		{"empty", "", LevelWarn, LevelWarn, false},
+		{"blank", " \t", LevelError, LevelError, false},
+		{"garbage", "loud", LevelWarn, LevelWarn, true},
+		{"numeric", "3", LevelInfo, LevelInfo, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateGlobalLogger(t)
+			var buf bytes.Buffer
+			globalLogger.Store(newWriterLogger(LevelInfo, &buf))
+
+			if got := ParseLevelOrDefault(tt.input, tt.def); got != tt.want {
+				t.Errorf("ParseLevelOrDefault(%q, %d) = %d, want %d", tt.input, tt.def, got, tt.want)
+			}
+			out := buf.String()
+			if !tt.warns {
+				if out != "" {
+					t.Errorf("unexpected output %q", out)
+				}
+				return
+			}
+			if !strings.Contains(out, "[WARN]") || !strings.Contains(out, `"`+tt.input+`"`) || !strings.Contains(out, levelName(tt.def)) {
+				t.Errorf("warning %q does not name the input and the default", out)
+			}
+			if !strings.Contains(out, "level_test.go:") {
+				t.Errorf("warning %q is not attributed to the caller", out)
+			}
+		})
+	}
+}
+
+func TestParseLevelOrDefaultWithoutGlobalLogger(t *testing.T) {
+	isolateGlobalLogger(t)
+	if got := ParseLevelOrDefault("garbage", LevelError); got != LevelError {
+		t.Errorf("ParseLevelOrDefault = %d, want %d", got, LevelError)
+	}
+	if globalLogger.Load() != nil {
+		t.Error("ParseLevelOrDefault initialized the global logger")
+	}
+}
+
+func TestInitFromEnvWithEnvLevelStrings(t *testing.T) {
+	mapping := map[string]int{"verbose": LevelInfo, "quiet": LevelError, " Pager ": LevelCritical, "warn": LevelCritical}
+	tests := []struct {
+		name    string
+		mapping map[string]int
+		value   string
+		want    int
+	}{
+		{"custom name", mapping, "verbose", LevelInfo},
+		{"custom name ignoring case", mapping, "QUIET", LevelError},
+		{"custom name with spaces", mapping, " pager\n", LevelCritical},
+		{"custom name overrides built-in", mapping, "WARN", LevelCritical},
+		{"built-in name still works", mapping, "info", LevelInfo},
+		{"unknown value", mapping, "chatty", LevelError},
+		{"without a mapping", nil, "verbose", LevelError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateGlobalLogger(t)
+			logger := newWriterLogger(LevelWarn, &bytes.Buffer{})
+			globalLogger.Store(logger)
+			if err := SetEnvLevelStrings(tt.mapping); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { SetEnvLevelStrings(nil) })
+			t.Setenv("LOG_LEVEL", tt.value)
+
+			InitFromEnv()
+			if got := logger.effectiveLevel(); got != tt.want {
+				t.Errorf("LOG_LEVEL=%q set %s, want %s", tt.value, levelName(got), levelName(tt.want))
+			}
+		})
+	}
+}
+
+func TestSetEnvLevelStringsReplaces(t *testing.T) {
+	t.Cleanup(func() { SetEnvLevelStrings(nil) })
+	for _, mapping := range []map[string]int{{"verbose": LevelInfo}, {"loud": LevelInfo}} {
+		if err := SetEnvLevelStrings(mapping); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for input, want := range map[string]bool{"loud": true, "verbose": false} {
+		if _, err := parseEnvLevel(input); (err == nil) != want {
+			t.Errorf("parseEnvLevel(%q) error = %v, want known = %v", input, err, want)
+		}
+	}
+	SetEnvLevelStrings(nil)
+	if _, err := parseEnvLevel("loud"); err == nil {
+		t.Error("mapping still used after SetEnvLevelStrings(nil)")
+	}
+}
+
+func TestSetEnvLevelStringsInvalid(t *testing.T) {
+	t.Cleanup(func() { SetEnvLevelStrings(nil) })
+	if err := SetEnvLevelStrings(map[string]int{"verbose": LevelInfo}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetEnvLevelStrings(map[string]int{"loud": LevelInfo, "debug": -1}); err == nil {
+		t.Fatal("SetEnvLevelStrings accepted an unknown level")
+	}
+	if level, err := parseEnvLevel("verbose"); err != nil || level != LevelInfo {
+		t.Errorf("parseEnvLevel(\"verbose\") = %d, %v, want the previous mapping kept", level, err)
+	}
+	if _, err := parseEnvLevel("loud"); err == nil {
+		t.Error("the rejected mapping was partly applied")
+	}
+}
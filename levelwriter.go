@@ -0,0 +1,109 @@
+package notifyme
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+)
+
+// maxDetectedLineLength is the longest line LevelDetectingWriter buffers;
+// longer lines are logged in pieces of this size
+const maxDetectedLineLength = 64 << 10
+
+// LevelDetectingWriter is an io.Writer that logs each written line at the
+// level named by its leading token, such as "ERROR: disk full" or
+// "[WARN] slow query". Lines without a recognized token are logged at the
+// default level. It is meant for capturing the output of a child process:
+//
+//	cmd.Stderr = notifyme.NewLevelDetectingWriter(logger, notifyme.LevelError)
+type LevelDetectingWriter struct {
+	mu           sync.Mutex
+	logger       *Logger
+	defaultLevel int
+	buf          []byte
+}
+
+// NewLevelDetectingWriter returns a writer logging lines to logger
+func NewLevelDetectingWriter(logger *Logger, defaultLevel int) *LevelDetectingWriter {
+	return &LevelDetectingWriter{logger: logger, defaultLevel: defaultLevel}
+}
+
+// Write logs every complete line in p. A trailing partial line is kept
+// until the rest of it is written or Close is called, but once it reaches
+// 64 KiB it is logged as it is and the rest starts a new line.
+func (w *LevelDetectingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	rest := w.buf
+	for {
+		i := bytes.IndexByte(rest, '\n')
+		if i > maxDetectedLineLength || i < 0 && len(rest) >= maxDetectedLineLength {
+			w.logLine(string(rest[:maxDetectedLineLength]))
+			rest = rest[maxDetectedLineLength:]
+			continue
+		}
+		if i < 0 {
+			break
+		}
+		w.logLine(string(rest[:i]))
+		rest = rest[i+1:]
+	}
+	w.buf = append(w.buf[:0], rest...)
+	return len(p), nil
+}
+
+// Close logs any buffered partial line
+func (w *LevelDetectingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) > 0 {
+		w.logLine(string(w.buf))
+		w.buf = nil
+	}
+	return nil
+}
+
+// logLine logs a single line without its level token. Empty lines are
+// skipped.
+func (w *LevelDetectingWriter) logLine(line string) {
+	line = strings.TrimRight(line, "\r")
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	level, message, ok := detectLevel(line)
+	if !ok {
+		level, message = w.defaultLevel, line
+	}
+	w.logger.logDepth(3, level, message)
+}
+
+// detectLevel parses a leading level token, optionally in brackets and
+// followed by a colon, and returns the level and the rest of the line
+func detectLevel(line string) (int, string, bool) {
+	rest := strings.TrimLeft(line, " \t")
+	bracketed := strings.HasPrefix(rest, "[")
+	if bracketed {
+		rest = rest[1:]
+	}
+	end := 0
+	for end < len(rest) && (rest[end] >= 'A' && rest[end] <= 'Z' || rest[end] >= 'a' && rest[end] <= 'z') {
+		end++
+	}
+	level, err := ParseLevel(rest[:end])
+	if err != nil {
+		return 0, "", false
+	}
+	rest = rest[end:]
+	switch {
+	case bracketed && strings.HasPrefix(rest, "]"):
+		rest = rest[1:]
+	case bracketed:
+		return 0, "", false
+	case strings.HasPrefix(rest, ":"):
+		rest = rest[1:]
+	case rest != "" && rest[0] != ' ' && rest[0] != '\t':
+		return 0, "", false
+	}
+	return level, strings.TrimLeft(rest, " \t"), true
+}
@@ -0,0 +1,127 @@
+package notifyme
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLevelDetectingWriter(t *testing.T) {
+	tests := []struct {
+		line    string
+		level   int
+		message string
+	}{
+		{"ERROR: disk full", LevelError, "disk full"},
+		{"[WARN] slow query", LevelWarn, "slow query"},
+		{"info starting up", LevelInfo, "starting up"},
+		{"  Critical:out of memory", LevelCritical, "out of memory"},
+		{"[Error]  bad config", LevelError, "bad config"},
+		{"plain output", LevelWarn, "plain output"},
+		{"ERRORS everywhere", LevelWarn, "ERRORS everywhere"},
+		{"[WARN slow", LevelWarn, "[WARN slow"},
+		{"WARNING: deprecated", LevelWarn, "WARNING: deprecated"},
+		{"ERROR", LevelError, ""},
+	}
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	ring, _ := NewRingSink(len(tests))
+	logger.AddSink(ring)
+	w := NewLevelDetectingWriter(logger, LevelWarn)
+
+	var input bytes.Buffer
+	for _, tt := range tests {
+		input.WriteString(tt.line + "\r\n\n")
+	}
+	if n, err := w.Write(input.Bytes()); err != nil || n != input.Len() {
+		t.Fatalf("Write = %d, %v, want %d, nil", n, err, input.Len())
+	}
+
+	entries := ring.Entries()
+	if len(entries) != len(tests) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			if entries[i].Level != tt.level || entries[i].Message != tt.message {
+				t.Errorf("got level %s message %q, want %s %q",
+					levelName(entries[i].Level), entries[i].Message, levelName(tt.level), tt.message)
+			}
+		})
+	}
+}
+
+func TestLevelDetectingWriterPartialLines(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	ring, _ := NewRingSink(4)
+	logger.AddSink(ring)
+	w := NewLevelDetectingWriter(logger, LevelInfo)
+
+	for _, chunk := range []string{"ERR", "OR: split ", "line\nWARN: tail"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := len(ring.Entries()); got != 1 {
+		t.Fatalf("got %d entries before Close, want 1", got)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	entries := ring.Entries()
+	want := []struct {
+		level   int
+		message string
+	}{{LevelError, "split line"}, {LevelWarn, "tail"}}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i, w := range want {
+		if entries[i].Level != w.level || entries[i].Message != w.message {
+			t.Errorf("entry %d: got %s %q, want %s %q", i, levelName(entries[i].Level), entries[i].Message, levelName(w.level), w.message)
+		}
+	}
+}
+
+func TestLevelDetectingWriterLongLine(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	ring, _ := NewRingSink(4)
+	logger.AddSink(ring)
+	w := NewLevelDetectingWriter(logger, LevelInfo)
+
+	long := "ERROR: " + strings.Repeat("x", maxDetectedLineLength)
+	for _, chunk := range []string{long[:100], long[100:]} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries := ring.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries before the newline, want the line cut at the cap", len(entries))
+	}
+	if entries[0].Level != LevelError || entries[0].Message != long[len("ERROR: "):maxDetectedLineLength] {
+		t.Errorf("got %s entry of %d bytes, want ERROR with the first %d bytes", levelName(entries[0].Level), len(entries[0].Message), maxDetectedLineLength)
+	}
+	if len(w.buf) != len(long)-maxDetectedLineLength {
+		t.Errorf("buffered %d bytes, want the %d after the cap", len(w.buf), len(long)-maxDetectedLineLength)
+	}
+
+	if _, err := w.Write([]byte("\n")); err != nil {
+		t.Fatal(err)
+	}
+	if entries := ring.Entries(); len(entries) != 2 || entries[1].Message != strings.Repeat("x", len("ERROR: ")) {
+		t.Errorf("got %d entries, want the rest of the line logged at the newline", len(entries))
+	}
+}
+
+func TestLevelDetectingWriterRespectsLevel(t *testing.T) {
+	logger := newWriterLogger(LevelError, &bytes.Buffer{})
+	ring, _ := NewRingSink(2)
+	logger.AddSink(ring)
+	w := NewLevelDetectingWriter(logger, LevelInfo)
+	w.Write([]byte("WARN: dropped\nCRITICAL: kept\nno token\n"))
+
+	entries := ring.Entries()
+	if len(entries) != 1 || entries[0].Message != "kept" {
+		t.Errorf("entries = %+v, want only the CRITICAL line", entries)
+	}
+}
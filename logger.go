@@ -3,9 +3,13 @@ package notifyme
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Logger struct holds different loggers for various log levels
@@ -14,14 +18,49 @@ type Logger struct {
 	warnLogger     *log.Logger
 	errorLogger    *log.Logger
 	criticalLogger *log.Logger
-	level          int
+	level          atomic.Int32 // read without the mutex to filter entries cheaply
+	writerLevel    int
+	name           string
+	component      atomic.Pointer[resolvedLevel] // component override cache of effectiveLevel
+	ctxLevel       int                           // set by WithContext if ctxLevelSet
+	ctxLevelSet    bool
+	fields         []Field
+	opts           loggerOptions
+	sampler        *keySampler
+	limiter        *rateLimiter
+	sinks          []attachedSink
+	routes         []fieldRoute
+	processors     []func(*Entry)
+	levelFiles     []levelFile
+	output         *reopenableFile // the main log file, closed by Close
+	sinkPool       *sinkPoolRef
+	syncErrs       *[]error
+	sinkTimeouts   atomic.Int64
+	lastError      atomic.Pointer[LoggerError]
+	everyLast      *lastSeen
+	stackSeen      *lastSeen
+	alertSeen      *lastSeen
+	reorder        *reorderBuffer
+	seq            *sequence
+	heartbeat      *heartbeat
+	noLock         atomic.Bool
+	levelCallbacks []func(old, new int)
+	now            func() time.Time
 	mu             sync.Mutex // Added mutex for thread safety
 }
 
-// Global logger instance
-var globalLogger *Logger
+// Global logger instance, stored atomically so it can be read by Notify
+// while another goroutine replaces it
+var globalLogger atomic.Pointer[Logger]
 var once sync.Once // Ensure singleton pattern for global logger
 
+// globalInitialized is set once the global logger was explicitly created,
+// as opposed to lazily defaulted by GetGlobalLogger
+var globalInitialized atomic.Bool
+
+// strictGlobal disables the lazy default of GetGlobalLogger
+var strictGlobal atomic.Bool
+
 // Log levels constants
 const (
 	LevelInfo = iota
@@ -31,130 +70,523 @@ const (
 )
 
 // newLoggerInstance initializes and returns a new Logger instance
-func newLoggerInstance(level int, output ...string) *Logger {
+func newLoggerInstance(level int, output ...string) (*Logger, error) {
 	// Default to stdout if no output file is specified
-	var logOutput *os.File
-	if len(output) > 0 {
-		var err error
-		logOutput, err = os.OpenFile(output[0], os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
-		if err != nil {
-			log.Fatalf("Failed to open log file: %v", err)
-		}
-	} else {
-		logOutput = os.Stdout
+	if len(output) == 0 {
+		return newWriterLogger(level, os.Stdout), nil
+	}
+	file, err := openReopenableFile(output[0])
+	if err != nil {
+		return nil, err
 	}
+	logger := newWriterLogger(level, file)
+	logger.output = file
+	return logger, nil
+}
 
+// newWriterLogger creates a logger writing every level to w
+func newWriterLogger(level int, logOutput io.Writer) *Logger {
 	// Initialize loggers for each level
-	return &Logger{
-		infoLogger:     log.New(logOutput, "INFO: ", log.Ldate|log.Ltime|log.Lshortfile),
-		warnLogger:     log.New(logOutput, "WARN: ", log.Ldate|log.Ltime|log.Lshortfile),
-		errorLogger:    log.New(logOutput, "ERROR: ", log.Ldate|log.Ltime|log.Lshortfile),
-		criticalLogger: log.New(logOutput, "CRITICAL: ", log.Ldate|log.Ltime|log.Lshortfile),
-		level:          level,
+	logger := &Logger{
+		infoLogger:     log.New(logOutput, "INFO: ", 0),
+		warnLogger:     log.New(logOutput, "WARN: ", 0),
+		errorLogger:    log.New(logOutput, "ERROR: ", 0),
+		criticalLogger: log.New(logOutput, "CRITICAL: ", 0),
+		everyLast:      newLastSeen(0),
+		stackSeen:      newLastSeen(maxStackRefs),
+		alertSeen:      newLastSeen(maxAlertFingerprints),
+		now:            time.Now,
 	}
+	logger.level.Store(int32(level))
+	return logger
 }
 
 // InitializeGlobalLogger creates and initializes the global logger instance
 func InitializeGlobalLogger(level int, output ...string) {
 	once.Do(func() {
-		globalLogger = newLoggerInstance(level, output...)
+		logger := mustLoggerInstance(level, output...)
+		globalLogger.Store(logger)
+		globalInitialized.Store(true)
+		replayEarly(logger)
 	})
 }
 
-// GetGlobalLogger returns the global logger instance
+// ReinitializeGlobalLogger replaces the global logger with a new instance,
+// even if one was already initialized, and returns the previous one. Calls
+// already in flight may still use the previous logger, so close it only
+// once they are done.
+func ReinitializeGlobalLogger(level int, output ...string) *Logger {
+	logger := mustLoggerInstance(level, output...)
+	once.Do(func() {})
+	previous := globalLogger.Swap(logger)
+	globalInitialized.Store(true)
+	replayEarly(logger)
+	return previous
+}
+
+// GetGlobalLogger returns the global logger instance. If none was
+// initialized it installs and returns a default INFO logger writing to
+// stdout, which a later InitializeGlobalLogger still replaces; in strict
+// mode it returns nil instead.
 func GetGlobalLogger() *Logger {
-	return globalLogger
+	if logger := globalLogger.Load(); logger != nil || strictGlobal.Load() {
+		return logger
+	}
+	globalLogger.CompareAndSwap(nil, mustLoggerInstance(LevelInfo))
+	return globalLogger.Load()
 }
 
-// NewLogger creates and returns a new Logger instance
+// IsGlobalLoggerInitialized reports whether InitializeGlobalLogger or
+// ReinitializeGlobalLogger has been called. The default installed by
+// GetGlobalLogger does not count.
+func IsGlobalLoggerInitialized() bool {
+	return globalInitialized.Load()
+}
+
+// SetStrictGlobalLogger turns strict mode on or off. In strict mode
+// GetGlobalLogger and Notify do not fall back to a default logger, so
+// GetGlobalLogger returns nil and Notify does nothing until the global
+// logger is initialized.
+func SetStrictGlobalLogger(strict bool) {
+	strictGlobal.Store(strict)
+}
+
+// NewLogger creates and returns a new Logger instance. It exits the process
+// if the output file cannot be opened; use NewLoggerE to handle the error.
 func NewLogger(level int, output ...string) *Logger {
+	return mustLoggerInstance(level, output...)
+}
+
+// NewLoggerE creates and returns a new Logger instance, returning an error
+// if the output file cannot be opened. Prefer it in library code.
+func NewLoggerE(level int, output ...string) (*Logger, error) {
 	return newLoggerInstance(level, output...)
 }
 
+// MustNewLogger is like NewLoggerE but panics if the logger cannot be
+// created. It is intended for main and init functions where failing fast
+// on a bad configuration is acceptable.
+func MustNewLogger(level int, output ...string) *Logger {
+	logger, err := newLoggerInstance(level, output...)
+	if err != nil {
+		panic(fmt.Sprintf("notifyme: MustNewLogger(%d, %q): %v", level, output, err))
+	}
+	return logger
+}
+
+// NewWriterLogger creates a logger writing every level to w instead of a
+// file. Close does not close w.
+func NewWriterLogger(level int, w io.Writer) *Logger {
+	return newWriterLogger(level, w)
+}
+
+// mustLoggerInstance creates a logger, exiting the process on failure
+func mustLoggerInstance(level int, output ...string) *Logger {
+	logger, err := newLoggerInstance(level, output...)
+	if err != nil {
+		log.Fatalf("Failed to open log file: %v", err)
+	}
+	return logger
+}
+
+// Clone returns a copy of the logger with the same level, prefixes and flags.
+// The copy has its own mutex and configuration, so changing one does not
+// affect the other, but both keep writing to the same underlying output.
+// Sinks, level files, the sequence counter and the sink delivery pool are
+// shared by reference and stay owned by the original: closing the copy
+// leaves them open for the original and its other copies, and a pool the
+// original replaces is used by the copy as well. Sinks added to either
+// logger afterwards are not seen by the other and are closed by the logger
+// they were added to. The copy also shares the sampler, rate limits,
+// LogEvery call sites, the stacks and alerts seen by the deduplication
+// options and the backpressure state of the pool, so both draw on the same
+// budgets and suppress repeats together.
+//
+// State kept while logging is not carried over, so the copy runs no
+// heartbeat, holds none of the entries waiting for WithReorderWindow, and
+// starts with its own sink timeout count and last error.
+func (l *Logger) Clone() *Logger {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	clone := &Logger{
+		infoLogger:     cloneStdLogger(l.infoLogger),
+		warnLogger:     cloneStdLogger(l.warnLogger),
+		errorLogger:    cloneStdLogger(l.errorLogger),
+		criticalLogger: cloneStdLogger(l.criticalLogger),
+		writerLevel:    l.writerLevel,
+		name:           l.name,
+		ctxLevel:       l.ctxLevel,
+		ctxLevelSet:    l.ctxLevelSet,
+		fields:         append([]Field(nil), l.fields...),
+		opts:           l.opts,
+		sinks:          append([]attachedSink(nil), l.sinks...),
+		routes:         append([]fieldRoute(nil), l.routes...),
+		processors:     append(([]func(*Entry))(nil), l.processors...),
+		levelFiles:     l.levelFiles,
+		levelCallbacks: l.levelCallbacks,
+		sampler:        l.sampler,
+		limiter:        l.limiter,
+		sinkPool:       l.sinkPool,
+		seq:            l.seq,
+		everyLast:      l.everyLast,
+		stackSeen:      l.stackSeen,
+		alertSeen:      l.alertSeen,
+		now:            l.now,
+	}
+	clone.level.Store(l.level.Load())
+	clone.noLock.Store(l.noLock.Load())
+	return clone
+}
+
+// cloneStdLogger creates a new log.Logger sharing the writer of the given one
+func cloneStdLogger(logger *log.Logger) *log.Logger {
+	return log.New(logger.Writer(), logger.Prefix(), logger.Flags())
+}
+
 // SetLevel sets the global log level
 func SetLevel(level int) {
-	if globalLogger != nil {
-		globalLogger.mu.Lock()
-		defer globalLogger.mu.Unlock()
-		globalLogger.level = level
+	if logger := globalLogger.Load(); logger != nil {
+		logger.SetLevel(level)
 	}
 }
 
-// Log logs a message with the given log level
-func (l *Logger) Log(level int, message string, optionalParams ...interface{}) {
+// SetLevel sets the log level of this logger
+func (l *Logger) SetLevel(level int) {
+	old := int(l.level.Swap(int32(level)))
+	if old == level {
+		return
+	}
+	l.mu.Lock()
+	callbacks := l.levelCallbacks
+	l.mu.Unlock()
+	for _, fn := range callbacks {
+		fn(old, level)
+	}
+}
+
+// OnLevelChange registers fn to be called with the old and new level
+// whenever SetLevel changes the level of this logger. Callbacks run
+// outside the logger mutex, so they may log. Clones keep the callbacks
+// registered before they were made.
+func (l *Logger) OnLevelChange(fn func(old, new int)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.levelCallbacks = append(l.levelCallbacks[:len(l.levelCallbacks):len(l.levelCallbacks)], fn)
+}
+
+// SetWriterLevel sets the minimum level written to the primary output. It
+// is applied after the logger's own level, so with the logger at INFO and
+// the writer at ERROR, sinks receive everything while the file or stdout
+// only gets ERROR and CRITICAL.
+func (l *Logger) SetWriterLevel(level int) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
-	fullMessage := message
+	l.writerLevel = level
+}
+
+// Log logs a message with the given log level
+func (l *Logger) Log(level int, message string, optionalParams ...interface{}) {
+	l.logDepth(2, level, message, optionalParams...)
+}
+
+// LogAt logs a message for an event that happened at t, such as one read
+// from a queue or backfilled. The entry keeps the current time as its log
+// time and carries t as its event time ("event_ts").
+func (l *Logger) LogAt(t time.Time, level int, message string, optionalParams ...interface{}) {
+	l.logAtDepth(2, t, nil, level, message, optionalParams...)
+}
+
+// logDepth logs a message, reporting the caller depth frames above it as
+// the source location
+func (l *Logger) logDepth(depth int, level int, message string, optionalParams ...interface{}) {
+	l.logAtDepth(depth+1, time.Time{}, nil, level, message, optionalParams...)
+}
+
+// logAtDepth is logDepth for entries with an optional event time and
+// fields of their own, which are set after the logger's fields
+func (l *Logger) logAtDepth(depth int, eventTime time.Time, fields []Field, level int, message string, optionalParams ...interface{}) {
+	l.logEntry(depth+1, nil, eventTime, fields, level, message, optionalParams...)
+}
+
+// logEntry is logAtDepth that, given a non-nil wait, delivers to the sinks
+// inline and collects their errors into it
+func (l *Logger) logEntry(depth int, wait *[]error, eventTime time.Time, fields []Field, level int, message string, optionalParams ...interface{}) {
+	// Filtered entries return before taking the mutex or looking up the
+	// caller; unknown levels go on to be reported below
+	if knownLevel(level) && l.effectiveLevel() > level {
+		return
+	}
+	caller := callerAt(depth)
+	defer l.unlockWrite(l.lockWrite())
+	if wait != nil {
+		l.syncErrs = wait
+		defer func() { l.syncErrs = nil }()
+	}
+	if !l.opts.callerFunction {
+		caller.Function = ""
+	}
+	if _, ok := l.levelLogger(level); !ok {
+		l.writeEntry(l.newEntry(LevelError, fmt.Sprintf("Unknown log level: %d", level), caller, nil))
+		return
+	}
+	fullMessage := l.translateMessage(message, fields)
 	for _, param := range optionalParams {
-		fullMessage += fmt.Sprintf(" %v", param)
+		fullMessage += " " + l.formatValue(param)
+	}
+	entry := l.newEntry(level, fullMessage, caller, fields)
+	entry.EventTime = eventTime
+	if l.opts.stackTrace && level >= l.opts.stackLevel {
+		l.addStack(&entry, depth)
+	}
+	if l.sampled(level) {
+		allowed, timesSeen := l.sampler.allow(entry)
+		if !allowed {
+			return
+		}
+		entry.TimesSeen = timesSeen
+	}
+	if l.limiter != nil {
+		allowed, dropped := l.limiter.allow(entry)
+		if !allowed {
+			return
+		}
+		entry.TimesSeen += dropped
+	}
+	l.writeEntry(entry)
+}
+
+// WriteEntry writes an already built entry, such as one received from
+// another process or decoded with ProtoReader, to the primary output,
+// field routes and sinks. Its time, caller, name and fields are kept as
+// they are, except that fields repeating a key are merged with the last
+// value winning; only the logger's level filter is applied.
+func (l *Logger) WriteEntry(entry Entry) {
+	if l.effectiveLevel() > entry.Level {
+		return
 	}
+	entry.Fields = dedupeFields(entry.Fields)
+	defer l.unlockWrite(l.lockWrite())
+	if l.opts.reorderWindow > 0 {
+		l.holdEntry(entry)
+		return
+	}
+	l.writeEntry(entry)
+}
+
+// newEntry builds an entry carrying the logger's name and fields, followed
+// by the given ones. It must be called with the logger mutex held.
+func (l *Logger) newEntry(level int, message string, caller Caller, fields []Field) Entry {
+	entry := Entry{
+		Level:     level,
+		Time:      l.currentTime(),
+		Caller:    l.trimCaller(caller),
+		Severity:  l.severity(level),
+		Name:      l.name,
+		TimesSeen: 1,
+	}
+	if n := len(l.fields) + len(fields); n > 0 || l.opts.fieldsCapacity > 0 {
+		if n < l.opts.fieldsCapacity {
+			n = l.opts.fieldsCapacity
+		}
+		entry.Fields = append(make([]Field, 0, n), l.fields...)
+	}
+	for _, field := range fields {
+		entry.Fields = setField(entry.Fields, field.Key, field.Value)
+	}
+	l.capFields(&entry)
+	l.addMessageID(&entry)
+	if len(l.opts.redactPatterns) > 0 {
+		l.redactFields(&entry)
+		message = l.redact(message)
+	}
+	entry.Message = l.truncateMessage(&entry, message)
+	return entry
+}
+
+// writeEntry writes the entry to the primary output, level files, matching
+// field routes and all sinks. It must be called with the logger mutex held.
+func (l *Logger) writeEntry(entry Entry) {
+	l.process(&entry)
+	if !l.validateEntry(&entry) {
+		return
+	}
+	l.numberEntry(&entry)
+	if entry.Level >= l.writerLevel {
+		l.writePrimary(entry)
+	}
+	l.writeLevelFiles(entry)
+	l.writeRoutes(entry)
+	l.writeSinks(entry)
+	if l.flushesAt(entry.Level) {
+		l.flush()
+	}
+}
+
+// writePrimary writes the entry to the primary output. It must be called
+// with the logger mutex held.
+func (l *Logger) writePrimary(entry Entry) {
+	logger, ok := l.levelLogger(entry.Level)
+	if !ok {
+		logger = l.errorLogger
+	}
+	if l.opts.writeDeadline > 0 {
+		l.writePrimaryWithDeadline(logger, entry)
+		return
+	}
+	if l.opts.format != FormatText {
+		l.writeEncoded(logger.Writer(), entry)
+		return
+	}
+	text := l.formatText(entry)
+	if err := logger.Output(0, text); err != nil {
+		l.writeFallback(logger.Prefix()+text+"\n", err)
+	}
+}
+
+// writeTo renders the entry like the primary output, level prefix
+// included, and writes it to w. It must be called with the logger mutex
+// held.
+func (l *Logger) writeTo(w io.Writer, entry Entry) {
+	if l.opts.format != FormatText {
+		l.writeEncoded(w, entry)
+		return
+	}
+	logger, ok := l.levelLogger(entry.Level)
+	if !ok {
+		logger = l.errorLogger
+	}
+	line := logger.Prefix() + l.formatText(entry) + "\n"
+	if _, err := io.WriteString(w, line); err != nil {
+		l.writeFallback(line, err)
+	}
+}
+
+// levelLogger returns the logger used for the given level
+func (l *Logger) levelLogger(level int) (*log.Logger, bool) {
 	switch level {
 	case LevelInfo:
-		if l.level <= LevelInfo {
-			logMessage(l.infoLogger, "INFO", fullMessage)
-		}
+		return l.infoLogger, true
 	case LevelWarn:
-		if l.level <= LevelWarn {
-			logMessage(l.warnLogger, "WARN", fullMessage)
-		}
+		return l.warnLogger, true
 	case LevelError:
-		if l.level <= LevelError {
-			logMessage(l.errorLogger, "ERROR", fullMessage)
-		}
+		return l.errorLogger, true
 	case LevelCritical:
-		if l.level <= LevelCritical {
-			logMessage(l.criticalLogger, "CRITICAL", fullMessage)
-		}
+		return l.criticalLogger, true
 	default:
-		logMessage(l.errorLogger, "ERROR", fmt.Sprintf("Unknown log level: %d", level))
+		return nil, false
 	}
 }
 
-// logMessage is a helper function to log the message
-func logMessage(logger *log.Logger, level string, message string) {
-	logger.Printf("[%s] %s", level, message)
+// knownLevel reports whether level is one of the defined levels
+func knownLevel(level int) bool {
+	return level >= LevelInfo && level <= LevelCritical
 }
 
-// Notify handles logging based on the message type
-func Notify(messageType string, message string, context ...interface{}) {
-	var formattedMessage string
+// levelName returns the name of the given level, such as "INFO"
+func levelName(level int) string {
+	switch level {
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	case LevelCritical:
+		return "CRITICAL"
+	default:
+		return fmt.Sprintf("LEVEL(%d)", level)
+	}
+}
+
+// currentTime returns the time from the logger's clock
+func (l *Logger) currentTime() time.Time {
+	if l.now == nil {
+		return time.Now()
+	}
+	return l.now()
+}
 
+// Notify handles logging based on the message type. The message is
+// formatted with the context arguments like fmt.Sprintf; arguments that do
+// not match its verbs are appended space-separated instead.
+func Notify(messageType string, message string, context ...interface{}) {
 	// Format the message with the provided context if any
-	if len(context) > 0 {
-		formattedMessage = fmt.Sprintf(message, context...)
-	} else {
-		formattedMessage = message
-	}
-
-	// Switch case to handle different message types
-	switch messageType {
-	case "Info":
-		globalLogger.Log(LevelInfo, formattedMessage)
-	case "Warn":
-		globalLogger.Log(LevelWarn, formattedMessage)
-	case "Error":
-		globalLogger.Log(LevelError, formattedMessage)
-	case "Critical":
-		globalLogger.Log(LevelCritical, formattedMessage)
-	default:
-		globalLogger.Log(LevelError, "Unknown message type: "+messageType)
+	formattedMessage := formatNotify(message, context)
+
+	level, known := notifyTypeLevel(messageType)
+	if known && bufferEarly(1, level, formattedMessage) {
+		return
+	}
+
+	logger := GetGlobalLogger()
+	if logger == nil {
+		return
+	}
+	if known {
+		logger.logDepth(2, level, formattedMessage)
+		return
+	}
+	if level, ok := logger.notifyDefaultLevel(); ok {
+		logger.logAtDepth(2, time.Time{}, []Field{{Key: "message_type", Value: messageType}}, level, formattedMessage)
+		return
 	}
+	logger.logDepth(2, LevelError, "Unknown message type: "+messageType)
 }
 
-// InitFromEnv sets the log level based on an environment variable
+// WithNotifyDefaultLevel makes Notify log messages with an unknown message
+// type at level, keeping the message and adding the type as a
+// "message_type" field, instead of replacing them with an ERROR about the
+// unknown type. It applies to the global logger.
+func WithNotifyDefaultLevel(level int) Option {
+	return func(l *Logger) error {
+		if !knownLevel(level) {
+			return fmt.Errorf("notifyme: unknown log level %d", level)
+		}
+		l.opts.notifyDefault = true
+		l.opts.notifyLevel = level
+		return nil
+	}
+}
+
+// notifyDefaultLevel returns the level set by WithNotifyDefaultLevel
+func (l *Logger) notifyDefaultLevel() (int, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.opts.notifyLevel, l.opts.notifyDefault
+}
+
+// InitFromEnv sets the log level based on an environment variable. The
+// value is a level name or one registered with SetEnvLevelStrings.
 func InitFromEnv() {
 	if logLevel, exists := os.LookupEnv("LOG_LEVEL"); exists {
-		switch logLevel {
-		case "INFO":
-			SetLevel(LevelInfo)
-		case "WARN":
-			SetLevel(LevelWarn)
-		case "ERROR":
-			SetLevel(LevelError)
-		case "CRITICAL":
-			SetLevel(LevelCritical)
-		default:
-			SetLevel(LevelError) // Default level if an unknown value is found
+		level, err := parseEnvLevel(logLevel)
+		if err != nil {
+			level = LevelError // Default level if an unknown value is found
+		}
+		SetLevel(level)
+	}
+}
+
+// InitFieldsFromEnv attaches fields read from environment variables to
+// every entry of the global logger. The mapping goes from field key to
+// variable name, e.g. {"env": "ENVIRONMENT", "service": "SERVICE"}.
+// Variables that are not set are skipped.
+func InitFieldsFromEnv(mapping map[string]string) {
+	logger := globalLogger.Load()
+	if logger == nil {
+		return
+	}
+	keys := make([]string, 0, len(mapping))
+	for key := range mapping {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	for _, key := range keys {
+		if value, ok := os.LookupEnv(mapping[key]); ok {
+			logger.fields = setField(logger.fields, key, value)
 		}
 	}
 }
@@ -165,7 +597,7 @@ func (l *Logger) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&struct {
 		Level int `json:"level"`
 	}{
-		Level: l.level,
+		Level: int(l.level.Load()),
 	})
 }
 
@@ -178,10 +610,10 @@ func (l *Logger) UnmarshalJSON(data []byte) error {
 	}
 	l.mu.Lock()
 	defer l.mu.Unlock()
-	l.level = aux.Level
-	l.infoLogger = log.New(os.Stdout, "INFO: ", log.Ldate|log.Ltime|log.Lshortfile)
-	l.warnLogger = log.New(os.Stdout, "WARN: ", log.Ldate|log.Ltime|log.Lshortfile)
-	l.errorLogger = log.New(os.Stdout, "ERROR: ", log.Ldate|log.Ltime|log.Lshortfile)
-	l.criticalLogger = log.New(os.Stdout, "CRITICAL: ", log.Ldate|log.Ltime|log.Lshortfile)
+	l.level.Store(int32(aux.Level))
+	l.infoLogger = log.New(os.Stdout, "INFO: ", 0)
+	l.warnLogger = log.New(os.Stdout, "WARN: ", 0)
+	l.errorLogger = log.New(os.Stdout, "ERROR: ", 0)
+	l.criticalLogger = log.New(os.Stdout, "CRITICAL: ", 0)
 	return nil
 }
@@ -0,0 +1,647 @@
+package notifyme
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingSink counts the entries written to it and how often it was
+// closed
+type countingSink struct {
+	writes atomic.Int32
+	closes atomic.Int32
+}
+
+func (s *countingSink) Write(Entry) error {
+	s.writes.Add(1)
+	return nil
+}
+
+func (s *countingSink) Close() error {
+	s.closes.Add(1)
+	return nil
+}
+
+func TestCloneLevelIndependent(t *testing.T) {
+	tests := []struct {
+		name          string
+		original      int
+		clone         int
+		wantOriginal  bool
+		wantClone     bool
+		loggedAtLevel int
+	}{
+		{"clone raised", LevelInfo, LevelError, true, false, LevelWarn},
+		{"clone lowered", LevelError, LevelInfo, false, true, LevelWarn},
+		{"unchanged", LevelWarn, LevelWarn, true, true, LevelWarn},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			original := newWriterLogger(tt.original, &buf)
+			clone := original.Clone()
+			clone.SetLevel(tt.clone)
+
+			original.Log(tt.loggedAtLevel, "from original")
+			clone.Log(tt.loggedAtLevel, "from clone")
+
+			out := buf.String()
+			if got := strings.Contains(out, "from original"); got != tt.wantOriginal {
+				t.Errorf("original logged = %v, want %v: %q", got, tt.wantOriginal, out)
+			}
+			if got := strings.Contains(out, "from clone"); got != tt.wantClone {
+				t.Errorf("clone logged = %v, want %v: %q", got, tt.wantClone, out)
+			}
+			if original.effectiveLevel() != tt.original {
+				t.Errorf("original level = %d, want %d", original.effectiveLevel(), tt.original)
+			}
+		})
+	}
+}
+
+func TestCloneConfigurationIndependent(t *testing.T) {
+	var buf bytes.Buffer
+	original := newWriterLogger(LevelInfo, &buf)
+	if err := original.Configure(WithFormat(FormatText)); err != nil {
+		t.Fatal(err)
+	}
+	clone := original.Clone()
+	if err := clone.Configure(WithFormat(FormatJSON)); err != nil {
+		t.Fatal(err)
+	}
+	clone.AddProcessor(func(e *Entry) { e.Message += " processed" })
+
+	original.Log(LevelInfo, "text entry")
+	clone.Log(LevelInfo, "json entry")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %q", len(lines), buf.String())
+	}
+	if strings.HasPrefix(lines[0], "{") || !strings.HasSuffix(lines[0], "text entry") {
+		t.Errorf("original line = %q, want unprocessed text", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "{") || !strings.Contains(lines[1], "json entry processed") {
+		t.Errorf("clone line = %q, want processed JSON", lines[1])
+	}
+}
+
+func TestCloneSinks(t *testing.T) {
+	original := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	shared := &countingSink{}
+	original.AddSink(shared)
+	clone := original.Clone()
+	own := &countingSink{}
+	clone.AddSink(own)
+
+	original.Log(LevelInfo, "original")
+	clone.Log(LevelInfo, "clone")
+	if shared.writes.Load() != 2 || own.writes.Load() != 1 {
+		t.Errorf("writes: shared %d, want 2; clone's own %d, want 1", shared.writes.Load(), own.writes.Load())
+	}
+
+	if err := clone.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if shared.closes.Load() != 0 || own.closes.Load() != 1 {
+		t.Errorf("after closing the clone: shared closed %d times, want 0; clone's own %d, want 1",
+			shared.closes.Load(), own.closes.Load())
+	}
+	original.Log(LevelInfo, "still open")
+	if shared.writes.Load() != 3 {
+		t.Errorf("shared sink got %d writes after the clone closed, want 3", shared.writes.Load())
+	}
+
+	if err := original.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if shared.closes.Load() != 1 || own.closes.Load() != 1 {
+		t.Errorf("after closing the original: shared closed %d times, clone's own %d; want 1 each",
+			shared.closes.Load(), own.closes.Load())
+	}
+}
+
+func TestCloneConcurrent(t *testing.T) {
+	original := newWriterLogger(LevelInfo, &lockedBuffer{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			clone := original.Clone()
+			clone.SetLevel(LevelWarn)
+			clone.Log(LevelWarn, "clone")
+		}()
+		go func() {
+			defer wg.Done()
+			original.Log(LevelInfo, "original")
+		}()
+	}
+	wg.Wait()
+	if original.effectiveLevel() != LevelInfo {
+		t.Errorf("original level = %d, want INFO", original.effectiveLevel())
+	}
+}
+
+// lockedBuffer is a bytes.Buffer safe for concurrent writes
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestMustNewLogger(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name      string
+		output    []string
+		wantPanic bool
+	}{
+		{"stdout", nil, false},
+		{"writable file", []string{filepath.Join(dir, "app.log")}, false},
+		{"missing directory", []string{filepath.Join(dir, "missing", "app.log")}, true},
+		{"directory", []string{dir}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logger *Logger
+			var recovered interface{}
+			func() {
+				defer func() { recovered = recover() }()
+				logger = MustNewLogger(LevelInfo, tt.output...)
+			}()
+
+			if !tt.wantPanic {
+				if recovered != nil {
+					t.Fatalf("MustNewLogger panicked: %v", recovered)
+				}
+				if logger == nil {
+					t.Fatal("MustNewLogger returned nil")
+				}
+				logger.Close()
+				return
+			}
+			message, ok := recovered.(string)
+			if !ok || !strings.HasPrefix(message, "notifyme: MustNewLogger(") || !strings.Contains(message, tt.output[0]) {
+				t.Errorf("panic value = %v, want a message naming the path", recovered)
+			}
+		})
+	}
+}
+
+func TestNewLoggerE(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewLoggerE(LevelInfo, filepath.Join(dir, "missing", "app.log")); err == nil {
+		t.Error("NewLoggerE returned no error for an unwritable path")
+	}
+	logger, err := NewLoggerE(LevelInfo, filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Close()
+}
+
+func TestLogAt(t *testing.T) {
+	logTime := time.Date(2024, 3, 9, 12, 0, 0, 0, time.UTC)
+	eventTime := logTime.Add(-90 * time.Minute)
+	tests := []struct {
+		name   string
+		format Format
+		want   []string
+	}{
+		{"text", FormatText, []string{"INFO: 2024/03/09 12:00:00 ", " event_ts=2024/03/09 10:30:00"}},
+		{"json", FormatJSON, []string{`"ts":"2024-03-09T12:00:00Z"`, `"event_ts":"2024-03-09T10:30:00Z"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newWriterLogger(LevelInfo, &buf)
+			logger.now = func() time.Time { return logTime }
+			ring, _ := NewRingSink(2)
+			logger.AddSink(ring)
+			if err := logger.Configure(WithFormat(tt.format)); err != nil {
+				t.Fatal(err)
+			}
+			logger.LogAt(eventTime, LevelInfo, "backfilled")
+			logger.Log(LevelInfo, "live")
+
+			lines := strings.Split(buf.String(), "\n")
+			for _, want := range tt.want {
+				if !strings.Contains(lines[0], want) {
+					t.Errorf("line %q lacks %s", lines[0], want)
+				}
+			}
+			if strings.Contains(lines[1], "event_ts") {
+				t.Errorf("entry logged without an event time has event_ts: %q", lines[1])
+			}
+			entries := ring.Entries()
+			if !entries[0].Time.Equal(logTime) || !entries[0].EventTime.Equal(eventTime) {
+				t.Errorf("entry times = %v and %v, want %v and %v", entries[0].Time, entries[0].EventTime, logTime, eventTime)
+			}
+			if !entries[1].EventTime.IsZero() {
+				t.Errorf("live entry EventTime = %v, want zero", entries[1].EventTime)
+			}
+		})
+	}
+}
+
+// isolateGlobalLogger resets the global logger state for the test and
+// restores it when the test finishes
+func isolateGlobalLogger(t *testing.T) {
+	saved, initialized, strict := globalLogger.Load(), globalInitialized.Load(), strictGlobal.Load()
+	globalLogger.Store(nil)
+	globalInitialized.Store(false)
+	strictGlobal.Store(false)
+	once = sync.Once{}
+	t.Cleanup(func() {
+		if logger := globalLogger.Load(); logger != nil && logger != saved {
+			logger.Close()
+		}
+		globalLogger.Store(saved)
+		globalInitialized.Store(initialized)
+		strictGlobal.Store(strict)
+		once = sync.Once{}
+	})
+}
+
+func TestNotifyDuringReinitialize(t *testing.T) {
+	isolateGlobalLogger(t)
+	dir := t.TempDir()
+	ReinitializeGlobalLogger(LevelInfo, filepath.Join(dir, "0.log"))
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					Notify("Info", "tick %d", 1)
+					SetLevel(LevelInfo)
+					GetGlobalLogger()
+				}
+			}
+		}()
+	}
+	var previous []*Logger
+	for i := 1; i <= 20; i++ {
+		previous = append(previous, ReinitializeGlobalLogger(LevelInfo, filepath.Join(dir, strconv.Itoa(i)+".log")))
+	}
+	close(stop)
+	wg.Wait()
+	for _, logger := range previous {
+		logger.Close()
+	}
+
+	Notify("Info", "final")
+	lines := readLines(t, filepath.Join(dir, "20.log"))
+	if len(lines) == 0 || !strings.HasSuffix(lines[len(lines)-1], "final") {
+		t.Errorf("last logger's file ends with %q, want the final entry", lines)
+	}
+}
+
+func TestReinitializeGlobalLoggerReturnsPrevious(t *testing.T) {
+	isolateGlobalLogger(t)
+	dir := t.TempDir()
+	InitializeGlobalLogger(LevelWarn, filepath.Join(dir, "first.log"))
+	first := GetGlobalLogger()
+	if !IsGlobalLoggerInitialized() {
+		t.Error("IsGlobalLoggerInitialized = false after InitializeGlobalLogger")
+	}
+
+	InitializeGlobalLogger(LevelInfo, filepath.Join(dir, "ignored.log"))
+	if GetGlobalLogger() != first {
+		t.Error("a second InitializeGlobalLogger replaced the logger")
+	}
+	if previous := ReinitializeGlobalLogger(LevelInfo, filepath.Join(dir, "second.log")); previous != first {
+		t.Error("ReinitializeGlobalLogger did not return the previous logger")
+	}
+	first.Close()
+	if GetGlobalLogger() == first || GetGlobalLogger().effectiveLevel() != LevelInfo {
+		t.Error("ReinitializeGlobalLogger did not install the new logger")
+	}
+}
+
+func TestSetWriterLevel(t *testing.T) {
+	tests := []struct {
+		name        string
+		level       int
+		writerLevel int
+		wantWriter  []string
+		wantSinks   []string
+	}{
+		{"writer narrower", LevelInfo, LevelError, []string{"ERROR", "CRITICAL"}, []string{"INFO", "WARN", "ERROR", "CRITICAL"}},
+		{"writer wider than the level", LevelWarn, LevelInfo, []string{"WARN", "ERROR", "CRITICAL"}, []string{"WARN", "ERROR", "CRITICAL"}},
+		{"same", LevelInfo, LevelInfo, []string{"INFO", "WARN", "ERROR", "CRITICAL"}, []string{"INFO", "WARN", "ERROR", "CRITICAL"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newWriterLogger(tt.level, &buf)
+			ring, _ := NewRingSink(10)
+			logger.AddSink(ring)
+			logger.SetWriterLevel(tt.writerLevel)
+			for level := LevelInfo; level <= LevelCritical; level++ {
+				logger.Log(level, levelName(level))
+			}
+
+			var written []string
+			for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+				if line != "" {
+					written = append(written, line[:strings.Index(line, ":")])
+				}
+			}
+			var delivered []string
+			for _, entry := range ring.Entries() {
+				delivered = append(delivered, entry.Message)
+			}
+			if strings.Join(written, ",") != strings.Join(tt.wantWriter, ",") {
+				t.Errorf("writer got %v, want %v", written, tt.wantWriter)
+			}
+			if strings.Join(delivered, ",") != strings.Join(tt.wantSinks, ",") {
+				t.Errorf("sinks got %v, want %v", delivered, tt.wantSinks)
+			}
+		})
+	}
+}
+
+func TestFilteredLogSkipsMutex(t *testing.T) {
+	logger := newWriterLogger(LevelError, &bytes.Buffer{})
+	logger.mu.Lock()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		logger.Log(LevelInfo, "filtered")
+		logger.NewEvent(LevelWarn).Str("k", "v").Msg("filtered")
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("filtered logging waited for the logger mutex")
+	}
+	logger.mu.Unlock()
+	<-done
+}
+
+func TestSetLevelConcurrent(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger := newWriterLogger(LevelInfo, buf)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logger.SetLevel([]int{LevelInfo, LevelError}[(i+j)%2])
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logger.Log(LevelInfo, "maybe")
+				logger.Log(LevelCritical, "always")
+			}
+		}()
+	}
+	wg.Wait()
+	if got := strings.Count(buf.String(), "[CRITICAL] always"); got != 400 {
+		t.Errorf("got %d CRITICAL lines, want 400", got)
+	}
+}
+
+// BenchmarkLogFiltered compares the lock-free level check with checking
+// under the logger mutex as filtered logs used to
+func BenchmarkLogFiltered(b *testing.B) {
+	logger := newWriterLogger(LevelError, &bytes.Buffer{})
+	b.Run("lock-free", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				logger.Log(LevelInfo, "filtered")
+			}
+		})
+	})
+	b.Run("locked", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				logger.mu.Lock()
+				filtered := logger.effectiveLevel() > LevelInfo
+				logger.mu.Unlock()
+				if !filtered {
+					b.Fatal("entry was not filtered")
+				}
+			}
+		})
+	})
+}
+
+func TestInitFieldsFromEnv(t *testing.T) {
+	t.Setenv("NOTIFYME_TEST_ENVIRONMENT", "staging")
+	t.Setenv("NOTIFYME_TEST_SERVICE", "billing")
+	t.Setenv("NOTIFYME_TEST_EMPTY", "")
+	tests := []struct {
+		name    string
+		mapping map[string]string
+		want    []Field
+	}{
+		{
+			"set variables",
+			map[string]string{"service": "NOTIFYME_TEST_SERVICE", "env": "NOTIFYME_TEST_ENVIRONMENT"},
+			[]Field{{Key: "env", Value: "staging"}, {Key: "service", Value: "billing"}},
+		},
+		{
+			"missing variable skipped",
+			map[string]string{"env": "NOTIFYME_TEST_ENVIRONMENT", "version": "NOTIFYME_TEST_UNSET"},
+			[]Field{{Key: "env", Value: "staging"}},
+		},
+		{
+			"empty variable kept",
+			map[string]string{"empty": "NOTIFYME_TEST_EMPTY"},
+			[]Field{{Key: "empty", Value: ""}},
+		},
+		{"nil mapping", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ring := useGlobalRing(t)
+			InitFieldsFromEnv(tt.mapping)
+			Notify("Info", "started")
+			GetGlobalLogger().Log(LevelWarn, "direct")
+
+			for _, entry := range ring.Entries() {
+				if !reflect.DeepEqual(entry.Fields, tt.want) {
+					t.Errorf("%q fields = %v, want %v", entry.Message, entry.Fields, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestInitFieldsFromEnvReplacesField(t *testing.T) {
+	t.Setenv("NOTIFYME_TEST_ENVIRONMENT", "production")
+	ring := useGlobalRing(t)
+	InitFieldsFromEnv(map[string]string{"env": "NOTIFYME_TEST_ENVIRONMENT"})
+	t.Setenv("NOTIFYME_TEST_ENVIRONMENT", "staging")
+	InitFieldsFromEnv(map[string]string{"env": "NOTIFYME_TEST_ENVIRONMENT"})
+	Notify("Info", "started")
+
+	if want := []Field{{Key: "env", Value: "staging"}}; !reflect.DeepEqual(ring.Entries()[0].Fields, want) {
+		t.Errorf("fields = %v, want %v", ring.Entries()[0].Fields, want)
+	}
+}
+
+func TestInitFieldsFromEnvWithoutGlobalLogger(t *testing.T) {
+	isolateGlobalLogger(t)
+	InitFieldsFromEnv(map[string]string{"env": "NOTIFYME_TEST_ENVIRONMENT"})
+	if globalLogger.Load() != nil {
+		t.Error("InitFieldsFromEnv created a global logger")
+	}
+}
+
+func TestGetGlobalLoggerDefault(t *testing.T) {
+	isolateGlobalLogger(t)
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	stdout := os.Stdout
+	os.Stdout = w
+	logger := GetGlobalLogger()
+	os.Stdout = stdout
+
+	if logger == nil {
+		t.Fatal("GetGlobalLogger returned nil before initialization")
+	}
+	if IsGlobalLoggerInitialized() {
+		t.Error("the default logger counts as initialized")
+	}
+	if GetGlobalLogger() != logger {
+		t.Error("GetGlobalLogger created a second default logger")
+	}
+	Notify("Warn", "no setup needed")
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "[WARN] no setup needed") {
+		t.Errorf("default logger wrote %q to stdout", out)
+	}
+}
+
+func TestGetGlobalLoggerStrict(t *testing.T) {
+	isolateGlobalLogger(t)
+	SetStrictGlobalLogger(true)
+
+	if logger := GetGlobalLogger(); logger != nil {
+		t.Fatalf("GetGlobalLogger = %v in strict mode, want nil", logger)
+	}
+	Notify("Error", "dropped")
+	SetLevel(LevelWarn)
+	if globalLogger.Load() != nil {
+		t.Error("strict mode installed a default logger")
+	}
+
+	InitializeGlobalLogger(LevelInfo, filepath.Join(t.TempDir(), "app.log"))
+	if GetGlobalLogger() == nil || !IsGlobalLoggerInitialized() {
+		t.Error("strict mode hid the initialized logger")
+	}
+}
+
+func TestOnLevelChange(t *testing.T) {
+	type change struct{ old, new int }
+	tests := []struct {
+		name   string
+		levels []int
+		want   []change
+	}{
+		{"single change", []int{LevelError}, []change{{LevelInfo, LevelError}}},
+		{"several changes", []int{LevelWarn, LevelCritical, LevelInfo}, []change{
+			{LevelInfo, LevelWarn}, {LevelWarn, LevelCritical}, {LevelCritical, LevelInfo},
+		}},
+		{"unchanged level", []int{LevelInfo, LevelWarn, LevelWarn}, []change{{LevelInfo, LevelWarn}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+			var got []change
+			logger.OnLevelChange(func(old, new int) { got = append(got, change{old, new}) })
+			for _, level := range tt.levels {
+				logger.SetLevel(level)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("callback got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOnLevelChangeCallbackMayLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newWriterLogger(LevelInfo, &buf)
+	logger.OnLevelChange(func(old, new int) {
+		logger.Log(LevelCritical, "level changed from "+levelName(old)+" to "+levelName(new))
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		logger.SetLevel(LevelError)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("SetLevel deadlocked on a logging callback")
+	}
+	if !strings.Contains(buf.String(), "level changed from INFO to ERROR") {
+		t.Errorf("callback output %q", buf.String())
+	}
+}
+
+func TestOnLevelChangeClones(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	var calls []string
+	logger.OnLevelChange(func(int, int) { calls = append(calls, "parent") })
+	clone := logger.Clone()
+	clone.OnLevelChange(func(int, int) { calls = append(calls, "clone") })
+
+	clone.SetLevel(LevelWarn)
+	logger.SetLevel(LevelError)
+	if want := []string{"parent", "clone", "parent"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("callbacks ran as %v, want %v", calls, want)
+	}
+}
+
+func TestOnLevelChangeGlobalSetLevel(t *testing.T) {
+	isolateGlobalLogger(t)
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	globalLogger.Store(logger)
+	var got int
+	logger.OnLevelChange(func(_, new int) { got = new })
+	SetLevel(LevelCritical)
+	if got != LevelCritical {
+		t.Errorf("callback got level %d, want CRITICAL", got)
+	}
+}
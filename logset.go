@@ -0,0 +1,132 @@
+package notifyme
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// logSetReader reads a list of log files one after the other, opening each
+// only when the previous one is exhausted
+type logSetReader struct {
+	paths  []string
+	file   *os.File
+	reader io.Reader
+	gz     *gzip.Reader
+}
+
+// OpenLogSet presents the files in dir matching the glob pattern, e.g.
+// "app.log*", as a single stream in chronological order, oldest first by
+// modification time. Files ending in .gz are decompressed transparently.
+// Files are opened one at a time as the stream reaches them.
+func OpenLogSet(dir, pattern string) (io.ReadCloser, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, pattern))
+	if err != nil {
+		return nil, fmt.Errorf("notifyme: invalid log set pattern: %w", err)
+	}
+
+	type logFile struct {
+		path string
+		info os.FileInfo
+	}
+	files := make([]logFile, 0, len(matches))
+	for _, path := range matches {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			continue
+		}
+		files = append(files, logFile{path: path, info: info})
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("notifyme: no log files match %s", filepath.Join(dir, pattern))
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		ti, tj := files[i].info.ModTime(), files[j].info.ModTime()
+		if ti.Equal(tj) {
+			return files[i].path < files[j].path
+		}
+		return ti.Before(tj)
+	})
+
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = file.path
+	}
+	return &logSetReader{paths: paths}, nil
+}
+
+// Read reads from the current file, moving on to the next one at its end
+func (r *logSetReader) Read(p []byte) (int, error) {
+	for {
+		if r.reader == nil {
+			if len(r.paths) == 0 {
+				return 0, io.EOF
+			}
+			if err := r.open(r.paths[0]); err != nil {
+				return 0, err
+			}
+			r.paths = r.paths[1:]
+		}
+		n, err := r.reader.Read(p)
+		if errors.Is(err, io.EOF) {
+			if closeErr := r.closeCurrent(); closeErr != nil {
+				return n, closeErr
+			}
+			err = nil
+		}
+		if n > 0 || err != nil {
+			return n, err
+		}
+	}
+}
+
+// Close closes the file currently being read
+func (r *logSetReader) Close() error {
+	r.paths = nil
+	return r.closeCurrent()
+}
+
+// open makes path the current file
+func (r *logSetReader) open(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	r.file = file
+	r.reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			file.Close()
+			r.file, r.reader = nil, nil
+			return fmt.Errorf("notifyme: reading %s: %w", path, err)
+		}
+		r.gz = gz
+		r.reader = gz
+	}
+	return nil
+}
+
+// closeCurrent closes the current file, if any
+func (r *logSetReader) closeCurrent() error {
+	if r.file == nil {
+		return nil
+	}
+	var err error
+	if r.gz != nil {
+		err = r.gz.Close()
+	}
+	if closeErr := r.file.Close(); err == nil {
+		err = closeErr
+	}
+	r.file, r.reader, r.gz = nil, nil, nil
+	return err
+}
@@ -0,0 +1,132 @@
+package notifyme
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// logSetFile is a rotated file written by writeLogSet
+type logSetFile struct {
+	name    string
+	content string
+	age     time.Duration
+}
+
+// writeLogSet writes files into a new directory, compressing those ending
+// in .gz and backdating each by its age
+func writeLogSet(t *testing.T, files []logSetFile) string {
+	t.Helper()
+	dir := t.TempDir()
+	now := time.Now()
+	for _, f := range files {
+		path := filepath.Join(dir, f.name)
+		out, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var w io.Writer = out
+		var gz *gzip.Writer
+		if filepath.Ext(f.name) == ".gz" {
+			gz = gzip.NewWriter(out)
+			w = gz
+		}
+		if _, err := io.WriteString(w, f.content); err != nil {
+			t.Fatal(err)
+		}
+		if gz != nil {
+			if err := gz.Close(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := out.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, now.Add(-f.age), now.Add(-f.age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestOpenLogSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []logSetFile
+		pattern string
+		want    string
+	}{
+		{"mixed plain and gzip", []logSetFile{
+			{"app.log", "5\n", 0},
+			{"app.log.1", "4\n", time.Hour},
+			{"app.log.2.gz", "3\n", 2 * time.Hour},
+			{"app.log.3", "2\n", 3 * time.Hour},
+			{"app.log.4.gz", "0\n1\n", 4 * time.Hour},
+		}, "app.log*", "0\n1\n2\n3\n4\n5\n"},
+		{"same time ordered by name", []logSetFile{
+			{"b.log", "b\n", time.Hour},
+			{"a.log.gz", "a\n", time.Hour},
+		}, "*.log*", "a\nb\n"},
+		{"pattern filters", []logSetFile{
+			{"app.log", "app\n", 0},
+			{"other.log", "other\n", time.Hour},
+		}, "app.log*", "app\n"},
+		{"empty files", []logSetFile{
+			{"app.log", "", 0},
+			{"app.log.1.gz", "", time.Hour},
+			{"app.log.2", "old\n", 2 * time.Hour},
+		}, "app.log*", "old\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeLogSet(t, tt.files)
+			if err := os.Mkdir(filepath.Join(dir, "app.log.d"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			r, err := OpenLogSet(dir, tt.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("stream = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenLogSetErrors(t *testing.T) {
+	dir := writeLogSet(t, []logSetFile{{"app.log", "ok\n", 0}})
+	if _, err := OpenLogSet(dir, "missing*"); err == nil {
+		t.Error("OpenLogSet succeeded with no matching files")
+	}
+	if _, err := OpenLogSet(dir, "[app"); err == nil {
+		t.Error("OpenLogSet accepted a malformed pattern")
+	}
+
+	dir = writeLogSet(t, []logSetFile{{"app.log.1.gz", "", time.Hour}, {"app.log", "new\n", 0}})
+	if err := os.WriteFile(filepath.Join(dir, "app.log.1.gz"), []byte("not gzip"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "app.log.1.gz"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenLogSet(dir, "app.log*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadAll(r); err == nil {
+		t.Error("reading a corrupt gzip member succeeded")
+	}
+	if err := r.Close(); err != nil {
+		t.Errorf("Close after a read error = %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package notifyme
+
+import "errors"
+
+// WithMaxFields caps the number of fields attached to each entry at n. The
+// first n fields in the order they were added are kept and the entry gets a
+// fields_dropped field with the number removed. Fields the logger adds
+// itself, such as truncated_bytes or stack, are not counted. Zero disables
+// the limit.
+func WithMaxFields(n int) Option {
+	return func(l *Logger) error {
+		if n < 0 {
+			return errors.New("notifyme: max fields must not be negative")
+		}
+		l.opts.maxFields = n
+		return nil
+	}
+}
+
+// WithFieldsCapacity preallocates room for n fields in every entry, so
+// entries that collect per-call, stack or other fields do not grow their
+// field slice one step at a time
+func WithFieldsCapacity(n int) Option {
+	return func(l *Logger) error {
+		if n < 0 {
+			return errors.New("notifyme: fields capacity must not be negative")
+		}
+		l.opts.fieldsCapacity = n
+		return nil
+	}
+}
+
+// capFields applies the logger's field limit to the entry. It must be
+// called with the logger mutex held.
+func (l *Logger) capFields(entry *Entry) {
+	if l.opts.maxFields == 0 || len(entry.Fields) <= l.opts.maxFields {
+		return
+	}
+	dropped := len(entry.Fields) - l.opts.maxFields
+	entry.Fields = setField(entry.Fields[:l.opts.maxFields:l.opts.maxFields], "fields_dropped", dropped)
+}
@@ -0,0 +1,144 @@
+package notifyme
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// numberedFields returns n fields named f0, f1, ... with their index as
+// value
+func numberedFields(n int) []Field {
+	fields := make([]Field, n)
+	for i := range fields {
+		fields[i] = Field{Key: "f" + strconv.Itoa(i), Value: i}
+	}
+	return fields
+}
+
+func TestWithMaxFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		max    int
+		fields int
+		want   []Field
+	}{
+		{"under the cap", 3, 2, numberedFields(2)},
+		{"at the cap", 3, 3, numberedFields(3)},
+		{"over the cap", 3, 5, append(numberedFields(3), Field{Key: "fields_dropped", Value: 2})},
+		{"cap of one", 1, 4, append(numberedFields(1), Field{Key: "fields_dropped", Value: 3})},
+		{"disabled", 0, 40, numberedFields(40)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+			ring, _ := NewRingSink(2)
+			logger.AddSink(ring)
+			if err := logger.Configure(WithMaxFields(tt.max)); err != nil {
+				t.Fatal(err)
+			}
+			fields := numberedFields(tt.fields)
+			keyvals := make([]interface{}, 0, 2*len(fields))
+			for _, field := range fields {
+				keyvals = append(keyvals, field.Key, field.Value)
+			}
+			logger.With(keyvals...).Log(LevelInfo, "logger fields")
+			event := logger.NewEvent(LevelInfo)
+			for _, field := range fields {
+				event.Int(field.Key, field.Value.(int))
+			}
+			event.Msg("event fields")
+
+			for _, entry := range ring.Entries() {
+				if !reflect.DeepEqual(entry.Fields, tt.want) {
+					t.Errorf("%s: fields = %v, want %v", entry.Message, entry.Fields, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestWithMaxFieldsKeepsLoggerFieldsFirst(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	ring, _ := NewRingSink(1)
+	logger.AddSink(ring)
+	if err := logger.Configure(WithMaxFields(2), WithStackTraceLevel(LevelError, 1)); err != nil {
+		t.Fatal(err)
+	}
+	logger.With("service", "api").NewEvent(LevelError).Str("order", "A-1").Str("user", "ann").Msg("failed")
+
+	entry := ring.Entries()[0]
+	keys := make([]string, len(entry.Fields))
+	for i, field := range entry.Fields {
+		keys[i] = field.Key
+	}
+	if want := []string{"service", "order", "fields_dropped", "stack"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("field keys = %v, want %v", keys, want)
+	}
+}
+
+func TestWithMaxFieldsInvalid(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	if err := logger.Configure(WithMaxFields(-1)); err == nil {
+		t.Error("Configure accepted a negative limit")
+	}
+}
+
+func TestWithFieldsCapacity(t *testing.T) {
+	tests := []struct {
+		name     string
+		capacity int
+		fields   int
+		min      int
+	}{
+		{"preallocated", 8, 2, 8},
+		{"more fields than capacity", 2, 5, 5},
+		{"no fields", 4, 0, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+			if err := logger.Configure(WithFieldsCapacity(tt.capacity)); err != nil {
+				t.Fatal(err)
+			}
+			logger.mu.Lock()
+			entry := logger.newEntry(LevelInfo, "m", Caller{}, numberedFields(tt.fields))
+			logger.mu.Unlock()
+			if len(entry.Fields) != tt.fields || cap(entry.Fields) < tt.min {
+				t.Errorf("fields len %d cap %d, want len %d cap at least %d", len(entry.Fields), cap(entry.Fields), tt.fields, tt.min)
+			}
+		})
+	}
+}
+
+func TestWithFieldsCapacityInvalid(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	if err := logger.Configure(WithFieldsCapacity(-1)); err == nil {
+		t.Error("Configure accepted a negative capacity")
+	}
+}
+
+// BenchmarkFieldsCapacity logs entries whose fields grow after they are
+// built, here by a message ID, with and without room reserved for it
+func BenchmarkFieldsCapacity(b *testing.B) {
+	for _, c := range []struct {
+		name string
+		opts []Option
+	}{{"default", nil}, {"capacity 8", []Option{WithFieldsCapacity(8)}}} {
+		b.Run(c.name, func(b *testing.B) {
+			logger := newWriterLogger(LevelInfo, io.Discard)
+			logger.AddSink(&countingSink{})
+			opts := append([]Option{WithMessageID(func() string { return "id" })}, c.opts...)
+			if err := logger.Configure(opts...); err != nil {
+				b.Fatal(err)
+			}
+			child := logger.With("service", "api", "region", "eu")
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				child.NewEvent(LevelInfo).Str("user", "ann").Int("items", 3).Bool("paid", true).Msg("checkout")
+			}
+		})
+	}
+}
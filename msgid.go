@@ -0,0 +1,119 @@
+package notifyme
+
+import (
+	"crypto/rand"
+	"sync"
+	"time"
+)
+
+// msgIDKey is the field that carries the message ID
+const msgIDKey = "msgid"
+
+// crockford is the Crockford base32 alphabet used by ULIDs
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// WithMessageID gives every entry a unique "msgid" field from gen, for
+// correlating an entry across outputs. A nil gen uses a ULID generator,
+// whose IDs sort in creation order. An empty ID leaves the field off that
+// entry. The ID is also the MSGID of FormatRFC5424 lines.
+func WithMessageID(gen func() string) Option {
+	return func(l *Logger) error {
+		if gen == nil {
+			gen = NewULIDGenerator()
+		}
+		l.opts.msgID = gen
+		return nil
+	}
+}
+
+// WithoutMessageID stops adding the msgid field set up by WithMessageID
+func WithoutMessageID() Option {
+	return func(l *Logger) error {
+		l.opts.msgID = nil
+		return nil
+	}
+}
+
+// NewULIDGenerator returns a function generating ULIDs: 26 characters
+// encoding a millisecond timestamp and 80 random bits. IDs generated in
+// the same millisecond increment the random part, so they stay unique
+// and ordered. The function is safe for concurrent use.
+func NewULIDGenerator() func() string {
+	var (
+		mu      sync.Mutex
+		lastMS  uint64
+		entropy [10]byte
+	)
+	return func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return nextULID(uint64(time.Now().UnixMilli()), &lastMS, &entropy)
+	}
+}
+
+// nextULID returns the next ID for the clock reading ms. A clock that went
+// backwards is held at the last timestamp, so IDs keep sorting after the
+// ones already issued. Within a millisecond the random part is
+// incremented, moving on to the next millisecond in the unlikely case
+// that it overflows. It returns "" if no randomness is available.
+func nextULID(ms uint64, lastMS *uint64, entropy *[10]byte) string {
+	switch {
+	case ms > *lastMS:
+		*lastMS = ms
+	case incrementEntropy(entropy):
+		return encodeULID(*lastMS, *entropy)
+	default:
+		*lastMS++
+	}
+	if _, err := rand.Read(entropy[:]); err != nil {
+		return ""
+	}
+	return encodeULID(*lastMS, *entropy)
+}
+
+// incrementEntropy adds one to the random part, reporting false when it
+// overflowed
+func incrementEntropy(entropy *[10]byte) bool {
+	for i := len(entropy) - 1; i >= 0; i-- {
+		entropy[i]++
+		if entropy[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeULID renders the 48-bit timestamp and 80-bit entropy as 26
+// base32 characters
+func encodeULID(ms uint64, entropy [10]byte) string {
+	var id [26]byte
+	for i := 9; i >= 0; i-- {
+		id[i] = crockford[ms&0x1f]
+		ms >>= 5
+	}
+	// 80 bits are exactly 16 characters of 5 bits
+	var acc uint64
+	bits := 0
+	pos := 10
+	for _, b := range entropy {
+		acc = acc<<8 | uint64(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			id[pos] = crockford[(acc>>uint(bits))&0x1f]
+			pos++
+		}
+	}
+	return string(id[:])
+}
+
+// addMessageID sets the msgid field from the configured generator. It must
+// be called with the logger mutex held.
+func (l *Logger) addMessageID(entry *Entry) {
+	if l.opts.msgID == nil {
+		return
+	}
+	if id := l.opts.msgID(); id != "" {
+		entry.Fields = setField(entry.Fields, msgIDKey, id)
+	}
+}
@@ -0,0 +1,143 @@
+package notifyme
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+)
+
+func TestWithMessageID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newWriterLogger(LevelInfo, &buf)
+	ring, _ := NewRingSink(10)
+	logger.AddSink(ring)
+	n := 0
+	gen := func() string {
+		n++
+		return "id-" + strconv.Itoa(n)
+	}
+	if err := logger.Configure(WithMessageID(gen)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		logger.Log(LevelInfo, "entry")
+	}
+
+	seen := make(map[string]bool)
+	for i, entry := range ring.Entries() {
+		id, ok := lastField(entry.Fields, msgIDKey)
+		if !ok {
+			t.Fatalf("entry %d has no msgid field", i)
+		}
+		if want := "id-" + strconv.Itoa(i+1); id != want {
+			t.Errorf("entry %d msgid = %v, want %s", i, id, want)
+		}
+		if seen[id.(string)] {
+			t.Errorf("msgid %v repeated", id)
+		}
+		seen[id.(string)] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("got %d distinct IDs, want 3", len(seen))
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("msgid=id-3")) {
+		t.Errorf("primary output lacks the msgid: %q", buf.String())
+	}
+}
+
+func TestWithMessageIDEmptyAndDisabled(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	ring, _ := NewRingSink(10)
+	logger.AddSink(ring)
+	id := ""
+	if err := logger.Configure(WithMessageID(func() string { return id })); err != nil {
+		t.Fatal(err)
+	}
+	logger.Log(LevelInfo, "empty id")
+	id = "set"
+	logger.Log(LevelInfo, "with id")
+	if err := logger.Configure(WithoutMessageID()); err != nil {
+		t.Fatal(err)
+	}
+	logger.Log(LevelInfo, "disabled")
+
+	tests := []struct {
+		message string
+		want    bool
+	}{
+		{"empty id", false},
+		{"with id", true},
+		{"disabled", false},
+	}
+	for i, entry := range ring.Entries() {
+		_, ok := lastField(entry.Fields, msgIDKey)
+		if entry.Message != tests[i].message || ok != tests[i].want {
+			t.Errorf("entry %q has msgid %v, want %q with %v", entry.Message, ok, tests[i].message, tests[i].want)
+		}
+	}
+}
+
+func TestULIDGeneratorUniqueAndSorted(t *testing.T) {
+	gen := NewULIDGenerator()
+	prev := ""
+	for i := 0; i < 10000; i++ {
+		id := gen()
+		if len(id) != 26 {
+			t.Fatalf("ULID %q has %d characters, want 26", id, len(id))
+		}
+		if id <= prev {
+			t.Fatalf("ULID %q does not sort after %q", id, prev)
+		}
+		prev = id
+	}
+}
+
+func TestNextULIDOrdering(t *testing.T) {
+	tests := []struct {
+		name   string
+		clock  []uint64
+		wantMS []uint64
+	}{
+		{"same millisecond", []uint64{1000, 1000, 1000}, []uint64{1000, 1000, 1000}},
+		{"advancing", []uint64{1000, 1001, 1005}, []uint64{1000, 1001, 1005}},
+		{"clock goes backwards", []uint64{1000, 999, 500, 1000, 1001}, []uint64{1000, 1000, 1000, 1000, 1001}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lastMS uint64
+			var entropy [10]byte
+			prev := ""
+			for i, ms := range tt.clock {
+				id := nextULID(ms, &lastMS, &entropy)
+				if id <= prev {
+					t.Errorf("ID %d %q does not sort after %q", i, id, prev)
+				}
+				if want := encodeULID(tt.wantMS[i], entropy); id != want {
+					t.Errorf("ID %d = %q, want timestamp %d", i, id, tt.wantMS[i])
+				}
+				prev = id
+			}
+		})
+	}
+}
+
+func TestNextULIDEntropyOverflow(t *testing.T) {
+	lastMS := uint64(1000)
+	entropy := [10]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	before := encodeULID(lastMS, entropy)
+	id := nextULID(1000, &lastMS, &entropy)
+	if lastMS != 1001 {
+		t.Errorf("timestamp after overflow = %d, want 1001", lastMS)
+	}
+	if id <= before {
+		t.Errorf("ID after overflow %q does not sort after %q", id, before)
+	}
+}
+
+func TestEncodeULID(t *testing.T) {
+	// the timestamp of the example in the ULID specification
+	got := encodeULID(1469918176385, [10]byte{})
+	if want := "01ARYZ6S410000000000000000"; got != want {
+		t.Errorf("encodeULID = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,30 @@
+package notifyme
+
+import "strings"
+
+// defaultNewlineReplacement is used when WithReplaceNewlines gets an empty
+// replacement
+const defaultNewlineReplacement = `\n`
+
+// WithReplaceNewlines replaces newlines inside messages with replacement in
+// text output, so every entry stays on one physical line for line-based
+// parsers. An empty replacement uses a literal `\n`. Entries passed to sinks
+// keep the original message.
+func WithReplaceNewlines(replacement string) Option {
+	return func(l *Logger) error {
+		if replacement == "" {
+			replacement = defaultNewlineReplacement
+		}
+		l.opts.newlineReplacer = strings.NewReplacer("\r\n", replacement, "\n", replacement, "\r", replacement)
+		return nil
+	}
+}
+
+// replaceNewlines applies the configured newline replacement to text
+// output. It must be called with the logger mutex held.
+func (l *Logger) replaceNewlines(text string) string {
+	if l.opts.newlineReplacer == nil {
+		return text
+	}
+	return l.opts.newlineReplacer.Replace(text)
+}
@@ -0,0 +1,64 @@
+package notifyme
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWithReplaceNewlines(t *testing.T) {
+	tests := []struct {
+		name        string
+		replacement string
+		message     string
+		want        string
+	}{
+		{"default", "", "SELECT *\nFROM users", `SELECT *\nFROM users`},
+		{"custom", " | ", "line one\nline two\nline three", "line one | line two | line three"},
+		{"crlf", "⏎", "a\r\nb\rc", "a⏎b⏎c"},
+		{"single line", " | ", "no newline", "no newline"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newWriterLogger(LevelInfo, &buf)
+			ring, _ := NewRingSink(1)
+			logger.AddSink(ring)
+			if err := logger.Configure(WithReplaceNewlines(tt.replacement)); err != nil {
+				t.Fatal(err)
+			}
+			logger.Log(LevelInfo, tt.message)
+
+			out := strings.TrimSuffix(buf.String(), "\n")
+			if strings.ContainsAny(out, "\r\n") {
+				t.Errorf("output spans several lines: %q", buf.String())
+			}
+			if !strings.HasSuffix(out, "] "+tt.want) {
+				t.Errorf("output = %q, want message %q", out, tt.want)
+			}
+			if got := ring.Entries()[0].Message; got != tt.message {
+				t.Errorf("sink message = %q, want the original %q", got, tt.message)
+			}
+		})
+	}
+}
+
+func TestReplaceNewlinesKeepsJSONEscaping(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newWriterLogger(LevelInfo, &buf)
+	if err := logger.Configure(WithFormat(FormatJSON), WithReplaceNewlines(" | ")); err != nil {
+		t.Fatal(err)
+	}
+	logger.Log(LevelInfo, "line one\nline two")
+	if strings.Count(buf.String(), "\n") != 1 {
+		t.Errorf("JSON output spans several lines: %q", buf.String())
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["msg"] != "line one\nline two" {
+		t.Errorf("JSON msg = %q, want the newline kept", doc["msg"])
+	}
+}
@@ -0,0 +1,45 @@
+package notifyme
+
+import "errors"
+
+// WithUnsafeNoLock stops logging calls from taking the logger mutex, for
+// tools that log from a single goroutine and want to save its cost.
+//
+// DANGER: with this option the logger is not safe for concurrent use. The
+// caller must guarantee that Log, LogAt, WriteEntry, Event.Msg and Notify
+// are never called from two goroutines at once, and that no heartbeat is
+// running. Misuse corrupts output and logger state silently; the race
+// detector (go test -race) reports it. Configuration methods still lock.
+//
+// Background goroutines are covered as follows. The reorder timer of
+// WithReorderWindow writes entries itself, so that option is rejected
+// together with this one. Sinks that send on a flush interval, such as
+// ElasticSink, and the sink pool of WithSinkConcurrency deliver from their
+// own goroutines without touching the logger; they stay safe as long as no
+// sink or error handler logs through this logger.
+func WithUnsafeNoLock() Option {
+	return func(l *Logger) error {
+		if l.opts.reorderWindow > 0 {
+			return errors.New("notifyme: WithUnsafeNoLock cannot be used with a reorder window")
+		}
+		l.noLock.Store(true)
+		return nil
+	}
+}
+
+// lockWrite takes the logger mutex for a logging call unless
+// WithUnsafeNoLock is set, and reports whether it did
+func (l *Logger) lockWrite() bool {
+	if l.noLock.Load() {
+		return false
+	}
+	l.mu.Lock()
+	return true
+}
+
+// unlockWrite releases the mutex if lockWrite took it
+func (l *Logger) unlockWrite(locked bool) {
+	if locked {
+		l.mu.Unlock()
+	}
+}
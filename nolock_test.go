@@ -0,0 +1,73 @@
+package notifyme
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestWithUnsafeNoLockOutput(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"text", nil},
+		{"json", []Option{WithFormat(FormatJSON)}},
+		{"regular event path", []Option{WithMaxFields(10)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var locked, unlocked bytes.Buffer
+			for _, c := range []struct {
+				buf  *bytes.Buffer
+				opts []Option
+			}{{&locked, tt.opts}, {&unlocked, append([]Option{WithUnsafeNoLock()}, tt.opts...)}} {
+				logger := newEventTestLogger(t, c.buf, c.opts...)
+				child := logger.With("user", "ann")
+				child.Log(LevelInfo, "logged", 1)
+				child.LogAt(fixedTime.Add(-time.Second), LevelWarn, "late")
+				child.NewEvent(LevelError).Int("n", 2).Msg("event")
+				child.WriteEntry(Entry{Level: LevelCritical, Message: "relayed", Time: fixedTime, Caller: Caller{File: "a.go", Line: 1}})
+			}
+			if unlocked.String() != locked.String() {
+				t.Errorf("unlocked output differs\nunlocked: %q\nlocked:   %q", unlocked.String(), locked.String())
+			}
+		})
+	}
+}
+
+func TestWithUnsafeNoLockSkipsMutex(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newWriterLogger(LevelInfo, &buf)
+	if err := logger.Configure(WithUnsafeNoLock()); err != nil {
+		t.Fatal(err)
+	}
+	logger.mu.Lock()
+	logger.Log(LevelInfo, "written without the mutex")
+	logger.mu.Unlock()
+	if buf.Len() == 0 {
+		t.Error("nothing was written")
+	}
+	if !logger.Clone().noLock.Load() {
+		t.Error("clones do not keep WithUnsafeNoLock")
+	}
+}
+
+func BenchmarkLogNoLock(b *testing.B) {
+	for _, c := range []struct {
+		name string
+		opts []Option
+	}{{"locked", nil}, {"unsafe no lock", []Option{WithUnsafeNoLock()}}} {
+		b.Run(c.name, func(b *testing.B) {
+			logger := newWriterLogger(LevelInfo, io.Discard)
+			if err := logger.Configure(c.opts...); err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				logger.Log(LevelInfo, "single goroutine")
+			}
+		})
+	}
+}
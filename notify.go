@@ -0,0 +1,81 @@
+package notifyme
+
+import (
+	"fmt"
+	"strings"
+)
+
+// notifyTypeLevel returns the level of a Notify message type
+func notifyTypeLevel(messageType string) (int, bool) {
+	switch messageType {
+	case "Info":
+		return LevelInfo, true
+	case "Warn":
+		return LevelWarn, true
+	case "Error":
+		return LevelError, true
+	case "Critical":
+		return LevelCritical, true
+	default:
+		return 0, false
+	}
+}
+
+// formatNotify formats a Notify message with its arguments. When the number
+// of arguments does not match the verbs in the message it degrades instead
+// of producing %!(EXTRA ...) or %!d(MISSING) noise: arguments beyond the
+// verbs, or all of them if there are too few, are appended space-separated
+// like fmt.Println would. Messages using explicit argument indexes are
+// passed to fmt.Sprintf unchanged.
+func formatNotify(message string, args []interface{}) string {
+	if len(args) == 0 {
+		return message
+	}
+	verbs, ok := countVerbs(message)
+	switch {
+	case !ok || verbs == len(args):
+		return fmt.Sprintf(message, args...)
+	case verbs == 0 || verbs > len(args):
+		return message + " " + sprintArgs(args)
+	default:
+		return fmt.Sprintf(message, args[:verbs]...) + " " + sprintArgs(args[verbs:])
+	}
+}
+
+// countVerbs returns how many arguments the format string consumes,
+// counting * widths and precisions. It reports false for formats with
+// explicit argument indexes, whose argument count is not a simple sum.
+func countVerbs(format string) (int, bool) {
+	count := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		if i < len(format) && format[i] == '%' {
+			continue
+		}
+		for ; i < len(format); i++ {
+			c := format[i]
+			if c == '[' {
+				return 0, false
+			}
+			if c == '*' {
+				count++
+				continue
+			}
+			if strings.IndexByte("+-# 0123456789.", c) < 0 {
+				break
+			}
+		}
+		if i < len(format) {
+			count++
+		}
+	}
+	return count, true
+}
+
+// sprintArgs renders arguments separated by spaces
+func sprintArgs(args []interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(args...), "\n")
+}
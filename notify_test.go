@@ -0,0 +1,130 @@
+package notifyme
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// useGlobalRing installs a global logger configured with opts whose
+// entries are kept in the returned ring
+func useGlobalRing(t *testing.T, opts ...Option) *RingSink {
+	t.Helper()
+	isolateGlobalLogger(t)
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	ring, _ := NewRingSink(16)
+	logger.AddSink(ring)
+	if err := logger.Configure(opts...); err != nil {
+		t.Fatal(err)
+	}
+	globalLogger.Store(logger)
+	return ring
+}
+
+func TestWithNotifyDefaultLevel(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		messageType string
+		level       int
+		message     string
+		fieldType   string
+	}{
+		{"known type", []Option{WithNotifyDefaultLevel(LevelInfo)}, "Warn", LevelWarn, "disk at 91%", ""},
+		{"bogus type", []Option{WithNotifyDefaultLevel(LevelInfo)}, "Wran", LevelInfo, "disk at 91%", "Wran"},
+		{"bogus type at warn", []Option{WithNotifyDefaultLevel(LevelWarn)}, "warn", LevelWarn, "disk at 91%", "warn"},
+		{"not configured", nil, "Wran", LevelError, "Unknown message type: Wran", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ring := useGlobalRing(t, tt.opts...)
+			Notify(tt.messageType, "disk at %d%%", 91)
+
+			entries := ring.Entries()
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(entries))
+			}
+			entry := entries[0]
+			if entry.Level != tt.level || entry.Message != tt.message {
+				t.Errorf("got %s %q, want %s %q", levelName(entry.Level), entry.Message, levelName(tt.level), tt.message)
+			}
+			fieldType, ok := lastField(entry.Fields, "message_type")
+			if tt.fieldType == "" && ok {
+				t.Errorf("unexpected message_type field %v", fieldType)
+			}
+			if tt.fieldType != "" && fieldType != tt.fieldType {
+				t.Errorf("message_type = %v, want %q", fieldType, tt.fieldType)
+			}
+			if !strings.HasSuffix(entry.Caller.File, "notify_test.go") {
+				t.Errorf("caller = %s, want the Notify call", entry.Caller)
+			}
+		})
+	}
+}
+
+func TestWithNotifyDefaultLevelUsesGlobalLogger(t *testing.T) {
+	useGlobalRing(t, WithNotifyDefaultLevel(LevelInfo), WithErrorHandler(func(error) {}))
+	logger := GetGlobalLogger()
+	logger.AddSink(failingSink{})
+
+	// A failed sink write is recorded on the logger that wrote the entry
+	Notify("Wran", "disk at %d%%", 91)
+	if logger.LastError() == nil {
+		t.Error("the sink failure was not recorded on the global logger")
+	}
+}
+
+func TestWithNotifyDefaultLevelInvalid(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	if err := logger.Configure(WithNotifyDefaultLevel(42)); err == nil {
+		t.Error("Configure accepted an unknown level")
+	}
+}
+
+func TestFormatNotify(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		args    []interface{}
+		want    string
+	}{
+		{"no args", "disk at 91%", nil, "disk at 91%"},
+		{"matching args", "user %s has %d items", []interface{}{"ann", 3}, "user ann has 3 items"},
+		{"no verbs", "cache miss", []interface{}{"orders", 42}, "cache miss orders 42"},
+		{"too many args", "user %s", []interface{}{"ann", 3, true}, "user ann 3 true"},
+		{"too few args", "user %s has %d items", []interface{}{"ann"}, "user %s has %d items ann"},
+		{"escaped percent", "disk at %d%%", []interface{}{91}, "disk at 91%"},
+		{"escaped percent only", "100%% done", []interface{}{"job"}, "100%% done job"},
+		{"flags and width", "%-5s|%08.3f", []interface{}{"ab", 3.14159}, "ab   |0003.142"},
+		{"star width", "%*d", []interface{}{4, 7}, "   7"},
+		{"explicit index", "%[2]s %[1]s", []interface{}{"world", "hello"}, "hello world"},
+		{"many args", "batch", []interface{}{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, "batch 1 2 3 4 5 6 7 8 9 10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatNotify(tt.message, tt.args)
+			if got != tt.want {
+				t.Errorf("formatNotify(%q, %v) = %q, want %q", tt.message, tt.args, got, tt.want)
+			}
+			if strings.Contains(got, "%!") {
+				t.Errorf("formatNotify(%q, %v) produced fmt error noise %q", tt.message, tt.args, got)
+			}
+		})
+	}
+}
+
+func TestNotifyMismatchedArgs(t *testing.T) {
+	ring := useGlobalRing(t)
+	Notify("Error", "retrying %s", "orders", 3)
+	Notify("Error", "lost connection", "db-1")
+
+	entries := ring.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	for i, want := range []string{"retrying orders 3", "lost connection db-1"} {
+		if entries[i].Message != want {
+			t.Errorf("entry %d = %q, want %q", i, entries[i].Message, want)
+		}
+	}
+}
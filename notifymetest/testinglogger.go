@@ -0,0 +1,46 @@
+// Package notifymetest provides helpers for using notifyme loggers in
+// tests. It is separate from the core package so programs importing
+// notifyme do not link the testing package.
+package notifymetest
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	notifyme "github.com/AmosSParker/NotifyMe"
+)
+
+// testingWriter passes lines to tb.Log until the test has finished
+type testingWriter struct {
+	tb   testing.TB
+	mu   sync.Mutex
+	done bool
+}
+
+// NewTestingLogger creates a logger that writes through tb.Log, so output
+// is attributed to the test and only shown when it fails or with -v.
+// Entries logged after the test has completed, e.g. from a goroutine that
+// outlived it, are discarded instead of making tb.Log panic.
+func NewTestingLogger(tb testing.TB, level int) *notifyme.Logger {
+	w := &testingWriter{tb: tb}
+	tb.Cleanup(w.finish)
+	return notifyme.NewWriterLogger(level, w)
+}
+
+// Write logs p without its trailing newline
+func (w *testingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.done {
+		w.tb.Log(strings.TrimSuffix(string(p), "\n"))
+	}
+	return len(p), nil
+}
+
+// finish stops further writes once the test is done
+func (w *testingWriter) finish() {
+	w.mu.Lock()
+	w.done = true
+	w.mu.Unlock()
+}
@@ -0,0 +1,89 @@
+package notifymetest_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	notifyme "github.com/AmosSParker/NotifyMe"
+	"github.com/AmosSParker/NotifyMe/notifymetest"
+)
+
+// recordingTB captures what a logger passes to Log and runs the cleanups
+// when the fake test finishes
+type recordingTB struct {
+	testing.TB
+	mu       sync.Mutex
+	lines    []string
+	cleanups []func()
+}
+
+func (tb *recordingTB) Log(args ...interface{}) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	for _, arg := range args {
+		tb.lines = append(tb.lines, arg.(string))
+	}
+}
+
+func (tb *recordingTB) Cleanup(f func()) {
+	tb.cleanups = append(tb.cleanups, f)
+}
+
+func (tb *recordingTB) finish() {
+	for i := len(tb.cleanups) - 1; i >= 0; i-- {
+		tb.cleanups[i]()
+	}
+}
+
+// Each test gets its own logger, so its entries are reported with that
+// test only, and only when it fails or go test runs with -v
+func TestNewTestingLoggerPerTest(t *testing.T) {
+	for _, name := range []string{"checkout", "refund"} {
+		t.Run(name, func(t *testing.T) {
+			logger := notifymetest.NewTestingLogger(t, notifyme.LevelInfo)
+			logger.Log(notifyme.LevelInfo, "handling "+name, "order", 42)
+		})
+	}
+}
+
+func TestNewTestingLogger(t *testing.T) {
+	tests := []struct {
+		name   string
+		level  int
+		logged []int
+		want   []string
+	}{
+		{"all levels", notifyme.LevelInfo, []int{notifyme.LevelInfo, notifyme.LevelError}, []string{"INFO: ", "ERROR: "}},
+		{"filtered", notifyme.LevelWarn, []int{notifyme.LevelInfo, notifyme.LevelCritical}, []string{"CRITICAL: "}},
+		{"nothing", notifyme.LevelError, []int{notifyme.LevelInfo}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &recordingTB{TB: t}
+			logger := notifymetest.NewTestingLogger(tb, tt.level)
+			for _, level := range tt.logged {
+				logger.Log(level, "entry")
+			}
+			if len(tb.lines) != len(tt.want) {
+				t.Fatalf("got lines %q, want %d", tb.lines, len(tt.want))
+			}
+			for i, line := range tb.lines {
+				if !strings.HasPrefix(line, tt.want[i]) || !strings.HasSuffix(line, "entry") {
+					t.Errorf("line %d = %q, want prefix %q and no trailing newline", i, line, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNewTestingLoggerAfterTest(t *testing.T) {
+	tb := &recordingTB{TB: t}
+	logger := notifymetest.NewTestingLogger(tb, notifyme.LevelInfo)
+	logger.Log(notifyme.LevelInfo, "during")
+	tb.finish()
+	logger.Log(notifyme.LevelInfo, "after")
+	if len(tb.lines) != 1 || !strings.Contains(tb.lines[0], "during") {
+		t.Errorf("lines = %q, want only the entry logged during the test", tb.lines)
+	}
+}
@@ -0,0 +1,84 @@
+package notifyme
+
+import (
+	"io"
+	"strings"
+	"time"
+)
+
+// loggerOptions holds the plain settings controlled by options. It is
+// copied by value when a logger is cloned.
+type loggerOptions struct {
+	maxMessageLength   int
+	precision          Precision
+	bytesEncoding      BytesEncoding
+	compactLevels      bool
+	sinkConcurrency    int
+	sinkQueueSize      int
+	sinkOverflow       OverflowPolicy
+	sinkTimeout        time.Duration
+	newlineReplacer    *strings.Replacer
+	severities         map[int]int
+	maxDepth           int
+	errorHandler       func(error)
+	fallback           io.Writer
+	format             Format
+	jsonKeys           jsonKeys
+	sanitizeControl    bool
+	callerFunction     bool
+	durationFormat     DurationFormat
+	epochTime          bool
+	epochPrecision     Precision
+	translator         func(msgKey string) string
+	flushOnLevel       bool
+	flushLevel         int
+	stackTrace         bool
+	stackLevel         int
+	stackFrames        int
+	location           *time.Location
+	notifyDefault      bool
+	notifyLevel        int
+	maxFields          int
+	textTemplate       []templatePart
+	redactPatterns     []redactPattern
+	validator          func(Entry) error
+	validationAction   ValidationAction
+	fieldsCapacity     int
+	contextFloor       int
+	writeDeadline      time.Duration
+	writeSlot          chan struct{}
+	callerTrim         string
+	backpressureWindow time.Duration
+	backpressureFn     func(old, new BackpressureState)
+	syslogFacility     SyslogFacility
+	syslogFacilitySet  bool
+	syslogAppName      string
+	msgID              func() string
+	textTimeLayout     string
+	jsonTimeLayout     string
+	escapeNonASCII     bool
+	stackDedup         time.Duration
+	samplingExempt     map[int]bool
+	reorderWindow      time.Duration
+	flushLevels        map[int]bool
+	alertWindow        time.Duration
+	clock              Clock
+	numericLevel       bool
+	prettyJSON         bool
+}
+
+// Option configures optional behaviour of a Logger
+type Option func(*Logger) error
+
+// Configure applies the given options to the logger in order, stopping at
+// the first one that fails
+func (l *Logger) Configure(opts ...Option) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, opt := range opts {
+		if err := opt(l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
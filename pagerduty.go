@@ -0,0 +1,200 @@
+package notifyme
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// defaultPagerDutyEndpoint is the Events API v2 enqueue URL
+const defaultPagerDutyEndpoint = "https://events.pagerduty.com/v2/enqueue"
+
+// maxPagerDutySummary is the longest summary the Events API accepts
+const maxPagerDutySummary = 1024
+
+// pagerDutyDedupKeyField lets an entry choose its own dedup key
+const pagerDutyDedupKeyField = "dedup_key"
+
+// defaultPagerDutyTimeout bounds each request of the default HTTP client,
+// so an unresponsive endpoint cannot block logging indefinitely
+const defaultPagerDutyTimeout = 10 * time.Second
+
+// PagerDutyConfig configures a PagerDutySink
+type PagerDutyConfig struct {
+	// RoutingKey is the integration key of the PagerDuty service
+	RoutingKey string
+	// MinLevel is the lowest level that triggers an incident. Nil defaults
+	// to LevelCritical.
+	MinLevel *int
+	// Source identifies the affected system. Defaults to the hostname.
+	Source string
+	// Endpoint is the Events API URL. Defaults to the public v2 endpoint.
+	Endpoint string
+	// Client is the HTTP client used for requests. Defaults to a client
+	// with a 10s timeout; a custom client should set its own timeout, as
+	// Write blocks until the request completes.
+	Client *http.Client
+}
+
+// PagerDutySink triggers PagerDuty incidents through the Events API v2.
+// Each event carries a dedup key derived from the entry, so repeats of the
+// same alert update one incident instead of opening new ones.
+type PagerDutySink struct {
+	config   PagerDutyConfig
+	minLevel int
+}
+
+// pagerDutyEvent is an Events API v2 request body
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+// pagerDutyPayload describes the alert of a trigger event
+type pagerDutyPayload struct {
+	Summary       string                 `json:"summary"`
+	Source        string                 `json:"source"`
+	Severity      string                 `json:"severity"`
+	Timestamp     string                 `json:"timestamp"`
+	Component     string                 `json:"component,omitempty"`
+	CustomDetails map[string]interface{} `json:"custom_details,omitempty"`
+}
+
+// NewPagerDutySink creates a PagerDutySink
+func NewPagerDutySink(config PagerDutyConfig) (*PagerDutySink, error) {
+	if config.RoutingKey == "" {
+		return nil, errors.New("notifyme: pagerduty routing key must not be empty")
+	}
+	if config.Source == "" {
+		config.Source, _ = os.Hostname()
+	}
+	if config.Source == "" {
+		config.Source = "unknown"
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = defaultPagerDutyEndpoint
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: defaultPagerDutyTimeout}
+	}
+	minLevel := LevelCritical
+	if config.MinLevel != nil {
+		minLevel = *config.MinLevel
+	}
+	return &PagerDutySink{config: config, minLevel: minLevel}, nil
+}
+
+// Write sends a trigger event for entries at or above the minimum level
+func (s *PagerDutySink) Write(entry Entry) error {
+	if entry.Level < s.minLevel {
+		return nil
+	}
+	summary := entry.Message
+	if len(summary) > maxPagerDutySummary {
+		summary = summary[:truncateUTF8(summary, maxPagerDutySummary)]
+	}
+	payload := &pagerDutyPayload{
+		Summary:   summary,
+		Source:    s.config.Source,
+		Severity:  pagerDutySeverity(entry.Level),
+		Timestamp: entry.Time.UTC().Format(time.RFC3339Nano),
+		Component: entry.Name,
+	}
+	payload.CustomDetails = make(map[string]interface{}, len(entry.Fields)+1)
+	for _, field := range entry.Fields {
+		payload.CustomDetails[field.Key] = defaultJSONValue(field.Value)
+	}
+	payload.CustomDetails["caller"] = entry.Caller.String()
+	return s.send(pagerDutyEvent{
+		RoutingKey:  s.config.RoutingKey,
+		EventAction: "trigger",
+		DedupKey:    s.DedupKey(entry),
+		Payload:     payload,
+	})
+}
+
+// Resolve sends a resolve event for the incident with the given dedup key,
+// as returned by DedupKey for the entry that triggered it
+func (s *PagerDutySink) Resolve(dedupKey string) error {
+	if dedupKey == "" {
+		return errors.New("notifyme: pagerduty dedup key must not be empty")
+	}
+	return s.send(pagerDutyEvent{
+		RoutingKey:  s.config.RoutingKey,
+		EventAction: "resolve",
+		DedupKey:    dedupKey,
+	})
+}
+
+// DedupKey returns the dedup key events for the entry are sent with: the
+// entry's dedup_key field if it is a string, otherwise a hash of the
+// logger name, level, caller and message, which stays the same across
+// repeats of the same alert
+func (s *PagerDutySink) DedupKey(entry Entry) string {
+	for _, field := range entry.Fields {
+		if key, ok := field.Value.(string); ok && field.Key == pagerDutyDedupKeyField && key != "" {
+			return key
+		}
+	}
+	return "notifyme-" + entryFingerprint(entry)
+}
+
+// entryFingerprint hashes the logger name, level, caller and message of an
+// entry, which stay the same across repeats of the same event
+func entryFingerprint(entry Entry) string {
+	h := sha256.New()
+	for _, part := range []string{entry.Name, levelName(entry.Level), entry.Caller.String(), entry.Message} {
+		io.WriteString(h, part)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// Close does nothing; events are sent synchronously by Write
+func (s *PagerDutySink) Close() error {
+	return nil
+}
+
+// send posts an event to the Events API
+func (s *PagerDutySink) send(event pagerDutyEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	resp, err := s.config.Client.Post(s.config.Endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("notifyme: pagerduty request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("notifyme: reading pagerduty response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("notifyme: pagerduty request returned %s: %s", resp.Status, respBody)
+	}
+	return nil
+}
+
+// pagerDutySeverity returns the Events API severity of a level
+func pagerDutySeverity(level int) string {
+	switch level {
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warning"
+	case LevelError:
+		return "error"
+	default:
+		return "critical"
+	}
+}
@@ -0,0 +1,221 @@
+package notifyme
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// pagerDutyServer records the events posted to it
+type pagerDutyServer struct {
+	*httptest.Server
+	mu     sync.Mutex
+	events []pagerDutyEvent
+}
+
+func newPagerDutyServer(t *testing.T, status int) *pagerDutyServer {
+	s := &pagerDutyServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event pagerDutyEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("decoding event: %v", err)
+		}
+		s.mu.Lock()
+		s.events = append(s.events, event)
+		s.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newTestPagerDutySink(t *testing.T, s *pagerDutyServer, minLevel *int) *PagerDutySink {
+	sink, err := NewPagerDutySink(PagerDutyConfig{
+		RoutingKey: "key",
+		MinLevel:   minLevel,
+		Source:     "host",
+		Endpoint:   s.URL,
+		Client:     s.Client(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sink
+}
+
+func TestPagerDutyTrigger(t *testing.T) {
+	server := newPagerDutyServer(t, http.StatusAccepted)
+	sink := newTestPagerDutySink(t, server, nil)
+	entry := Entry{
+		Level:   LevelCritical,
+		Message: "disk full",
+		Time:    time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600)),
+		Caller:  Caller{File: "disk.go", Line: 12},
+		Name:    "storage",
+		Fields:  []Field{{Key: "volume", Value: "/data"}, {Key: "free", Value: 0}},
+	}
+	if err := sink.Write(entry); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(server.events) != 1 {
+		t.Fatalf("got %d events, want 1", len(server.events))
+	}
+	event := server.events[0]
+	if event.RoutingKey != "key" || event.EventAction != "trigger" || event.DedupKey != sink.DedupKey(entry) {
+		t.Errorf("event = %+v", event)
+	}
+	p := event.Payload
+	if p.Summary != "disk full" || p.Source != "host" || p.Severity != "critical" ||
+		p.Timestamp != "2024-05-01T11:00:00Z" || p.Component != "storage" {
+		t.Errorf("payload = %+v", p)
+	}
+	want := map[string]interface{}{"volume": "/data", "free": float64(0), "caller": "disk.go:12"}
+	for key, value := range want {
+		if p.CustomDetails[key] != value {
+			t.Errorf("custom_details[%q] = %v, want %v", key, p.CustomDetails[key], value)
+		}
+	}
+}
+
+func TestPagerDutyMinLevel(t *testing.T) {
+	info, warn := LevelInfo, LevelWarn
+	tests := []struct {
+		name     string
+		minLevel *int
+		level    int
+		want     int
+	}{
+		{"default skips error", nil, LevelError, 0},
+		{"default sends critical", nil, LevelCritical, 1},
+		{"warn sends warn", &warn, LevelWarn, 1},
+		{"warn skips info", &warn, LevelInfo, 0},
+		{"info sends info", &info, LevelInfo, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newPagerDutyServer(t, http.StatusAccepted)
+			sink := newTestPagerDutySink(t, server, tt.minLevel)
+			if err := sink.Write(Entry{Level: tt.level, Message: "m"}); err != nil {
+				t.Fatal(err)
+			}
+			if len(server.events) != tt.want {
+				t.Errorf("got %d events, want %d", len(server.events), tt.want)
+			}
+		})
+	}
+}
+
+func TestPagerDutyDedupKey(t *testing.T) {
+	sink, err := NewPagerDutySink(PagerDutyConfig{RoutingKey: "key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := Entry{Level: LevelCritical, Message: "disk full", Caller: Caller{File: "disk.go", Line: 12}, Name: "storage"}
+	key := sink.DedupKey(base)
+	if !strings.HasPrefix(key, "notifyme-") {
+		t.Errorf("dedup key %q lacks the notifyme- prefix", key)
+	}
+
+	tests := []struct {
+		name   string
+		change func(Entry) Entry
+		same   bool
+	}{
+		{"later repeat", func(e Entry) Entry { e.Time = time.Now(); return e }, true},
+		{"other fields", func(e Entry) Entry { e.Fields = []Field{{Key: "free", Value: 1}}; return e }, true},
+		{"other message", func(e Entry) Entry { e.Message = "disk almost full"; return e }, false},
+		{"other caller", func(e Entry) Entry { e.Caller.Line = 13; return e }, false},
+		{"other level", func(e Entry) Entry { e.Level = LevelError; return e }, false},
+		{"other name", func(e Entry) Entry { e.Name = "db"; return e }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sink.DedupKey(tt.change(base)); (got == key) != tt.same {
+				t.Errorf("dedup key %q compared to %q: same = %v, want %v", got, key, got == key, tt.same)
+			}
+		})
+	}
+
+	base.Fields = []Field{{Key: pagerDutyDedupKeyField, Value: "disk-/data"}}
+	if got := sink.DedupKey(base); got != "disk-/data" {
+		t.Errorf("dedup key from field = %q, want disk-/data", got)
+	}
+}
+
+func TestPagerDutyCustomDetailsValues(t *testing.T) {
+	server := newPagerDutyServer(t, http.StatusAccepted)
+	sink := newTestPagerDutySink(t, server, nil)
+	entry := Entry{
+		Level:   LevelCritical,
+		Message: "odd values",
+		Fields: []Field{
+			{Key: "err", Value: errors.New("boom")},
+			{Key: "nan", Value: math.NaN()},
+			{Key: "fn", Value: func() {}},
+			{Key: "wait", Value: 1500 * time.Millisecond},
+		},
+	}
+	if err := sink.Write(entry); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if len(server.events) != 1 {
+		t.Fatalf("got %d events, want 1", len(server.events))
+	}
+	details := server.events[0].Payload.CustomDetails
+	if details["err"] != "boom" || details["nan"] != "NaN" || details["wait"] != "1.5s" {
+		t.Errorf("custom_details = %v", details)
+	}
+	if _, ok := details["fn"].(string); !ok {
+		t.Errorf("custom_details[fn] = %v, want its text form", details["fn"])
+	}
+}
+
+func TestPagerDutyResolve(t *testing.T) {
+	server := newPagerDutyServer(t, http.StatusAccepted)
+	sink := newTestPagerDutySink(t, server, nil)
+	if err := sink.Resolve(""); err == nil {
+		t.Error("Resolve accepted an empty dedup key")
+	}
+	if err := sink.Resolve("notifyme-abc"); err != nil {
+		t.Fatal(err)
+	}
+	if len(server.events) != 1 {
+		t.Fatalf("got %d events, want 1", len(server.events))
+	}
+	event := server.events[0]
+	if event.EventAction != "resolve" || event.DedupKey != "notifyme-abc" || event.Payload != nil {
+		t.Errorf("resolve event = %+v", event)
+	}
+}
+
+func TestPagerDutyErrorStatus(t *testing.T) {
+	server := newPagerDutyServer(t, http.StatusBadRequest)
+	sink := newTestPagerDutySink(t, server, nil)
+	err := sink.Write(Entry{Level: LevelCritical, Message: "m"})
+	if err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("Write error = %v, want the 400 status", err)
+	}
+}
+
+func TestNewPagerDutySinkRequiresRoutingKey(t *testing.T) {
+	if _, err := NewPagerDutySink(PagerDutyConfig{}); err == nil {
+		t.Error("NewPagerDutySink accepted an empty routing key")
+	}
+}
+
+func TestNewPagerDutySinkDefaultClientTimeout(t *testing.T) {
+	sink, err := NewPagerDutySink(PagerDutyConfig{RoutingKey: "key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sink.config.Client == http.DefaultClient || sink.config.Client.Timeout != defaultPagerDutyTimeout {
+		t.Errorf("default client timeout = %v, want %v", sink.config.Client.Timeout, defaultPagerDutyTimeout)
+	}
+}
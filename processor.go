@@ -0,0 +1,26 @@
+package notifyme
+
+// AddProcessor registers fn to modify entries before they are written, for
+// example to add a computed field. Processors run in registration order on
+// every entry that passed the level, sampling and rate-limit filters, after
+// redaction and message truncation and before entry validation, so fields
+// a processor adds are validated but not redacted. Every output and sink
+// sees the processed entry. Processors run with the logger mutex held and
+// must not log through the same logger. They are copied to clones.
+func (l *Logger) AddProcessor(fn func(*Entry)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.processors = append(l.processors, fn)
+}
+
+// process runs the registered processors on the entry, keeping field keys
+// unique afterwards. It must be called with the logger mutex held.
+func (l *Logger) process(entry *Entry) {
+	if len(l.processors) == 0 {
+		return
+	}
+	for _, fn := range l.processors {
+		fn(entry)
+	}
+	entry.Fields = dedupeFields(entry.Fields)
+}
@@ -0,0 +1,121 @@
+package notifyme
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// addRegion is a processor adding a computed field
+func addRegion(entry *Entry) {
+	entry.Fields = append(entry.Fields, Field{Key: "region", Value: "eu-" + strings.ToLower(levelName(entry.Level))})
+}
+
+func TestAddProcessor(t *testing.T) {
+	tests := []struct {
+		name       string
+		processors []func(*Entry)
+		log        func(*Logger)
+		want       []Field
+	}{
+		{"adds a field", []func(*Entry){addRegion},
+			func(l *Logger) { l.Log(LevelWarn, "slow") },
+			[]Field{{Key: "region", Value: "eu-warn"}}},
+		{"runs on events", []func(*Entry){addRegion},
+			func(l *Logger) { l.NewEvent(LevelError).Int("ms", 900).Msg("slow") },
+			[]Field{{Key: "ms", Value: 900}, {Key: "region", Value: "eu-error"}}},
+		{"registration order", []func(*Entry){
+			addRegion,
+			func(e *Entry) {
+				region, _ := lastField(e.Fields, "region")
+				e.Fields = append(e.Fields, Field{Key: "zone", Value: region.(string) + "-1a"})
+			},
+		}, func(l *Logger) { l.Log(LevelInfo, "slow") },
+			[]Field{{Key: "region", Value: "eu-info"}, {Key: "zone", Value: "eu-info-1a"}}},
+		{"overrides a field once", []func(*Entry){func(e *Entry) {
+			e.Fields = append(e.Fields, Field{Key: "user", Value: "anonymous"})
+		}}, func(l *Logger) { l.With("user", "ann").Log(LevelInfo, "slow") },
+			[]Field{{Key: "user", Value: "anonymous"}}},
+		{"changes the message", []func(*Entry){func(e *Entry) { e.Message = "[api] " + e.Message }},
+			func(l *Logger) { l.Log(LevelInfo, "slow") }, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newWriterLogger(LevelInfo, &buf)
+			ring, _ := NewRingSink(1)
+			logger.AddSink(ring)
+			for _, fn := range tt.processors {
+				logger.AddProcessor(fn)
+			}
+			tt.log(logger)
+
+			entry := ring.Entries()[0]
+			if !reflect.DeepEqual(entry.Fields, tt.want) {
+				t.Errorf("sink fields = %v, want %v", entry.Fields, tt.want)
+			}
+			for _, field := range tt.want {
+				if kv := field.Key + "=" + logger.formatValue(field.Value); !strings.Contains(buf.String(), kv) {
+					t.Errorf("output %q lacks %s", buf.String(), kv)
+				}
+			}
+			if !strings.Contains(buf.String(), "] "+entry.Message) {
+				t.Errorf("output %q differs from the sink's message %q", buf.String(), entry.Message)
+			}
+		})
+	}
+}
+
+func TestAddProcessorJSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newWriterLogger(LevelInfo, &buf)
+	if err := logger.Configure(WithFormat(FormatJSON)); err != nil {
+		t.Fatal(err)
+	}
+	logger.AddProcessor(addRegion)
+	logger.NewEvent(LevelInfo).Str("user", "ann").Msg("login")
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["region"] != "eu-info" || doc["user"] != "ann" {
+		t.Errorf("document = %v, want region and user", doc)
+	}
+}
+
+func TestAddProcessorOrdering(t *testing.T) {
+	logger := newWriterLogger(LevelWarn, &bytes.Buffer{})
+	ring, _ := NewRingSink(10)
+	logger.AddSink(ring)
+	if err := logger.Configure(WithRedactPattern(emailPattern, "[email]")); err != nil {
+		t.Fatal(err)
+	}
+	var seen []string
+	logger.AddProcessor(func(e *Entry) {
+		seen = append(seen, e.Message)
+		e.Fields = append(e.Fields, Field{Key: "owner", Value: "ops@example.com"})
+	})
+	logger.Log(LevelInfo, "filtered out")
+	logger.Log(LevelWarn, "mail to ann@example.com failed")
+
+	if want := []string{"mail to [email] failed"}; !reflect.DeepEqual(seen, want) {
+		t.Errorf("processor saw %q, want %q: filtered entries skipped, redaction first", seen, want)
+	}
+	if owner, _ := lastField(ring.Entries()[0].Fields, "owner"); owner != "ops@example.com" {
+		t.Errorf("owner = %v, want the processor's field left unredacted", owner)
+	}
+}
+
+func TestAddProcessorCopiedToClones(t *testing.T) {
+	base := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	ring, _ := NewRingSink(1)
+	base.AddSink(ring)
+	base.AddProcessor(addRegion)
+	base.With("user", "ann").Log(LevelInfo, "child")
+	if region, ok := lastField(ring.Entries()[0].Fields, "region"); !ok || region != "eu-info" {
+		t.Errorf("child entry fields = %v, want the base's processor applied", ring.Entries()[0].Fields)
+	}
+}
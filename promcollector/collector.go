@@ -0,0 +1,68 @@
+// Package promcollector exposes logging activity of a notifyme.Logger as
+// Prometheus metrics. It lives in its own module so the core package does
+// not depend on the Prometheus client.
+//
+// The collector is a sink, so it sees every entry that passes the logger's
+// level and sampling:
+//
+//	collector := promcollector.New("myapp")
+//	logger.AddSink(collector)
+//	prometheus.MustRegister(collector)
+package promcollector
+
+import (
+	notifyme "github.com/AmosSParker/NotifyMe"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector counts log entries by level. It implements both notifyme.Sink
+// and prometheus.Collector.
+type Collector struct {
+	entries    *prometheus.CounterVec
+	suppressed *prometheus.CounterVec
+}
+
+// New creates a collector whose metrics are prefixed with namespace, which
+// may be empty
+func New(namespace string) *Collector {
+	return &Collector{
+		entries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "log_entries_total",
+			Help:      "Number of log entries written, by level.",
+		}, []string{"level"}),
+		suppressed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "log_entries_suppressed_total",
+			Help:      "Number of log entries dropped by sampling, by level.",
+		}, []string{"level"}),
+	}
+}
+
+// Write counts the entry. Entries standing in for siblings dropped by
+// sampling add those to the suppressed count.
+func (c *Collector) Write(entry notifyme.Entry) error {
+	level := notifyme.LevelName(entry.Level)
+	c.entries.WithLabelValues(level).Inc()
+	if entry.TimesSeen > 1 {
+		c.suppressed.WithLabelValues(level).Add(float64(entry.TimesSeen - 1))
+	}
+	return nil
+}
+
+// Close implements notifyme.Sink; the counters stay available
+func (c *Collector) Close() error {
+	return nil
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.entries.Describe(ch)
+	c.suppressed.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.entries.Collect(ch)
+	c.suppressed.Collect(ch)
+}
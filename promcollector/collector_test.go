@@ -0,0 +1,127 @@
+package promcollector_test
+
+import (
+	"io"
+	"sort"
+	"testing"
+	"time"
+
+	notifyme "github.com/AmosSParker/NotifyMe"
+	"github.com/AmosSParker/NotifyMe/promcollector"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// manualClock is a notifyme.Clock that only moves when told to
+type manualClock struct {
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	return c.now
+}
+
+func (c *manualClock) NewTicker(time.Duration) notifyme.Ticker {
+	panic("tickers are not used by these tests")
+}
+
+func TestCollector(t *testing.T) {
+	clock := &manualClock{now: time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC)}
+	logger := notifyme.NewWriterLogger(notifyme.LevelInfo, io.Discard)
+	defer logger.Close()
+	collector := promcollector.New("app")
+	logger.AddSink(collector)
+	err := logger.Configure(
+		notifyme.WithClock(clock),
+		notifyme.WithSamplingByKey(func(e notifyme.Entry) string { return e.Message }, 1),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(collector); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		logger.Log(notifyme.LevelInfo, "request")
+	}
+	clock.now = clock.now.Add(time.Second)
+	logger.Log(notifyme.LevelInfo, "request")
+	logger.Log(notifyme.LevelWarn, "slow")
+	logger.Log(notifyme.LevelError, "failed")
+	logger.Log(notifyme.LevelError, "failed")
+	logger.Log(notifyme.LevelCritical, "down")
+	logger.SetLevel(notifyme.LevelWarn)
+	logger.Log(notifyme.LevelInfo, "filtered")
+
+	got := gather(t, registry)
+	want := map[string]map[string]float64{
+		"app_log_entries_total": {
+			"INFO":     2,
+			"WARN":     1,
+			"ERROR":    2,
+			"CRITICAL": 1,
+		},
+		"app_log_entries_suppressed_total": {
+			"INFO": 2,
+		},
+	}
+	if len(got) != len(want) {
+		t.Errorf("got metric families %v, want %v", names(got), names(want))
+	}
+	for family, levels := range want {
+		for level, value := range levels {
+			if got[family][level] != value {
+				t.Errorf("%s{level=%q} = %v, want %v", family, level, got[family][level], value)
+			}
+		}
+		if len(got[family]) != len(levels) {
+			t.Errorf("%s has levels %v, want %v", family, got[family], levels)
+		}
+	}
+}
+
+func TestCollectorWithoutNamespace(t *testing.T) {
+	collector := promcollector.New("")
+	collector.Write(notifyme.Entry{Level: notifyme.LevelWarn, Message: "x"})
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+	got := gather(t, registry)
+	if got["log_entries_total"]["WARN"] != 1 {
+		t.Errorf("got %v, want log_entries_total{level=\"WARN\"} = 1", got)
+	}
+}
+
+// gather returns the counter values of every metric family by level label
+func gather(t *testing.T, registry *prometheus.Registry) map[string]map[string]float64 {
+	t.Helper()
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := make(map[string]map[string]float64)
+	for _, family := range families {
+		levels := make(map[string]float64)
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "level" {
+					levels[label.GetValue()] = metric.GetCounter().GetValue()
+				}
+			}
+		}
+		values[family.GetName()] = levels
+	}
+	return values
+}
+
+// names returns the sorted metric family names
+func names(families map[string]map[string]float64) []string {
+	list := make([]string, 0, len(families))
+	for name := range families {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,231 @@
+package notifyme
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Field numbers of the Entry message in entry.proto
+const (
+	protoLevel          = 1
+	protoMessage        = 2
+	protoTime           = 3
+	protoCallerFile     = 4
+	protoCallerLine     = 5
+	protoCallerFunction = 6
+	protoFields         = 7
+	protoEventTime      = 8
+	protoSeverity       = 9
+	protoName           = 10
+	protoTimesSeen      = 11
+)
+
+// Field numbers of the Field message in entry.proto
+const (
+	protoFieldKey   = 1
+	protoFieldValue = 2
+)
+
+// Protobuf wire types used by the schema
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// maxProtoFrame bounds the size of a frame accepted by ProtoReader so a
+// corrupt length prefix cannot trigger a huge allocation
+const maxProtoFrame = 64 << 20
+
+// formatProto renders an entry as a length-delimited protobuf message
+// following entry.proto. Field values are encoded as their text rendering.
+// It must be called with the logger mutex held.
+func (l *Logger) formatProto(entry Entry) ([]byte, error) {
+	var msg []byte
+	msg = appendProtoVarint(msg, protoLevel, uint64(int64(entry.Level)))
+	msg = appendProtoString(msg, protoMessage, entry.Message)
+	if !entry.Time.IsZero() {
+		msg = appendProtoVarint(msg, protoTime, uint64(entry.Time.UnixNano()))
+	}
+	msg = appendProtoString(msg, protoCallerFile, entry.Caller.File)
+	msg = appendProtoVarint(msg, protoCallerLine, uint64(int64(entry.Caller.Line)))
+	msg = appendProtoString(msg, protoCallerFunction, entry.Caller.Function)
+	for _, field := range entry.Fields {
+		var sub []byte
+		sub = appendProtoString(sub, protoFieldKey, field.Key)
+		sub = appendProtoString(sub, protoFieldValue, l.formatValue(field.Value))
+		msg = appendProtoBytes(msg, protoFields, sub)
+	}
+	if !entry.EventTime.IsZero() {
+		msg = appendProtoVarint(msg, protoEventTime, uint64(entry.EventTime.UnixNano()))
+	}
+	msg = appendProtoVarint(msg, protoSeverity, uint64(int64(entry.Severity)))
+	msg = appendProtoString(msg, protoName, entry.Name)
+	msg = appendProtoVarint(msg, protoTimesSeen, uint64(int64(entry.TimesSeen)))
+
+	frame := binary.AppendUvarint(make([]byte, 0, len(msg)+binary.MaxVarintLen32), uint64(len(msg)))
+	return append(frame, msg...), nil
+}
+
+// appendProtoVarint appends a varint field, omitting zero values as proto3
+// does
+func appendProtoVarint(b []byte, num int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = binary.AppendUvarint(b, uint64(num)<<3|wireVarint)
+	return binary.AppendUvarint(b, v)
+}
+
+// appendProtoString appends a string field, omitting empty strings
+func appendProtoString(b []byte, num int, s string) []byte {
+	if s == "" {
+		return b
+	}
+	return appendProtoBytes(b, num, []byte(s))
+}
+
+// appendProtoBytes appends a length-delimited field
+func appendProtoBytes(b []byte, num int, v []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(num)<<3|wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// ProtoReader decodes a stream written with FormatProto back into entries.
+// Field values come back as the strings they were rendered to.
+type ProtoReader struct {
+	r *bufio.Reader
+}
+
+// NewProtoReader returns a reader decoding entries from r
+func NewProtoReader(r io.Reader) *ProtoReader {
+	return &ProtoReader{r: bufio.NewReader(r)}
+}
+
+// Next decodes the next entry. It returns io.EOF at the end of the stream
+// and io.ErrUnexpectedEOF if the stream ends inside a frame.
+func (p *ProtoReader) Next() (Entry, error) {
+	size, err := binary.ReadUvarint(p.r)
+	if err != nil {
+		return Entry{}, err
+	}
+	if size > maxProtoFrame {
+		return Entry{}, fmt.Errorf("notifyme: protobuf frame of %d bytes is too large", size)
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(p.r, msg); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return Entry{}, err
+	}
+	return decodeProtoEntry(msg)
+}
+
+// decodeProtoEntry decodes a single Entry message, skipping unknown fields
+func decodeProtoEntry(msg []byte) (Entry, error) {
+	var entry Entry
+	err := walkProto(msg, func(num int, v uint64, data []byte) error {
+		switch num {
+		case protoLevel:
+			entry.Level = int(int64(v))
+		case protoMessage:
+			entry.Message = string(data)
+		case protoTime:
+			entry.Time = time.Unix(0, int64(v))
+		case protoCallerFile:
+			entry.Caller.File = string(data)
+		case protoCallerLine:
+			entry.Caller.Line = int(int64(v))
+		case protoCallerFunction:
+			entry.Caller.Function = string(data)
+		case protoFields:
+			field, err := decodeProtoField(data)
+			if err != nil {
+				return err
+			}
+			entry.Fields = append(entry.Fields, field)
+		case protoEventTime:
+			entry.EventTime = time.Unix(0, int64(v))
+		case protoSeverity:
+			entry.Severity = int(int64(v))
+		case protoName:
+			entry.Name = string(data)
+		case protoTimesSeen:
+			entry.TimesSeen = int(int64(v))
+		}
+		return nil
+	})
+	return entry, err
+}
+
+// decodeProtoField decodes a single Field message
+func decodeProtoField(msg []byte) (Field, error) {
+	var field Field
+	var value string
+	err := walkProto(msg, func(num int, v uint64, data []byte) error {
+		switch num {
+		case protoFieldKey:
+			field.Key = string(data)
+		case protoFieldValue:
+			value = string(data)
+		}
+		return nil
+	})
+	field.Value = value
+	return field, err
+}
+
+// walkProto calls fn for every field of a message. Varint and fixed-size
+// fields are passed in v, length-delimited ones in data.
+func walkProto(msg []byte, fn func(num int, v uint64, data []byte) error) error {
+	for len(msg) > 0 {
+		tag, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return errors.New("notifyme: malformed protobuf tag")
+		}
+		msg = msg[n:]
+		num, wire := int(tag>>3), int(tag&7)
+		var v uint64
+		var data []byte
+		switch wire {
+		case wireVarint:
+			v, n = binary.Uvarint(msg)
+			if n <= 0 {
+				return errors.New("notifyme: malformed protobuf varint")
+			}
+			msg = msg[n:]
+		case wireFixed64:
+			if len(msg) < 8 {
+				return io.ErrUnexpectedEOF
+			}
+			v = binary.LittleEndian.Uint64(msg)
+			msg = msg[8:]
+		case wireFixed32:
+			if len(msg) < 4 {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(binary.LittleEndian.Uint32(msg))
+			msg = msg[4:]
+		case wireBytes:
+			size, n := binary.Uvarint(msg)
+			if n <= 0 || size > uint64(len(msg)-n) {
+				return errors.New("notifyme: malformed protobuf length")
+			}
+			data = msg[n : n+int(size)]
+			msg = msg[n+int(size):]
+		default:
+			return fmt.Errorf("notifyme: unsupported protobuf wire type %d", wire)
+		}
+		if err := fn(num, v, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
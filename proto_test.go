@@ -0,0 +1,179 @@
+package notifyme
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestProtoRoundTrip(t *testing.T) {
+	at := time.Date(2024, 3, 9, 14, 5, 6, 123456789, time.UTC)
+	tests := []struct {
+		name  string
+		entry Entry
+		want  Entry
+	}{
+		{
+			"message only",
+			Entry{Level: LevelInfo, Message: "started"},
+			Entry{Level: LevelInfo, Message: "started"},
+		},
+		{
+			"every field",
+			Entry{
+				Level: LevelError, Message: "query failed", Time: at,
+				Caller:    Caller{File: "/src/app/db.go", Line: 42, Function: "app.Query"},
+				Fields:    []Field{{Key: "table", Value: "users"}, {Key: "attempt", Value: 2}, {Key: "took", Value: 1500 * time.Millisecond}},
+				EventTime: at.Add(-time.Hour), Severity: 3, Name: "db", TimesSeen: 7,
+			},
+			Entry{
+				Level: LevelError, Message: "query failed", Time: at,
+				Caller:    Caller{File: "/src/app/db.go", Line: 42, Function: "app.Query"},
+				Fields:    []Field{{Key: "table", Value: "users"}, {Key: "attempt", Value: "2"}, {Key: "took", Value: "1.5s"}},
+				EventTime: at.Add(-time.Hour), Severity: 3, Name: "db", TimesSeen: 7,
+			},
+		},
+		{
+			"negative level and empty field value",
+			Entry{Level: -4, Message: "custom", Fields: []Field{{Key: "empty", Value: ""}}},
+			Entry{Level: -4, Message: "custom", Fields: []Field{{Key: "empty", Value: ""}}},
+		},
+		{
+			"unicode",
+			Entry{Level: LevelWarn, Message: "héllo\n世界", Fields: []Field{{Key: "ключ", Value: "值"}}},
+			Entry{Level: LevelWarn, Message: "héllo\n世界", Fields: []Field{{Key: "ключ", Value: "值"}}},
+		},
+	}
+
+	var stream bytes.Buffer
+	for _, tt := range tests {
+		data, err := EncodeEntry(tt.entry, FormatProto)
+		if err != nil {
+			t.Fatalf("%s: EncodeEntry: %v", tt.name, err)
+		}
+		stream.Write(data)
+	}
+
+	reader := NewProtoReader(&stream)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := reader.Next()
+			if err != nil {
+				t.Fatalf("Next: %v", err)
+			}
+			assertProtoEntry(t, got, tt.want)
+		})
+	}
+	if _, err := reader.Next(); err != io.EOF {
+		t.Errorf("Next at end of stream = %v, want io.EOF", err)
+	}
+}
+
+// assertProtoEntry compares a decoded entry with want, comparing times by
+// instant since the decoder returns them in the local zone
+func assertProtoEntry(t *testing.T, got, want Entry) {
+	t.Helper()
+	if !got.Time.Equal(want.Time) {
+		t.Errorf("Time = %v, want %v", got.Time, want.Time)
+	}
+	if !got.EventTime.Equal(want.EventTime) {
+		t.Errorf("EventTime = %v, want %v", got.EventTime, want.EventTime)
+	}
+	got.Time, want.Time = time.Time{}, time.Time{}
+	got.EventTime, want.EventTime = time.Time{}, time.Time{}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decoded %#v\nwant    %#v", got, want)
+	}
+}
+
+func TestProtoLoggerOutput(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newEventTestLogger(t, &buf, WithFormat(FormatProto))
+	logger.Named("api").With("user", "ann").Log(LevelInfo, "first")
+	logger.NewEvent(LevelWarn).Int("n", 3).Bool("ok", false).Msg("second")
+
+	reader := NewProtoReader(&buf)
+	want := []struct {
+		level   int
+		message string
+		name    string
+		fields  []Field
+	}{
+		{LevelInfo, "first", "api", []Field{{Key: "user", Value: "ann"}}},
+		{LevelWarn, "second", "", []Field{{Key: "n", Value: "3"}, {Key: "ok", Value: "false"}}},
+	}
+	for _, w := range want {
+		got, err := reader.Next()
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		if got.Level != w.level || got.Message != w.message || got.Name != w.name {
+			t.Errorf("got level %d message %q name %q, want %d %q %q", got.Level, got.Message, got.Name, w.level, w.message, w.name)
+		}
+		if !reflect.DeepEqual(got.Fields, w.fields) {
+			t.Errorf("fields = %#v, want %#v", got.Fields, w.fields)
+		}
+		if !got.Time.Equal(fixedTime) {
+			t.Errorf("Time = %v, want %v", got.Time, fixedTime)
+		}
+		if got.Caller.File == "" || got.Caller.Line == 0 {
+			t.Errorf("caller not recorded: %+v", got.Caller)
+		}
+	}
+	if _, err := reader.Next(); err != io.EOF {
+		t.Errorf("Next at end of stream = %v, want io.EOF", err)
+	}
+}
+
+func TestProtoReaderSkipsUnknownFields(t *testing.T) {
+	var msg []byte
+	msg = appendProtoString(msg, protoMessage, "known")
+	msg = appendProtoVarint(msg, 99, 12345)
+	msg = appendProtoBytes(msg, 100, []byte("future"))
+	msg = binary.AppendUvarint(msg, 101<<3|wireFixed64)
+	msg = binary.LittleEndian.AppendUint64(msg, 1)
+	msg = binary.AppendUvarint(msg, 102<<3|wireFixed32)
+	msg = binary.LittleEndian.AppendUint32(msg, 1)
+	frame := append(binary.AppendUvarint(nil, uint64(len(msg))), msg...)
+
+	got, err := NewProtoReader(bytes.NewReader(frame)).Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if got.Message != "known" {
+		t.Errorf("Message = %q, want %q", got.Message, "known")
+	}
+}
+
+func TestProtoReaderErrors(t *testing.T) {
+	valid, err := EncodeEntry(Entry{Level: LevelInfo, Message: "hello"}, FormatProto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		stream  []byte
+		wantErr error
+	}{
+		{"truncated frame", valid[:len(valid)-2], io.ErrUnexpectedEOF},
+		{"length only", valid[:1], io.ErrUnexpectedEOF},
+		{"oversized frame", binary.AppendUvarint(nil, maxProtoFrame+1), nil},
+		{"malformed length", []byte{2, protoMessage<<3 | wireBytes, 5}, nil},
+		{"unsupported wire type", []byte{1, protoMessage<<3 | 3}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewProtoReader(bytes.NewReader(tt.stream)).Next()
+			if err == nil || err == io.EOF {
+				t.Fatalf("Next = %v, want a decoding error", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Next = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
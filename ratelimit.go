@@ -0,0 +1,187 @@
+package notifyme
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// rateLimiter keeps a token bucket per limited level and optionally one
+// shared by all levels. Clones share it, so it has its own mutex.
+type rateLimiter struct {
+	mu      sync.Mutex
+	owner   *Logger // the logger whose options change the limits
+	buckets map[int]*rateBucket
+	global  *rateBucket
+	// pending counts entries per level dropped by the global bucket since
+	// the last one let through; globalDrops counts them since creation
+	pending     map[int]int
+	globalDrops map[int]int64
+}
+
+// globalReserve is the share of the global budget each level leaves for
+// the levels above it, so INFO is throttled first
+var globalReserve = map[int]float64{
+	LevelInfo:  0.5,
+	LevelWarn:  0.25,
+	LevelError: 0.1,
+}
+
+// rateBucket is the budget of one level. It starts full and refills at rate
+// tokens per second up to burst.
+type rateBucket struct {
+	rate    float64
+	burst   float64
+	tokens  float64
+	last    time.Time
+	dropped int
+}
+
+// WithRateLimitBucket limits entries at level to ratePerSec on average,
+// allowing bursts of up to burst entries. Entries over budget are dropped
+// and counted; the next entry let through at that level reports them in
+// times_seen, as sampling does. Applying it again for the same level
+// replaces the limit. Clones share the budgets; applying a rate limit to a
+// clone gives it limits of its own.
+func WithRateLimitBucket(level int, ratePerSec float64, burst int) Option {
+	return func(l *Logger) error {
+		if ratePerSec <= 0 {
+			return errors.New("notifyme: rate limit must be positive")
+		}
+		if burst < 1 {
+			return errors.New("notifyme: rate limit burst must be at least 1")
+		}
+		r := l.ownLimiter()
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.buckets[level] = &rateBucket{rate: ratePerSec, burst: float64(burst), tokens: float64(burst)}
+		return nil
+	}
+}
+
+// WithGlobalRateLimit caps the entries of all levels together at
+// maxPerSec per second, allowing bursts of the same size. Lower levels
+// give way first: INFO entries are dropped once half of the budget is
+// used, WARN once three quarters are and ERROR at 90%. CRITICAL entries
+// are never dropped by this cap but use up the budget. Dropped entries
+// are counted per level in GlobalRateLimitDrops and reported in
+// times_seen like WithRateLimitBucket drops. It applies after any
+// per-level limit. Clones share the budget and the drop counts.
+func WithGlobalRateLimit(maxPerSec int) Option {
+	return func(l *Logger) error {
+		if maxPerSec <= 0 {
+			return errors.New("notifyme: global rate limit must be positive")
+		}
+		r := l.ownLimiter()
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		rate := float64(maxPerSec)
+		r.global = &rateBucket{rate: rate, burst: rate, tokens: rate}
+		if r.pending == nil {
+			r.pending = make(map[int]int)
+			r.globalDrops = make(map[int]int64)
+		}
+		return nil
+	}
+}
+
+// GlobalRateLimitDrops returns how many entries of each level the
+// WithGlobalRateLimit cap dropped, counted across the logger and the
+// loggers sharing its limits
+func (l *Logger) GlobalRateLimitDrops() map[int]int64 {
+	l.mu.Lock()
+	r := l.limiter
+	l.mu.Unlock()
+	drops := make(map[int]int64)
+	if r != nil {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for level, n := range r.globalDrops {
+			drops[level] = n
+		}
+	}
+	return drops
+}
+
+// ownLimiter returns the logger's rate limiter for changing its limits. A
+// limiter shared from the logger's parent is replaced by a copy with the
+// same limits, full buckets and no drops, so the parent keeps its own. It
+// must be called with the logger mutex held.
+func (l *Logger) ownLimiter() *rateLimiter {
+	if l.limiter != nil && l.limiter.owner == l {
+		return l.limiter
+	}
+	c := &rateLimiter{owner: l, buckets: make(map[int]*rateBucket)}
+	if r := l.limiter; r != nil {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for level, b := range r.buckets {
+			c.buckets[level] = &rateBucket{rate: b.rate, burst: b.burst, tokens: b.burst}
+		}
+		if r.global != nil {
+			c.global = &rateBucket{rate: r.global.rate, burst: r.global.burst, tokens: r.global.burst}
+			c.pending = make(map[int]int)
+			c.globalDrops = make(map[int]int64)
+		}
+	}
+	l.limiter = c
+	return c
+}
+
+// allow reports whether the entry fits in its level's budget and the
+// global one and, if so, how many entries were dropped at that level since
+// the last one let through
+func (r *rateLimiter) allow(entry Entry) (bool, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, limited := r.buckets[entry.Level]
+	if limited && !b.take(entry.Time, 0) {
+		b.dropped++
+		return false, 0
+	}
+	dropped := 0
+	switch {
+	case r.global == nil:
+	case entry.Level >= LevelCritical:
+		// CRITICAL is never dropped; it only spends what is left
+		r.global.take(entry.Time, 0)
+	default:
+		// A full bucket admits every level, so small caps do not starve INFO
+		reserve := globalReserve[entry.Level] * r.global.burst
+		if reserve > r.global.burst-1 {
+			reserve = r.global.burst - 1
+		}
+		if !r.global.take(entry.Time, reserve) {
+			if limited {
+				b.tokens++
+			}
+			r.pending[entry.Level]++
+			r.globalDrops[entry.Level]++
+			return false, 0
+		}
+		dropped = r.pending[entry.Level]
+		delete(r.pending, entry.Level)
+	}
+	if limited {
+		dropped += b.dropped
+		b.dropped = 0
+	}
+	return true, dropped
+}
+
+// take refills the bucket up to now and takes a token if more than reserve
+// tokens would remain
+func (b *rateBucket) take(now time.Time, reserve float64) bool {
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+	if b.tokens < 1+reserve {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,255 @@
+package notifyme
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// newRateLimitTestLogger returns a logger reading the time from clock whose
+// entries are kept in the returned ring
+func newRateLimitTestLogger(t *testing.T, clock *fakeClock, opts ...Option) (*Logger, *RingSink) {
+	t.Helper()
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	ring, _ := NewRingSink(1000)
+	logger.AddSink(ring)
+	if err := logger.Configure(append([]Option{WithClock(clock)}, opts...)...); err != nil {
+		t.Fatal(err)
+	}
+	return logger, ring
+}
+
+// rateStep logs n entries after advancing the clock by wait
+type rateStep struct {
+	wait time.Duration
+	n    int
+	want int
+}
+
+func TestWithRateLimitBucket(t *testing.T) {
+	tests := []struct {
+		name  string
+		rate  float64
+		burst int
+		steps []rateStep
+	}{
+		{"burst up to the cap", 1, 5, []rateStep{{0, 8, 5}}},
+		{"refill at the rate", 2, 3, []rateStep{{0, 3, 3}, {500 * time.Millisecond, 3, 1}, {time.Second, 3, 2}}},
+		{"refill capped at burst", 10, 4, []rateStep{{0, 4, 4}, {time.Minute, 10, 4}}},
+		{"fractional rate", 0.5, 1, []rateStep{{0, 2, 1}, {time.Second, 1, 0}, {time.Second, 1, 1}}},
+		{"smooth across boundaries", 4, 1, []rateStep{
+			{0, 1, 1}, {250 * time.Millisecond, 1, 1}, {100 * time.Millisecond, 1, 0}, {150 * time.Millisecond, 1, 1},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock(time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC))
+			logger, ring := newRateLimitTestLogger(t, clock, WithRateLimitBucket(LevelInfo, tt.rate, tt.burst))
+			total := 0
+			for i, step := range tt.steps {
+				clock.Advance(step.wait)
+				for j := 0; j < step.n; j++ {
+					logger.Log(LevelInfo, "tick")
+				}
+				total += step.want
+				if got := len(ring.Entries()); got != total {
+					t.Fatalf("step %d: %d entries let through so far, want %d", i, got, total)
+				}
+			}
+		})
+	}
+}
+
+func TestWithRateLimitBucketReportsDrops(t *testing.T) {
+	clock := newFakeClock(time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC))
+	logger, ring := newRateLimitTestLogger(t, clock, WithRateLimitBucket(LevelInfo, 1, 1))
+	for i := 0; i < 4; i++ {
+		logger.Log(LevelInfo, "tick")
+	}
+	logger.Log(LevelWarn, "unlimited level")
+	clock.Advance(time.Second)
+	logger.Log(LevelInfo, "tick")
+
+	entries := ring.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	for i, want := range []int{1, 1, 4} {
+		if entries[i].TimesSeen != want {
+			t.Errorf("entry %d (%q) times_seen = %d, want %d", i, entries[i].Message, entries[i].TimesSeen, want)
+		}
+	}
+}
+
+func TestWithRateLimitBucketInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		rate  float64
+		burst int
+	}{
+		{"zero rate", 0, 1},
+		{"negative rate", -1, 1},
+		{"zero burst", 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+			if err := logger.Configure(WithRateLimitBucket(LevelInfo, tt.rate, tt.burst)); err == nil {
+				t.Error("Configure accepted the limit")
+			}
+		})
+	}
+}
+
+func TestWithGlobalRateLimit(t *testing.T) {
+	type levelStep struct {
+		wait  time.Duration
+		level int
+		n     int
+		want  int
+	}
+	tests := []struct {
+		name  string
+		rate  int
+		steps []levelStep
+	}{
+		{"info gets half", 100, []levelStep{{0, LevelInfo, 100, 50}}},
+		{"warn gets three quarters", 100, []levelStep{{0, LevelWarn, 100, 75}}},
+		{"error gets 90%", 100, []levelStep{{0, LevelError, 100, 90}}},
+		{"critical always passes", 100, []levelStep{{0, LevelCritical, 150, 150}}},
+		{"info spent leaves room for errors", 100, []levelStep{
+			{0, LevelInfo, 100, 50}, {0, LevelWarn, 100, 25}, {0, LevelError, 100, 15}, {0, LevelCritical, 10, 10},
+		}},
+		{"critical uses up the budget", 100, []levelStep{{0, LevelCritical, 100, 100}, {0, LevelError, 10, 0}}},
+		{"refill", 10, []levelStep{{0, LevelInfo, 10, 5}, {time.Second, LevelInfo, 10, 5}}},
+		{"small cap admits info", 1, []levelStep{{0, LevelInfo, 3, 1}, {time.Second, LevelInfo, 3, 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock(time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC))
+			logger, ring := newRateLimitTestLogger(t, clock, WithGlobalRateLimit(tt.rate))
+			total := 0
+			for i, step := range tt.steps {
+				clock.Advance(step.wait)
+				for j := 0; j < step.n; j++ {
+					logger.Log(step.level, "flood")
+				}
+				total += step.want
+				if got := len(ring.Entries()); got != total {
+					t.Fatalf("step %d: %d entries let through so far, want %d", i, got, total)
+				}
+			}
+		})
+	}
+}
+
+func TestWithGlobalRateLimitDropsLowerLevelsFirst(t *testing.T) {
+	clock := newFakeClock(time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC))
+	logger, ring := newRateLimitTestLogger(t, clock, WithGlobalRateLimit(100))
+	levels := []int{LevelInfo, LevelWarn, LevelError, LevelCritical}
+	for i := 0; i < 100; i++ {
+		for _, level := range levels {
+			logger.Log(level, "flood")
+		}
+	}
+
+	passed := make(map[int]int)
+	for _, entry := range ring.Entries() {
+		passed[entry.Level]++
+	}
+	drops := logger.GlobalRateLimitDrops()
+	for _, level := range levels {
+		if got := int64(passed[level]) + drops[level]; got != 100 {
+			t.Errorf("%s: %d passed and %d dropped, want 100 in all", levelName(level), passed[level], drops[level])
+		}
+	}
+	if passed[LevelCritical] != 100 || drops[LevelCritical] != 0 {
+		t.Errorf("%d criticals passed, want all 100", passed[LevelCritical])
+	}
+	if !(drops[LevelInfo] > drops[LevelWarn] && drops[LevelWarn] > drops[LevelError] && drops[LevelError] > 0) {
+		t.Errorf("drops %v, want fewer drops at each higher level", drops)
+	}
+}
+
+func TestWithGlobalRateLimitReportsDrops(t *testing.T) {
+	clock := newFakeClock(time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC))
+	logger, ring := newRateLimitTestLogger(t, clock, WithGlobalRateLimit(4))
+	for i := 0; i < 5; i++ {
+		logger.Log(LevelInfo, "tick")
+	}
+	clock.Advance(time.Second)
+	logger.Log(LevelInfo, "tick")
+
+	entries := ring.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	for i, want := range []int{1, 1, 4} {
+		if entries[i].TimesSeen != want {
+			t.Errorf("entry %d times_seen = %d, want %d", i, entries[i].TimesSeen, want)
+		}
+	}
+	if drops := logger.GlobalRateLimitDrops(); drops[LevelInfo] != 3 || len(drops) != 1 {
+		t.Errorf("GlobalRateLimitDrops = %v, want 3 INFO", drops)
+	}
+}
+
+func TestGlobalRateLimitSharedWithDerivedLoggers(t *testing.T) {
+	clock := newFakeClock(time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC))
+	logger, ring := newRateLimitTestLogger(t, clock, WithGlobalRateLimit(10))
+	derived := []*Logger{
+		logger,
+		logger.Clone(),
+		logger.WithFields(map[string]interface{}{"req": 1}),
+		logger.With("user", "ann"),
+	}
+	for i := 0; i < 10; i++ {
+		for _, l := range derived {
+			l.Log(LevelInfo, "flood")
+		}
+	}
+
+	// Half of the budget is open to INFO, however many loggers share it
+	if got := len(ring.Entries()); got != 5 {
+		t.Errorf("%d entries let through, want 5", got)
+	}
+	for i, l := range derived {
+		if drops := l.GlobalRateLimitDrops(); drops[LevelInfo] != 35 {
+			t.Errorf("logger %d: GlobalRateLimitDrops = %v, want 35 INFO", i, drops)
+		}
+	}
+}
+
+func TestRateLimitOnCloneIsIndependent(t *testing.T) {
+	clock := newFakeClock(time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC))
+	logger, ring := newRateLimitTestLogger(t, clock, WithRateLimitBucket(LevelInfo, 1, 2))
+	clone := logger.Clone()
+	if err := clone.Configure(WithRateLimitBucket(LevelWarn, 1, 1)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		logger.Log(LevelInfo, "original")
+		clone.Log(LevelInfo, "clone")
+		clone.Log(LevelWarn, "clone")
+	}
+
+	counts := make(map[string]int)
+	for _, entry := range ring.Entries() {
+		counts[levelName(entry.Level)+" "+entry.Message]++
+	}
+	want := map[string]int{"INFO original": 2, "INFO clone": 2, "WARN clone": 1}
+	for key, n := range want {
+		if counts[key] != n {
+			t.Errorf("%s: %d entries let through, want %d", key, counts[key], n)
+		}
+	}
+}
+
+func TestWithGlobalRateLimitInvalid(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	for _, rate := range []int{0, -5} {
+		if err := logger.Configure(WithGlobalRateLimit(rate)); err == nil {
+			t.Errorf("Configure accepted a limit of %d", rate)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package notifyme
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// maxPanicFrames bounds the frames captured for a recovered panic before
+// they are cut to the configured stack frame limit
+const maxPanicFrames = 128
+
+// RecoveryMiddleware wraps an HTTP handler so a panic in it is logged at
+// CRITICAL and answered with a 500 response instead of tearing down the
+// connection. The entry carries the request method and path, the panic
+// value and the stack of the panicking goroutine, and its caller is where
+// the panic happened. Error values are logged with their type and wrapped
+// errors, structs with their exported fields. A 500 is only written if the
+// handler had not started its response. http.ErrAbortHandler is passed
+// on, as net/http expects.
+func (l *Logger) RecoveryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &recoveryWriter{ResponseWriter: w}
+		defer func() {
+			value := recover()
+			if value == nil {
+				return
+			}
+			if value == http.ErrAbortHandler {
+				panic(value)
+			}
+			l.logPanic(value, []Field{
+				{Key: "method", Value: r.Method},
+				{Key: "path", Value: r.URL.Path},
+			})
+			if !rw.wroteHeader {
+				http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+		}()
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// logPanic logs a recovered panic value at CRITICAL with the stack of the
+// panic. It must be called from the deferred function that recovered it.
+func (l *Logger) logPanic(value interface{}, fields []Field) {
+	if l.effectiveLevel() > LevelCritical {
+		return
+	}
+	// Skip runtime.Callers, logPanic and the deferred function, then the
+	// runtime's panic machinery above the panicking frame
+	pcs := make([]uintptr, maxPanicFrames)
+	pcs = pcs[:runtime.Callers(3, pcs)]
+	for len(pcs) > 0 {
+		frame, _ := runtime.CallersFrames(pcs[:1]).Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			break
+		}
+		pcs = pcs[1:]
+	}
+	var caller Caller
+	if len(pcs) > 0 {
+		frame, _ := runtime.CallersFrames(pcs[:1]).Next()
+		caller = Caller{File: frame.File, Line: frame.Line, Function: frame.Function}
+	}
+
+	defer l.unlockWrite(l.lockWrite())
+	frames := l.opts.stackFrames
+	if frames == 0 {
+		frames = defaultStackFrames
+	}
+	if len(pcs) > frames {
+		pcs = pcs[:frames]
+	}
+	if !l.opts.callerFunction {
+		caller.Function = ""
+	}
+	described := panicFields(value)
+	message := fmt.Sprintf("Recovered panic: %T", value)
+	if text, ok := described[0].Value.(string); ok {
+		message = "Recovered panic: " + text
+	}
+	fields = append(fields, described...)
+	fields = append(fields, Field{Key: "stack", Value: renderStack(pcs)})
+	l.writeEntry(l.newEntry(LevelCritical, message, caller, fields))
+}
+
+// panicFields describes a panic value. Errors give their message, type and
+// the messages of the errors they wrap; structs, or pointers to them, give
+// their exported fields as a map; anything else is formatted with %v.
+func panicFields(value interface{}) []Field {
+	if err, ok := value.(error); ok {
+		fields := []Field{
+			{Key: "panic", Value: err.Error()},
+			{Key: "panic_type", Value: fmt.Sprintf("%T", err)},
+		}
+		if chain := unwrapChain(err); len(chain) > 0 {
+			fields = append(fields, Field{Key: "panic_chain", Value: chain})
+		}
+		return fields
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Struct {
+		t := v.Type()
+		fields := make(map[string]interface{}, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).IsExported() {
+				fields[t.Field(i).Name] = v.Field(i).Interface()
+			}
+		}
+		return []Field{
+			{Key: "panic", Value: fields},
+			{Key: "panic_type", Value: fmt.Sprintf("%T", value)},
+		}
+	}
+	return []Field{{Key: "panic", Value: fmt.Sprintf("%v", value)}}
+}
+
+// unwrapChain returns the messages of the errors err wraps, depth first,
+// following both Unwrap() error and Unwrap() []error
+func unwrapChain(err error) []string {
+	var chain []string
+	var walk func(error)
+	walk = func(err error) {
+		switch u := err.(type) {
+		case interface{ Unwrap() error }:
+			if inner := u.Unwrap(); inner != nil {
+				chain = append(chain, inner.Error())
+				walk(inner)
+			}
+		case interface{ Unwrap() []error }:
+			for _, inner := range u.Unwrap() {
+				if inner != nil {
+					chain = append(chain, inner.Error())
+					walk(inner)
+				}
+			}
+		}
+	}
+	walk(err)
+	return chain
+}
+
+// recoveryWriter records whether the response was started, so a panic
+// after that does not write a second status line
+type recoveryWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *recoveryWriter) WriteHeader(status int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recoveryWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush passes flushes on for streaming handlers
+func (w *recoveryWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wroteHeader = true
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController
+func (w *recoveryWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,217 @@
+package notifyme
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// panickingHandler panics with value after writing status, if not zero
+func panickingHandler(status int, value interface{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status != 0 {
+			w.WriteHeader(status)
+		}
+		if value != nil {
+			panic(value)
+		}
+		w.Write([]byte("ok"))
+	})
+}
+
+func TestRecoveryMiddleware(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.Handler
+		status  int
+		logged  bool
+		message string
+	}{
+		{"no panic", panickingHandler(0, nil), http.StatusOK, false, ""},
+		{"panic", panickingHandler(0, "nil map"), http.StatusInternalServerError, true, "Recovered panic: nil map"},
+		{"panic after the header", panickingHandler(http.StatusAccepted, "late"), http.StatusAccepted, true, "Recovered panic: late"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+			ring, _ := NewRingSink(1)
+			logger.AddSink(ring)
+			rec := httptest.NewRecorder()
+			logger.RecoveryMiddleware(tt.handler).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/orders?id=7", nil))
+
+			if rec.Code != tt.status {
+				t.Errorf("status %d, want %d", rec.Code, tt.status)
+			}
+			entries := ring.Entries()
+			if !tt.logged {
+				if len(entries) != 0 {
+					t.Errorf("logged %+v without a panic", entries)
+				}
+				return
+			}
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(entries))
+			}
+			entry := entries[0]
+			if entry.Level != LevelCritical || entry.Message != tt.message {
+				t.Errorf("entry %s %q, want CRITICAL %q", levelName(entry.Level), entry.Message, tt.message)
+			}
+			method, _ := lastField(entry.Fields, "method")
+			path, _ := lastField(entry.Fields, "path")
+			if method != http.MethodPost || path != "/orders" {
+				t.Errorf("method %v path %v, want the request's", method, path)
+			}
+			stack, _ := lastField(entry.Fields, "stack")
+			if s, _ := stack.(string); !strings.Contains(s, "panickingHandler") || strings.HasPrefix(s, "runtime.") {
+				t.Errorf("stack = %q, want it to start at the handler", s)
+			}
+			if !strings.HasSuffix(entry.Caller.File, "recovery_test.go") {
+				t.Errorf("caller = %s, want the panicking line", entry.Caller)
+			}
+			if tt.status == http.StatusInternalServerError && !strings.Contains(rec.Body.String(), "Internal Server Error") {
+				t.Errorf("body %q, want the 500 text", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRecoveryMiddlewareAbortHandler(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	ring, _ := NewRingSink(1)
+	logger.AddSink(ring)
+	defer func() {
+		if value := recover(); value != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler passed on", value)
+		}
+		if len(ring.Entries()) != 0 {
+			t.Error("http.ErrAbortHandler was logged")
+		}
+	}()
+	logger.RecoveryMiddleware(panickingHandler(0, http.ErrAbortHandler)).
+		ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestRecoveryMiddlewareOutput(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newWriterLogger(LevelCritical, &buf)
+	rec := httptest.NewRecorder()
+	logger.RecoveryMiddleware(panickingHandler(0, "boom")).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	line := buf.String()
+	if !strings.Contains(line, "[CRITICAL] Recovered panic: boom method=GET path=/health panic=boom stack=") {
+		t.Errorf("line %q lacks the request and the stack", line)
+	}
+}
+
+// orderFailure is a struct panic value with an unexported field
+type orderFailure struct {
+	OrderID int
+	Reason  string
+	retries int
+}
+
+// multiError wraps several errors like errors.Join
+type multiError []error
+
+func (m multiError) Error() string   { return "several failures" }
+func (m multiError) Unwrap() []error { return m }
+
+func TestPanicFields(t *testing.T) {
+	inner := errors.New("connection refused")
+	tests := []struct {
+		name  string
+		value interface{}
+		want  []Field
+	}{
+		{"string", "nil map", []Field{{Key: "panic", Value: "nil map"}}},
+		{"int", 42, []Field{{Key: "panic", Value: "42"}}},
+		{"error", inner, []Field{
+			{Key: "panic", Value: "connection refused"}, {Key: "panic_type", Value: "*errors.errorString"},
+		}},
+		{"wrapped error", fmt.Errorf("charge: %w", fmt.Errorf("dial: %w", inner)), []Field{
+			{Key: "panic", Value: "charge: dial: connection refused"},
+			{Key: "panic_type", Value: "*fmt.wrapError"},
+			{Key: "panic_chain", Value: []string{"dial: connection refused", "connection refused"}},
+		}},
+		{"multiple wrapped errors", multiError{inner, fmt.Errorf("retry: %w", io.EOF)}, []Field{
+			{Key: "panic", Value: "several failures"},
+			{Key: "panic_type", Value: "notifyme.multiError"},
+			{Key: "panic_chain", Value: []string{"connection refused", "retry: EOF", "EOF"}},
+		}},
+		{"struct", orderFailure{OrderID: 7, Reason: "declined", retries: 3}, []Field{
+			{Key: "panic", Value: map[string]interface{}{"OrderID": 7, "Reason": "declined"}},
+			{Key: "panic_type", Value: "notifyme.orderFailure"},
+		}},
+		{"struct pointer", &orderFailure{OrderID: 8}, []Field{
+			{Key: "panic", Value: map[string]interface{}{"OrderID": 8, "Reason": ""}},
+			{Key: "panic_type", Value: "*notifyme.orderFailure"},
+		}},
+		{"nil struct pointer", (*orderFailure)(nil), []Field{{Key: "panic", Value: "<nil>"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := panicFields(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("panicFields(%#v) = %#v, want %#v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecoveryMiddlewareStructuredPanic(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		message string
+		check   func(t *testing.T, doc map[string]interface{})
+	}{
+		{"error", fmt.Errorf("charge: %w", io.ErrUnexpectedEOF), "Recovered panic: charge: unexpected EOF",
+			func(t *testing.T, doc map[string]interface{}) {
+				chain, _ := doc["panic_chain"].([]interface{})
+				if doc["panic_type"] != "*fmt.wrapError" || len(chain) != 1 || chain[0] != "unexpected EOF" {
+					t.Errorf("panic_type %v panic_chain %v", doc["panic_type"], doc["panic_chain"])
+				}
+			}},
+		{"struct", orderFailure{OrderID: 7, Reason: "declined"}, "Recovered panic: notifyme.orderFailure",
+			func(t *testing.T, doc map[string]interface{}) {
+				value, _ := doc["panic"].(map[string]interface{})
+				if value["OrderID"] != float64(7) || value["Reason"] != "declined" || len(value) != 2 {
+					t.Errorf("panic = %v, want the exported fields", doc["panic"])
+				}
+			}},
+		{"string", "nil map", "Recovered panic: nil map",
+			func(t *testing.T, doc map[string]interface{}) {
+				if doc["panic"] != "nil map" {
+					t.Errorf("panic = %v", doc["panic"])
+				}
+				if _, ok := doc["panic_type"]; ok {
+					t.Error("panic_type set for a string")
+				}
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newWriterLogger(LevelInfo, &buf)
+			if err := logger.Configure(WithFormat(FormatJSON)); err != nil {
+				t.Fatal(err)
+			}
+			logger.RecoveryMiddleware(panickingHandler(0, tt.value)).
+				ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			var doc map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+				t.Fatalf("decoding %q: %v", buf.String(), err)
+			}
+			if doc["level"] != "CRITICAL" || doc["msg"] != tt.message {
+				t.Errorf("entry %v %q, want CRITICAL %q", doc["level"], doc["msg"], tt.message)
+			}
+			tt.check(t, doc)
+		})
+	}
+}
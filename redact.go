@@ -0,0 +1,47 @@
+package notifyme
+
+import (
+	"errors"
+	"regexp"
+)
+
+// redactPattern is a compiled pattern and its replacement
+type redactPattern struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+// WithRedactPattern replaces every match of re in messages and string field
+// values with replacement, which may refer to submatches as in
+// Regexp.ReplaceAllString. Patterns accumulate and are applied in the order
+// they were added, before the message is truncated. Values that are not
+// strings are left alone.
+func WithRedactPattern(re *regexp.Regexp, replacement string) Option {
+	return func(l *Logger) error {
+		if re == nil {
+			return errors.New("notifyme: redact pattern must not be nil")
+		}
+		patterns := make([]redactPattern, len(l.opts.redactPatterns), len(l.opts.redactPatterns)+1)
+		copy(patterns, l.opts.redactPatterns)
+		l.opts.redactPatterns = append(patterns, redactPattern{re: re, replacement: replacement})
+		return nil
+	}
+}
+
+// redact applies the redaction patterns to s
+func (l *Logger) redact(s string) string {
+	for _, p := range l.opts.redactPatterns {
+		s = p.re.ReplaceAllString(s, p.replacement)
+	}
+	return s
+}
+
+// redactFields applies the redaction patterns to the entry's string field
+// values. It must be called with the logger mutex held.
+func (l *Logger) redactFields(entry *Entry) {
+	for i, field := range entry.Fields {
+		if s, ok := field.Value.(string); ok {
+			entry.Fields[i].Value = l.redact(s)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package notifyme
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+var (
+	emailPattern = regexp.MustCompile(`[\w.+-]+@[\w-]+\.[\w.]+`)
+	cardPattern  = regexp.MustCompile(`\b\d{4}(?:[ -]?\d{4}){3}\b`)
+)
+
+func TestWithRedactPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		log     func(*Logger)
+		message string
+		fields  []Field
+	}{
+		{
+			"email in message",
+			[]Option{WithRedactPattern(emailPattern, "[email]")},
+			func(l *Logger) { l.Log(LevelInfo, "sent receipt to ann.lee+shop@example.co.uk today") },
+			"sent receipt to [email] today", nil,
+		},
+		{
+			"email in parameters",
+			[]Option{WithRedactPattern(emailPattern, "[email]")},
+			func(l *Logger) { l.Log(LevelWarn, "bounce from", "bob@example.com", 550) },
+			"bounce from [email] 550", nil,
+		},
+		{
+			"string fields",
+			[]Option{WithRedactPattern(emailPattern, "[email]")},
+			func(l *Logger) {
+				l.With("to", "bob@example.com", "attempts", 3).NewEvent(LevelInfo).Str("cc", "x <eve@example.org>").Msg("mailed")
+			},
+			"mailed", []Field{{Key: "to", Value: "[email]"}, {Key: "attempts", Value: 3}, {Key: "cc", Value: "x <[email]>"}},
+		},
+		{
+			"patterns applied in order",
+			[]Option{WithRedactPattern(cardPattern, "[card]"), WithRedactPattern(emailPattern, "[email]")},
+			func(l *Logger) { l.Log(LevelInfo, "card 4111 1111 1111 1111 for bob@example.com") },
+			"card [card] for [email]", nil,
+		},
+		{
+			"submatch replacement",
+			[]Option{WithRedactPattern(regexp.MustCompile(`([\w.]+)@([\w.]+)`), "***@$2")},
+			func(l *Logger) { l.Log(LevelInfo, "user bob@example.com") },
+			"user ***@example.com", nil,
+		},
+		{
+			"no match",
+			[]Option{WithRedactPattern(emailPattern, "[email]")},
+			func(l *Logger) { l.Log(LevelInfo, "nothing to hide") },
+			"nothing to hide", nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+			ring, _ := NewRingSink(1)
+			logger.AddSink(ring)
+			if err := logger.Configure(tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			tt.log(logger)
+
+			entry := ring.Entries()[0]
+			if entry.Message != tt.message {
+				t.Errorf("message = %q, want %q", entry.Message, tt.message)
+			}
+			if !reflect.DeepEqual(entry.Fields, tt.fields) {
+				t.Errorf("fields = %v, want %v", entry.Fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestWithRedactPatternKeepsLoggerFields(t *testing.T) {
+	var buf bytes.Buffer
+	base := newWriterLogger(LevelInfo, &buf)
+	logger := base.With("to", "bob@example.com")
+	if err := logger.Configure(WithRedactPattern(emailPattern, "[email]")); err != nil {
+		t.Fatal(err)
+	}
+	logger.Log(LevelInfo, "mailed")
+	if want := []Field{{Key: "to", Value: "bob@example.com"}}; !reflect.DeepEqual(logger.fields, want) {
+		t.Errorf("logger fields = %v, want them unchanged", logger.fields)
+	}
+	if configured := base.opts.redactPatterns; len(configured) != 0 {
+		t.Errorf("configuring the child added %d patterns to its parent", len(configured))
+	}
+}
+
+func TestWithRedactPatternNil(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	if err := logger.Configure(WithRedactPattern(nil, "x")); err == nil {
+		t.Error("Configure accepted a nil pattern")
+	}
+}
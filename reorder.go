@@ -0,0 +1,117 @@
+package notifyme
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// reorderBuffer holds entries passed to WriteEntry until their window has
+// passed, so they can be written in timestamp order
+type reorderBuffer struct {
+	// held is sorted by entry time
+	held []heldEntry
+	// stop ends the goroutine waiting for the next deadline, if any
+	stop chan struct{}
+}
+
+// heldEntry is an entry waiting in the reorder buffer until its deadline
+type heldEntry struct {
+	entry    Entry
+	deadline time.Time
+}
+
+// WithReorderWindow holds entries passed to WriteEntry for up to d and
+// writes them in timestamp order, for relays merging entries from sources
+// whose clocks or deliveries are slightly out of step. An entry is written
+// at most d after it arrived, together with every held entry older than
+// it; entries arriving more than d late may still be out of order. Entries
+// logged directly are not held. Close writes any entries still held. Zero
+// writes entries as they arrive. Deadlines are read from the clock set
+// with WithClock, if any. Held entries are written from a timer goroutine,
+// so the option cannot be combined with WithUnsafeNoLock.
+func WithReorderWindow(d time.Duration) Option {
+	return func(l *Logger) error {
+		if d < 0 {
+			return errors.New("notifyme: reorder window must not be negative")
+		}
+		if d > 0 && l.noLock.Load() {
+			return errors.New("notifyme: reorder window cannot be used with WithUnsafeNoLock")
+		}
+		l.opts.reorderWindow = d
+		if d == 0 && l.reorder != nil {
+			l.releaseHeld(true)
+		}
+		return nil
+	}
+}
+
+// holdEntry adds the entry to the reorder buffer and writes whatever is
+// due. It must be called with the logger mutex held.
+func (l *Logger) holdEntry(entry Entry) {
+	if l.reorder == nil {
+		l.reorder = &reorderBuffer{}
+	}
+	r := l.reorder
+	i := sort.Search(len(r.held), func(i int) bool {
+		return r.held[i].entry.Time.After(entry.Time)
+	})
+	r.held = append(r.held, heldEntry{})
+	copy(r.held[i+1:], r.held[i:])
+	r.held[i] = heldEntry{entry: entry, deadline: l.currentTime().Add(l.opts.reorderWindow)}
+	l.releaseHeld(false)
+}
+
+// releaseHeld writes every held entry up to the newest one whose deadline
+// has passed, or all of them if all is set, and schedules the next
+// release. It must be called with the logger mutex held.
+func (l *Logger) releaseHeld(all bool) {
+	r := l.reorder
+	if r == nil {
+		return
+	}
+	now := l.currentTime()
+	n := 0
+	for i, h := range r.held {
+		if all || !h.deadline.After(now) {
+			n = i + 1
+		}
+	}
+	due := r.held[:n]
+	r.held = append([]heldEntry(nil), r.held[n:]...)
+	for _, h := range due {
+		l.writeEntry(h.entry)
+	}
+
+	if r.stop != nil {
+		close(r.stop)
+		r.stop = nil
+	}
+	if len(r.held) == 0 {
+		return
+	}
+	next := r.held[0].deadline
+	for _, h := range r.held[1:] {
+		if h.deadline.Before(next) {
+			next = h.deadline
+		}
+	}
+	r.stop = make(chan struct{})
+	go l.awaitHeld(r, r.stop, l.newTicker(next.Sub(now)))
+}
+
+// awaitHeld releases the held entries that are due on the first tick,
+// unless it is stopped first because the release was rescheduled
+func (l *Logger) awaitHeld(r *reorderBuffer, stop chan struct{}, ticker Ticker) {
+	defer ticker.Stop()
+	select {
+	case <-ticker.C():
+	case <-stop:
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.reorder == r && r.stop == stop {
+		l.releaseHeld(false)
+	}
+}
@@ -0,0 +1,175 @@
+package notifyme
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// relayedEntries returns entries logged the given number of milliseconds
+// after a base time, each with that offset as message, e.g. "5ms"
+func relayedEntries(offsets ...int) []Entry {
+	at := time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC)
+	entries := make([]Entry, len(offsets))
+	for i, ms := range offsets {
+		offset := time.Duration(ms) * time.Millisecond
+		entries[i] = Entry{Level: LevelInfo, Message: offset.String(), Time: at.Add(offset)}
+	}
+	return entries
+}
+
+// waitForEntries waits until the ring holds n entries and returns their
+// messages
+func waitForEntries(t *testing.T, ring *RingSink, n int) []string {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for len(ring.Entries()) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d entries, want %d", len(ring.Entries()), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	var messages []string
+	for _, entry := range ring.Entries() {
+		messages = append(messages, entry.Message)
+	}
+	return messages
+}
+
+func TestWithReorderWindow(t *testing.T) {
+	tests := []struct {
+		name    string
+		offsets []int
+		want    []string
+	}{
+		{"in order", []int{1, 2, 3}, []string{"1ms", "2ms", "3ms"}},
+		{"reversed", []int{30, 20, 10}, []string{"10ms", "20ms", "30ms"}},
+		{"shuffled", []int{5, 1, 4, 2, 3}, []string{"1ms", "2ms", "3ms", "4ms", "5ms"}},
+		{"repeated times", []int{2, 1, 2, 1}, []string{"1ms", "1ms", "2ms", "2ms"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+			ring, _ := NewRingSink(10)
+			logger.AddSink(ring)
+			if err := logger.Configure(WithReorderWindow(20 * time.Millisecond)); err != nil {
+				t.Fatal(err)
+			}
+			start := time.Now()
+			for _, entry := range relayedEntries(tt.offsets...) {
+				logger.WriteEntry(entry)
+			}
+			if got := waitForEntries(t, ring, len(tt.want)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("written %q, want %q", got, tt.want)
+			}
+			if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+				t.Errorf("entries written after %v, before the window passed", elapsed)
+			}
+		})
+	}
+}
+
+func TestWithReorderWindowStableOrder(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	ring, _ := NewRingSink(10)
+	logger.AddSink(ring)
+	if err := logger.Configure(WithReorderWindow(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	entries := relayedEntries(1, 1)
+	entries[0].Message, entries[1].Message = "first", "second"
+	for _, entry := range entries {
+		logger.WriteEntry(entry)
+	}
+	logger.Close()
+	if got := waitForEntries(t, ring, 2); !reflect.DeepEqual(got, []string{"first", "second"}) {
+		t.Errorf("written %q, want arrival order", got)
+	}
+}
+
+func TestWithReorderWindowHoldsOnlyRelayedEntries(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	ring, _ := NewRingSink(10)
+	logger.AddSink(ring)
+	if err := logger.Configure(WithReorderWindow(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	logger.WriteEntry(relayedEntries(5)[0])
+	logger.Log(LevelInfo, "direct")
+	if got := waitForEntries(t, ring, 1); !reflect.DeepEqual(got, []string{"direct"}) {
+		t.Fatalf("written %q, want only the direct entry before the window", got)
+	}
+
+	// Close releases held entries
+	logger.Close()
+	if got := waitForEntries(t, ring, 2); !reflect.DeepEqual(got, []string{"direct", "5ms"}) {
+		t.Errorf("written %q after Close, want the held entry", got)
+	}
+}
+
+func TestWithReorderWindowDisabling(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	ring, _ := NewRingSink(10)
+	logger.AddSink(ring)
+	if err := logger.Configure(WithReorderWindow(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	entries := relayedEntries(2, 1, 3)
+	logger.WriteEntry(entries[0])
+	logger.WriteEntry(entries[1])
+	if err := logger.Configure(WithReorderWindow(0)); err != nil {
+		t.Fatal(err)
+	}
+	logger.WriteEntry(entries[2])
+	if got := waitForEntries(t, ring, 3); !reflect.DeepEqual(got, []string{"1ms", "2ms", "3ms"}) {
+		t.Errorf("written %q, want held entries released when the window is turned off", got)
+	}
+}
+
+func TestWithReorderWindowInvalid(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	if err := logger.Configure(WithReorderWindow(-time.Second)); err == nil {
+		t.Error("Configure accepted a negative window")
+	}
+}
+
+func TestWithReorderWindowUsesClock(t *testing.T) {
+	clock := newFakeClock(time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC))
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	ring, _ := NewRingSink(10)
+	logger.AddSink(ring)
+	if err := logger.Configure(WithClock(clock), WithReorderWindow(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	entries := relayedEntries(2, 1)
+	logger.WriteEntry(entries[0])
+	clock.Advance(30 * time.Second)
+	logger.WriteEntry(entries[1])
+	if n := len(ring.Entries()); n != 0 {
+		t.Fatalf("%d entries written before the clock passed the window", n)
+	}
+
+	clock.Advance(30 * time.Second)
+	if got := waitForEntries(t, ring, 2); !reflect.DeepEqual(got, []string{"1ms", "2ms"}) {
+		t.Errorf("written %q, want both entries once the first deadline passed", got)
+	}
+}
+
+func TestWithReorderWindowRejectsUnsafeNoLock(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"window first", []Option{WithReorderWindow(time.Second), WithUnsafeNoLock()}},
+		{"no lock first", []Option{WithUnsafeNoLock(), WithReorderWindow(time.Second)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+			if err := logger.Configure(tt.opts...); err == nil {
+				t.Error("Configure accepted a reorder window without the logger mutex")
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package notifyme
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRFC5424Priority(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		level    int
+		priority string
+	}{
+		{"default facility info", nil, LevelInfo, "<14>"},
+		{"default facility warn", nil, LevelWarn, "<12>"},
+		{"default facility error", nil, LevelError, "<11>"},
+		{"default facility critical", nil, LevelCritical, "<10>"},
+		{"kern", []Option{WithSyslogFacility(FacilityKern)}, LevelCritical, "<2>"},
+		{"auth", []Option{WithSyslogFacility(FacilityAuth)}, LevelError, "<35>"},
+		{"local0", []Option{WithSyslogFacility(FacilityLocal0)}, LevelInfo, "<134>"},
+		{"local7", []Option{WithSyslogFacility(FacilityLocal7)}, LevelWarn, "<188>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newEventTestLogger(t, &buf, append([]Option{WithFormat(FormatRFC5424)}, tt.opts...)...)
+			logger.Log(tt.level, "checked")
+			if want := tt.priority + "1 "; !strings.HasPrefix(buf.String(), want) {
+				t.Errorf("line %q, want prefix %q", buf.String(), want)
+			}
+		})
+	}
+}
+
+func TestRFC5424Header(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newEventTestLogger(t, &buf, WithFormat(FormatRFC5424), WithSyslogAppName("billing service"))
+	logger.Log(LevelWarn, "card declined")
+
+	hostname, _ := os.Hostname()
+	want := "<12>1 2024-03-09T14:05:06+01:00 " + syslogHeaderField(hostname, maxSyslogHostname) +
+		" billing_service " + strconv.Itoa(os.Getpid()) + " - - card declined\n"
+	if buf.String() != want {
+		t.Errorf("line = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestRFC5424StructuredData(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(*Logger)
+		want string
+	}{
+		{"no fields", func(l *Logger) { l.Log(LevelInfo, "up") }, " - - up\n"},
+		{"fields", func(l *Logger) {
+			l.NewEvent(LevelInfo).Str("user", "ann").Int("items", 3).Bool("paid", true).Msg("checkout")
+		}, ` - [fields@32473 user="ann" items="3" paid="true"] checkout` + "\n"},
+		{"escaped values", func(l *Logger) {
+			l.With("query", `name="o\neil" [x]`).Log(LevelInfo, "search")
+		}, ` - [fields@32473 query="name=\"o\\neil\" [x\]"] search` + "\n"},
+		{"invalid names", func(l *Logger) {
+			l.With("a b=c", 1, "", 2, "é", 3).Log(LevelInfo, "odd keys")
+		}, ` - [fields@32473 a_b_c="1" _="2" _="3"] odd keys` + "\n"},
+		{"long name", func(l *Logger) {
+			l.With(strings.Repeat("k", 40), "v").Log(LevelInfo, "long")
+		}, ` - [fields@32473 ` + strings.Repeat("k", 32) + `="v"] long` + "\n"},
+		{"no message", func(l *Logger) { l.With("k", "v").Log(LevelInfo, "") }, ` - [fields@32473 k="v"]` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tt.log(newEventTestLogger(t, &buf, WithFormat(FormatRFC5424), WithSyslogAppName("app")))
+			if !strings.HasSuffix(buf.String(), tt.want) {
+				t.Errorf("line = %q, want suffix %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRFC5424MessageID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newEventTestLogger(t, &buf, WithFormat(FormatRFC5424), WithSyslogAppName("app"),
+		WithMessageID(func() string { return "req 42" }))
+	logger.With("user", "ann").Log(LevelInfo, "login")
+	if want := ` req_42 [fields@32473 user="ann"] login` + "\n"; !strings.HasSuffix(buf.String(), want) {
+		t.Errorf("line = %q, want suffix %q", buf.String(), want)
+	}
+}
+
+func TestRFC5424Timestamp(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{"default", nil, " 2024-03-09T14:05:06+01:00 "},
+		{"nanoseconds capped at microseconds", []Option{WithTimestampPrecision(PrecisionNanoseconds)}, " 2024-03-09T14:05:06.123456+01:00 "},
+		{"time zone", []Option{WithTimeZone(time.UTC)}, " 2024-03-09T13:05:06Z "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newEventTestLogger(t, &buf, append([]Option{WithFormat(FormatRFC5424)}, tt.opts...)...)
+			logger.Log(LevelInfo, "tick")
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("line = %q, want timestamp %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRFC5424OptionsInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  Option
+	}{
+		{"negative facility", WithSyslogFacility(-1)},
+		{"facility too large", WithSyslogFacility(24)},
+		{"empty app name", WithSyslogAppName("")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+			if err := logger.Configure(tt.opt); err == nil {
+				t.Error("Configure accepted the option")
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package notifyme
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// RingSink keeps the most recent entries in memory, e.g. to dump them when
+// an incident is detected. Attach it with AddSink and raise the logger's
+// level filter on the primary output with SetWriterLevel to capture more
+// than is written.
+type RingSink struct {
+	mu      sync.Mutex
+	entries []Entry
+	next    int
+	full    bool
+}
+
+// NewRingSink creates a RingSink retaining up to size entries
+func NewRingSink(size int) (*RingSink, error) {
+	if size <= 0 {
+		return nil, errors.New("notifyme: ring buffer size must be positive")
+	}
+	return &RingSink{entries: make([]Entry, size)}, nil
+}
+
+// Write stores the entry, replacing the oldest one once the buffer is full
+func (r *RingSink) Write(entry Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[r.next] = entry
+	r.next++
+	if r.next == len(r.entries) {
+		r.next = 0
+		r.full = true
+	}
+	return nil
+}
+
+// Close implements Sink; the retained entries stay available
+func (r *RingSink) Close() error {
+	return nil
+}
+
+// Entries returns a copy of the retained entries, oldest first
+func (r *RingSink) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.full {
+		return append([]Entry(nil), r.entries[:r.next]...)
+	}
+	out := make([]Entry, 0, len(r.entries))
+	out = append(out, r.entries[r.next:]...)
+	return append(out, r.entries[:r.next]...)
+}
+
+// DrainTo writes a snapshot of the retained entries to w in the given
+// format, oldest first, without removing them
+func (r *RingSink) DrainTo(w io.Writer, format Format) error {
+	for _, entry := range r.Entries() {
+		data, err := EncodeEntry(entry, format)
+		if err != nil {
+			return fmt.Errorf("notifyme: encoding entry: %w", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
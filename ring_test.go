@@ -0,0 +1,142 @@
+package notifyme
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ringEntries returns n entries with messages "0", "1", ...
+func ringEntries(n int) []Entry {
+	at := time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC)
+	entries := make([]Entry, n)
+	for i := range entries {
+		entries[i] = Entry{
+			Level: LevelWarn, Message: strconv.Itoa(i), Time: at.Add(time.Duration(i) * time.Second),
+			Caller: Caller{File: "app.go", Line: i + 1},
+		}
+	}
+	return entries
+}
+
+func TestRingSinkEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		written int
+		want    []string
+	}{
+		{"empty", 3, 0, nil},
+		{"partly filled", 3, 2, []string{"0", "1"}},
+		{"exactly full", 3, 3, []string{"0", "1", "2"}},
+		{"wrapped", 3, 7, []string{"4", "5", "6"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ring, err := NewRingSink(tt.size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, entry := range ringEntries(tt.written) {
+				ring.Write(entry)
+			}
+			var got []string
+			for _, entry := range ring.Entries() {
+				got = append(got, entry.Message)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("entries %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRingSinkInvalid(t *testing.T) {
+	for _, size := range []int{0, -1} {
+		if _, err := NewRingSink(size); err == nil {
+			t.Errorf("NewRingSink(%d) succeeded", size)
+		}
+	}
+}
+
+func TestRingSinkDrainTo(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+	}{
+		{"text", FormatText},
+		{"json", FormatJSON},
+		{"ecs", FormatECS},
+		{"proto", FormatProto},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ring, _ := NewRingSink(3)
+			for _, entry := range ringEntries(5) {
+				ring.Write(entry)
+			}
+			var want bytes.Buffer
+			for _, entry := range ringEntries(5)[2:] {
+				data, err := EncodeEntry(entry, tt.format)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want.Write(data)
+			}
+
+			for i := 0; i < 2; i++ {
+				var buf bytes.Buffer
+				if err := ring.DrainTo(&buf, tt.format); err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(buf.Bytes(), want.Bytes()) {
+					t.Errorf("drain %d wrote %q, want %q", i, buf.String(), want.String())
+				}
+			}
+			if got := len(ring.Entries()); got != 3 {
+				t.Errorf("DrainTo left %d entries, want 3", got)
+			}
+		})
+	}
+}
+
+func TestRingSinkDrainToFromLogger(t *testing.T) {
+	var out bytes.Buffer
+	logger := newWriterLogger(LevelInfo, &out)
+	logger.now = func() time.Time { return fixedTime }
+	ring, _ := NewRingSink(10)
+	logger.AddSink(ring)
+	logger.SetWriterLevel(LevelError)
+	logger.With("user", "ann").Log(LevelInfo, "kept in memory only")
+	logger.Log(LevelError, "written too")
+
+	var dump bytes.Buffer
+	if err := ring.DrainTo(&dump, FormatText); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(dump.String(), "\n"), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "[INFO] kept in memory only user=ann") || !strings.HasSuffix(lines[1], "[ERROR] written too") {
+		t.Errorf("dump = %q", dump.String())
+	}
+	if !strings.HasSuffix(dump.String(), out.String()) {
+		t.Errorf("dumped ERROR line differs from the written one %q", out.String())
+	}
+}
+
+func TestRingSinkDrainToErrors(t *testing.T) {
+	ring, _ := NewRingSink(2)
+	for _, entry := range ringEntries(2) {
+		ring.Write(entry)
+	}
+	if err := ring.DrainTo(failingWriter{}, FormatText); !errors.Is(err, errDiskFull) {
+		t.Errorf("DrainTo to a failing writer = %v, want %v", err, errDiskFull)
+	}
+	if err := ring.DrainTo(io.Discard, Format(99)); err == nil {
+		t.Error("DrainTo accepted an unknown format")
+	}
+}
@@ -0,0 +1,41 @@
+package notifyme
+
+import "io"
+
+// fieldRoute is an extra output for entries carrying a given field value
+type fieldRoute struct {
+	key   string
+	value string
+	w     io.Writer
+}
+
+// AddRouteByField additionally writes entries whose field key renders as
+// value to w, in the same format as the primary output. For example,
+// entries with component=billing can be copied to billing.log while every
+// entry still goes to the main output. Routes are copied to clones.
+func (l *Logger) AddRouteByField(key, value string, w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.routes = append(l.routes, fieldRoute{key: key, value: value, w: w})
+}
+
+// writeRoutes writes the entry to every route whose field matches. It must
+// be called with the logger mutex held.
+func (l *Logger) writeRoutes(entry Entry) {
+	for _, route := range l.routes {
+		if l.routeMatches(route, entry) {
+			l.writeTo(route.w, entry)
+		}
+	}
+}
+
+// routeMatches reports whether the entry has the route's field value. The
+// last field with the key wins, as it does in JSON output.
+func (l *Logger) routeMatches(route fieldRoute, entry Entry) bool {
+	for i := len(entry.Fields) - 1; i >= 0; i-- {
+		if entry.Fields[i].Key == route.key {
+			return l.formatValue(entry.Fields[i].Value) == route.value
+		}
+	}
+	return false
+}
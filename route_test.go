@@ -0,0 +1,63 @@
+package notifyme
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAddRouteByField(t *testing.T) {
+	tests := []struct {
+		name   string
+		log    func(*Logger)
+		routed bool
+	}{
+		{"logger field", func(l *Logger) { l.With("component", "billing").Log(LevelInfo, "invoice sent") }, true},
+		{"event field", func(l *Logger) { l.NewEvent(LevelWarn).Str("component", "billing").Msg("invoice late") }, true},
+		{"other value", func(l *Logger) { l.With("component", "search").Log(LevelInfo, "query") }, false},
+		{"no field", func(l *Logger) { l.Log(LevelInfo, "plain") }, false},
+		{"value prefix", func(l *Logger) { l.With("component", "billing-eu").Log(LevelInfo, "invoice sent") }, false},
+		{"other key", func(l *Logger) { l.With("team", "billing").Log(LevelInfo, "standup") }, false},
+		{"last field wins", func(l *Logger) {
+			l.With("component", "billing").NewEvent(LevelInfo).Str("component", "search").Msg("overridden")
+		}, false},
+		{"non-string value rendered", func(l *Logger) { l.With("shard", 7).Log(LevelInfo, "shard seven") }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var main, billing bytes.Buffer
+			logger := newWriterLogger(LevelInfo, &main)
+			logger.AddRouteByField("component", "billing", &billing)
+			logger.AddRouteByField("shard", "7", &billing)
+			tt.log(logger)
+
+			if main.Len() == 0 {
+				t.Error("entry missing from the main output")
+			}
+			if tt.routed && billing.String() != main.String() {
+				t.Errorf("routed output %q, want the main line %q", billing.String(), main.String())
+			}
+			if !tt.routed && billing.Len() != 0 {
+				t.Errorf("unmatched entry routed: %q", billing.String())
+			}
+		})
+	}
+}
+
+func TestAddRouteByFieldFormatAndClones(t *testing.T) {
+	var main, billing bytes.Buffer
+	logger := newWriterLogger(LevelInfo, &main)
+	if err := logger.Configure(WithFormat(FormatJSON)); err != nil {
+		t.Fatal(err)
+	}
+	logger.AddRouteByField("component", "billing", &billing)
+	logger.Named("payments").With("component", "billing").Log(LevelError, "charge failed")
+	logger.Log(LevelInfo, "unrelated")
+
+	if got := strings.Count(billing.String(), "\n"); got != 1 {
+		t.Fatalf("routed %d lines, want 1: %q", got, billing.String())
+	}
+	if !strings.HasPrefix(billing.String(), "{") || !strings.Contains(billing.String(), `"msg":"charge failed"`) {
+		t.Errorf("routed line %q is not in the JSON format", billing.String())
+	}
+}
@@ -0,0 +1,131 @@
+package notifyme
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// keySampler keeps a separate token bucket for every key returned by keyFn,
+// so a hot key cannot use up the budget of quieter ones. Clones share it,
+// so it has its own mutex.
+type keySampler struct {
+	mu        sync.Mutex
+	keyFn     func(Entry) string
+	rate      float64
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// tokenBucket holds the remaining budget for a single sampling key and the
+// number of entries dropped since the last one that was let through
+type tokenBucket struct {
+	tokens  float64
+	last    time.Time
+	dropped int
+}
+
+// droppedBucketTTL bounds how long a bucket with uncounted drops is kept
+// after its key goes quiet
+const droppedBucketTTL = time.Minute
+
+// WithSamplingByKey limits every distinct key returned by keyFn to
+// perKeyPerSecond entries per second. Entries over budget are dropped.
+// Clones share the budgets; applying the option to a clone gives it a
+// sampler of its own. ERROR and CRITICAL entries are not sampled unless
+// WithSamplingExemptLevels says otherwise.
+func WithSamplingByKey(keyFn func(Entry) string, perKeyPerSecond int) Option {
+	return func(l *Logger) error {
+		if keyFn == nil {
+			return errors.New("notifyme: sampling key function must not be nil")
+		}
+		if perKeyPerSecond <= 0 {
+			return errors.New("notifyme: sampling rate must be positive")
+		}
+		l.sampler = newKeySampler(keyFn, perKeyPerSecond)
+		return nil
+	}
+}
+
+// WithSamplingExemptLevels sets the levels that sampling never drops,
+// replacing the default of LevelError and LevelCritical; with no levels
+// every level is sampled
+func WithSamplingExemptLevels(levels ...int) Option {
+	return func(l *Logger) error {
+		exempt := make(map[int]bool, len(levels))
+		for _, level := range levels {
+			exempt[level] = true
+		}
+		l.opts.samplingExempt = exempt
+		return nil
+	}
+}
+
+// sampled reports whether sampling applies to entries at level. It must be
+// called with the logger mutex held.
+func (l *Logger) sampled(level int) bool {
+	if l.sampler == nil {
+		return false
+	}
+	if l.opts.samplingExempt == nil {
+		return level < LevelError
+	}
+	return !l.opts.samplingExempt[level]
+}
+
+func newKeySampler(keyFn func(Entry) string, perKeyPerSecond int) *keySampler {
+	return &keySampler{
+		keyFn:   keyFn,
+		rate:    float64(perKeyPerSecond),
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// allow reports whether the entry fits in its key's budget and, if so, how
+// many entries it stands for including siblings dropped before it
+func (s *keySampler) allow(entry Entry) (bool, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := entry.Time
+	s.evictIdle(now)
+
+	key := s.keyFn(entry)
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: s.rate, last: now}
+		s.buckets[key] = bucket
+	} else {
+		bucket.tokens += now.Sub(bucket.last).Seconds() * s.rate
+		if bucket.tokens > s.rate {
+			bucket.tokens = s.rate
+		}
+		bucket.last = now
+	}
+
+	if bucket.tokens < 1 {
+		bucket.dropped++
+		return false, 0
+	}
+	bucket.tokens--
+	timesSeen := bucket.dropped + 1
+	bucket.dropped = 0
+	return true, timesSeen
+}
+
+// evictIdle drops buckets that have been idle for a full second. Such a
+// bucket would be completely refilled anyway, so forgetting it is lossless
+// and keeps memory bounded by the number of recently active keys. Buckets
+// still holding a drop count are kept longer so the next entry for the key
+// can report it.
+func (s *keySampler) evictIdle(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Second {
+		return
+	}
+	for key, bucket := range s.buckets {
+		idle := now.Sub(bucket.last)
+		if (bucket.dropped == 0 && idle >= time.Second) || idle >= droppedBucketTTL {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
@@ -0,0 +1,268 @@
+package notifyme
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// endpointKey is a sampling key function using the "endpoint" field
+func endpointKey(entry Entry) string {
+	value, _ := lastField(entry.Fields, "endpoint")
+	s, _ := value.(string)
+	return s
+}
+
+// logEndpoint logs an INFO entry with an endpoint field
+func logEndpoint(logger *Logger, message, endpoint string) {
+	logger.NewEvent(LevelInfo).Str("endpoint", endpoint).Msg(message)
+}
+
+// newSamplingTestLogger returns a logger whose clock is read from *now and
+// a ring sink receiving everything it lets through
+func newSamplingTestLogger(t *testing.T, now *time.Time, opts ...Option) (*Logger, *RingSink) {
+	t.Helper()
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	logger.now = func() time.Time { return *now }
+	ring, _ := NewRingSink(1000)
+	logger.AddSink(ring)
+	if err := logger.Configure(opts...); err != nil {
+		t.Fatal(err)
+	}
+	return logger, ring
+}
+
+// countByEndpoint counts the entries per endpoint field
+func countByEndpoint(entries []Entry) map[string]int {
+	counts := make(map[string]int)
+	for _, entry := range entries {
+		counts[endpointKey(entry)]++
+	}
+	return counts
+}
+
+func TestSamplingByKeyIndependentBudgets(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	logger, ring := newSamplingTestLogger(t, &now, WithSamplingByKey(endpointKey, 5))
+
+	// /hot is logged far over its budget, /quiet stays within it
+	sent := map[string]int{"/hot": 100, "/quiet": 3}
+	for i := 0; i < sent["/hot"]; i++ {
+		logEndpoint(logger, "request", "/hot")
+		if i < sent["/quiet"] {
+			logEndpoint(logger, "request", "/quiet")
+		}
+	}
+
+	got := countByEndpoint(ring.Entries())
+	if got["/hot"] != 5 {
+		t.Errorf("/hot let through %d entries, want its budget of 5", got["/hot"])
+	}
+	if got["/quiet"] != 3 {
+		t.Errorf("/quiet let through %d entries, want all 3", got["/quiet"])
+	}
+}
+
+func TestSamplingByKeyRefill(t *testing.T) {
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		want    int
+	}{
+		{"no time passed", 0, 0},
+		{"tenth of a second", 100 * time.Millisecond, 1},
+		{"half a second", 500 * time.Millisecond, 5},
+		{"long pause caps at the budget", time.Hour, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			logger, ring := newSamplingTestLogger(t, &now, WithSamplingByKey(endpointKey, 10))
+			for i := 0; i < 10; i++ {
+				logEndpoint(logger, "burst", "/a")
+			}
+			now = now.Add(tt.elapsed)
+			for i := 0; i < 20; i++ {
+				logEndpoint(logger, "after", "/a")
+			}
+			if got := len(ring.Entries()) - 10; got != tt.want {
+				t.Errorf("let through %d entries after %v, want %d", got, tt.elapsed, tt.want)
+			}
+		})
+	}
+}
+
+func TestSamplingByKeyEvictsIdleKeys(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	logger, _ := newSamplingTestLogger(t, &now, WithSamplingByKey(endpointKey, 1))
+	for i := 0; i < 100; i++ {
+		logEndpoint(logger, "request", "/"+strconv.Itoa(i))
+	}
+	if n := len(logger.sampler.buckets); n != 100 {
+		t.Fatalf("tracking %d keys, want 100", n)
+	}
+
+	now = now.Add(2 * time.Second)
+	logEndpoint(logger, "request", "/new")
+	if n := len(logger.sampler.buckets); n != 1 {
+		t.Errorf("tracking %d keys after they went idle, want 1", n)
+	}
+}
+
+func TestSamplingByKeySharedWithDerivedLoggers(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	logger, ring := newSamplingTestLogger(t, &now, WithSamplingByKey(endpointKey, 5))
+	derived := []*Logger{logger, logger.Clone(), logger.WithFields(map[string]interface{}{"req": 1})}
+	for i := 0; i < 10; i++ {
+		for _, l := range derived {
+			logEndpoint(l, "request", "/hot")
+		}
+	}
+	if got := len(ring.Entries()); got != 5 {
+		t.Errorf("derived loggers let through %d entries, want the shared budget of 5", got)
+	}
+}
+
+func TestSamplingByKeyOnCloneIsIndependent(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	logger, ring := newSamplingTestLogger(t, &now, WithSamplingByKey(endpointKey, 1))
+	clone := logger.Clone()
+	if err := clone.Configure(WithSamplingByKey(endpointKey, 2)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		logEndpoint(logger, "original", "/a")
+		logEndpoint(clone, "clone", "/a")
+	}
+	counts := make(map[string]int)
+	for _, entry := range ring.Entries() {
+		counts[entry.Message]++
+	}
+	if counts["original"] != 1 || counts["clone"] != 2 {
+		t.Errorf("let through %v, want 1 original and 2 clone entries", counts)
+	}
+}
+
+func TestWithSamplingByKeyInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		keyFn func(Entry) string
+		rate  int
+	}{
+		{"nil key function", nil, 1},
+		{"zero rate", endpointKey, 0},
+		{"negative rate", endpointKey, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+			if err := logger.Configure(WithSamplingByKey(tt.keyFn, tt.rate)); err == nil {
+				t.Error("Configure accepted the sampling settings")
+			}
+		})
+	}
+}
+
+func TestSamplingTimesSeen(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	logger, ring := newSamplingTestLogger(t, &now, WithSamplingByKey(endpointKey, 1))
+	// Ten entries a second against a budget of one: 1-in-10 sampling
+	for second := 0; second < 3; second++ {
+		for i := 0; i < 10; i++ {
+			logEndpoint(logger, "request", "/hot")
+		}
+		now = now.Add(time.Second)
+	}
+	logEndpoint(logger, "request", "/hot")
+
+	entries := ring.Entries()
+	want := []int{1, 10, 10, 10}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i, entry := range entries {
+		if entry.TimesSeen != want[i] {
+			t.Errorf("entry %d TimesSeen = %d, want %d", i, entry.TimesSeen, want[i])
+		}
+	}
+}
+
+func TestSamplingTimesSeenOutput(t *testing.T) {
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{FormatText, " times_seen=3"},
+		{FormatJSON, `"times_seen":3`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			var buf bytes.Buffer
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			logger := newWriterLogger(LevelInfo, &buf)
+			logger.now = func() time.Time { return now }
+			if err := logger.Configure(WithFormat(tt.format), WithSamplingByKey(endpointKey, 1)); err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 3; i++ {
+				logEndpoint(logger, "request", "/a")
+			}
+			buf.Reset()
+			now = now.Add(time.Second)
+			logEndpoint(logger, "request", "/a")
+			if !bytes.Contains(buf.Bytes(), []byte(tt.want)) {
+				t.Errorf("output %q lacks %s", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWithSamplingExemptLevels(t *testing.T) {
+	byLevel := func(entry Entry) string { return levelName(entry.Level) }
+	tests := []struct {
+		name string
+		opts []Option
+		want map[int]int
+	}{
+		{"default exempts error and critical", []Option{WithSamplingByKey(byLevel, 1)},
+			map[int]int{LevelInfo: 1, LevelWarn: 1, LevelError: 50, LevelCritical: 50}},
+		{"custom levels", []Option{WithSamplingByKey(byLevel, 1), WithSamplingExemptLevels(LevelWarn, LevelCritical)},
+			map[int]int{LevelInfo: 1, LevelWarn: 50, LevelError: 1, LevelCritical: 50}},
+		{"no levels samples everything", []Option{WithSamplingByKey(byLevel, 1), WithSamplingExemptLevels()},
+			map[int]int{LevelInfo: 1, LevelWarn: 1, LevelError: 1, LevelCritical: 1}},
+		{"set before sampling", []Option{WithSamplingExemptLevels(LevelInfo), WithSamplingByKey(byLevel, 1)},
+			map[int]int{LevelInfo: 50, LevelWarn: 1, LevelError: 1, LevelCritical: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC)
+			logger, ring := newSamplingTestLogger(t, &now, tt.opts...)
+			for i := 0; i < 50; i++ {
+				for _, level := range []int{LevelInfo, LevelWarn, LevelError, LevelCritical} {
+					logger.Log(level, "flood")
+				}
+			}
+			got := make(map[int]int)
+			for _, entry := range ring.Entries() {
+				got[entry.Level]++
+			}
+			for level, want := range tt.want {
+				if got[level] != want {
+					t.Errorf("%s: %d entries passed, want %d", levelName(level), got[level], want)
+				}
+			}
+		})
+	}
+}
+
+func TestWithSamplingExemptLevelsNoSampler(t *testing.T) {
+	now := time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC)
+	logger, ring := newSamplingTestLogger(t, &now, WithSamplingExemptLevels(LevelError))
+	for i := 0; i < 10; i++ {
+		logger.Log(LevelInfo, "not sampled")
+	}
+	if got := len(ring.Entries()); got != 10 {
+		t.Errorf("%d entries passed without a sampler, want 10", got)
+	}
+}
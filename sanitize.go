@@ -0,0 +1,101 @@
+package notifyme
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// WithSanitizeControlChars escapes control characters in text output so
+// untrusted input cannot inject terminal escape sequences. C0 and C1
+// control characters, DEL, bidirectional controls and invalid UTF-8 are
+// written as \xNN or \uNNNN; newlines and tabs are kept. Recommended when
+// logging user input. JSON output already escapes them.
+func WithSanitizeControlChars() Option {
+	return func(l *Logger) error {
+		l.opts.sanitizeControl = true
+		return nil
+	}
+}
+
+// sanitizeControlChars escapes the characters described in
+// WithSanitizeControlChars
+func sanitizeControlChars(s string) string {
+	if !needsSanitizing(s) {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s) + 16)
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&b, `\x%02x`, s[i])
+		case r == '\n' || r == '\t':
+			b.WriteRune(r)
+		case r < 0x80 && unicode.IsControl(r):
+			fmt.Fprintf(&b, `\x%02x`, r)
+		case unicode.IsControl(r) || unicode.Is(unicode.Bidi_Control, r):
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// needsSanitizing reports whether s contains anything to escape
+func needsSanitizing(s string) bool {
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if (r == utf8.RuneError && size == 1) ||
+			(r != '\n' && r != '\t' && unicode.IsControl(r)) ||
+			unicode.Is(unicode.Bidi_Control, r) {
+			return true
+		}
+		i += size
+	}
+	return false
+}
+
+// WithEscapeNonASCII escapes every non-ASCII character in text output as
+// \uNNNN, or \UNNNNNNNN beyond the Basic Multilingual Plane, and invalid
+// UTF-8 as \xNN, for consoles that mangle UTF-8. Text output is raw UTF-8
+// by default; JSON output and sinks are not affected.
+func WithEscapeNonASCII() Option {
+	return func(l *Logger) error {
+		l.opts.escapeNonASCII = true
+		return nil
+	}
+}
+
+// escapeNonASCII escapes the characters described in WithEscapeNonASCII
+func escapeNonASCII(s string) string {
+	i := 0
+	for i < len(s) && s[i] < utf8.RuneSelf {
+		i++
+	}
+	if i == len(s) {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s) + 16)
+	b.WriteString(s[:i])
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r < utf8.RuneSelf:
+			b.WriteByte(byte(r))
+		case r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&b, `\x%02x`, s[i])
+		case r > 0xffff:
+			fmt.Fprintf(&b, `\U%08x`, r)
+		default:
+			fmt.Fprintf(&b, `\u%04x`, r)
+		}
+		i += size
+	}
+	return b.String()
+}
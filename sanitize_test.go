@@ -0,0 +1,134 @@
+package notifyme
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeControlChars(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "hello world", "hello world"},
+		{"ansi color", "\x1b[31mred\x1b[0m", `\x1b[31mred\x1b[0m`},
+		{"clear screen", "\x1b[2J\x1b[H", `\x1b[2J\x1b[H`},
+		{"bell and backspace", "a\x07b\x08c", `a\x07b\x08c`},
+		{"carriage return", "ok\rFAKE", `ok\x0dFAKE`},
+		{"newline and tab kept", "a\nb\tc", "a\nb\tc"},
+		{"delete", "a\x7fb", `a\x7fb`},
+		{"c1 control", "a\u009bb", `a\u009bb`},
+		{"bidi override", "user\u202etxt.exe", `user\u202etxt.exe`},
+		{"invalid utf-8", "a\xffb", `a\xffb`},
+		{"unicode kept", "héllo 世界", "héllo 世界"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeControlChars(tt.in); got != tt.want {
+				t.Errorf("sanitizeControlChars(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithSanitizeControlChars(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		want    string
+		rawKept bool
+	}{
+		{"off by default", nil, "\x1b[31mred", true},
+		{"on", []Option{WithSanitizeControlChars()}, `\x1b[31mred`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newWriterLogger(LevelInfo, &buf)
+			if err := logger.Configure(tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			logger.With("input", "\x1b[31mred").Log(LevelInfo, "user said", "\x1b[31mred")
+			out := buf.String()
+			if strings.Count(out, tt.want) != 2 {
+				t.Errorf("output %q does not contain %q in the message and the field", out, tt.want)
+			}
+			if kept := strings.Contains(out, "\x1b"); kept != tt.rawKept {
+				t.Errorf("output %q: raw escape kept = %v, want %v", out, kept, tt.rawKept)
+			}
+		})
+	}
+}
+
+func TestEscapeNonASCII(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"ascii", "hello world", "hello world"},
+		{"latin", "héllo", `h\u00e9llo`},
+		{"cjk", "世界", `\u4e16\u754c`},
+		{"beyond the bmp", "ok 🚀", `ok \U0001f680`},
+		{"invalid utf-8", "a\xffb", `a\xffb`},
+		{"mixed", "naïve\tcafé\n", "na\\u00efve\tcaf\\u00e9\n"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeNonASCII(tt.in); got != tt.want {
+				t.Errorf("escapeNonASCII(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithEscapeNonASCII(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{"raw utf-8 by default", nil, "Zürich"},
+		{"escaped", []Option{WithEscapeNonASCII()}, `Z\u00fcrich`},
+		{"escaped after sanitizing", []Option{WithEscapeNonASCII(), WithSanitizeControlChars()}, `Z\u00fcrich`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newWriterLogger(LevelInfo, &buf)
+			ring, _ := NewRingSink(2)
+			logger.AddSink(ring)
+			if err := logger.Configure(tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			logger.With("city", "Zürich").Log(LevelInfo, "moved to", "Zürich")
+			logger.NewEvent(LevelInfo).Str("city", "Zürich").Msg("moved to Zürich")
+
+			for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+				if strings.Count(line, tt.want) != 2 {
+					t.Errorf("line %q does not contain %q in the message and the field", line, tt.want)
+				}
+			}
+			for _, entry := range ring.Entries() {
+				if entry.Message != "moved to Zürich" {
+					t.Errorf("sink message %q, want it unescaped", entry.Message)
+				}
+			}
+		})
+	}
+}
+
+func TestWithEscapeNonASCIIKeepsJSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newWriterLogger(LevelInfo, &buf)
+	if err := logger.Configure(WithEscapeNonASCII(), WithFormat(FormatJSON)); err != nil {
+		t.Fatal(err)
+	}
+	logger.Log(LevelInfo, "moved to Zürich")
+	if !strings.Contains(buf.String(), `"msg":"moved to Zürich"`) {
+		t.Errorf("JSON line %q, want raw UTF-8", buf.String())
+	}
+}
@@ -0,0 +1,15 @@
+module github.com/AmosSParker/NotifyMe/sentrysink
+
+go 1.25.0
+
+require (
+	github.com/AmosSParker/NotifyMe v0.0.0
+	github.com/getsentry/sentry-go v0.49.0
+)
+
+require (
+	golang.org/x/sys v0.46.0 // indirect
+	golang.org/x/text v0.39.0 // indirect
+)
+
+replace github.com/AmosSParker/NotifyMe => ../
@@ -0,0 +1,171 @@
+// Package sentrysink provides a notifyme.Sink reporting severe entries to
+// Sentry. It lives in its own module so the core package does not depend on
+// the Sentry SDK.
+package sentrysink
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	notifyme "github.com/AmosSParker/NotifyMe"
+	"github.com/getsentry/sentry-go"
+)
+
+// defaultFlushTimeout bounds how long Close waits for queued events
+const defaultFlushTimeout = 5 * time.Second
+
+// Config configures a Sink
+type Config struct {
+	// DSN is the Sentry project DSN
+	DSN string
+	// MinLevel is the lowest level reported. It defaults to LevelError;
+	// since LevelInfo is the zero value, INFO entries cannot be reported.
+	MinLevel int
+	// TagKeys lists field keys sent as tags, which Sentry indexes for
+	// search. All other fields go into the event's "fields" context.
+	TagKeys []string
+	// Environment and Release are attached to every event when set
+	Environment string
+	Release     string
+	// Transport overrides the SDK's HTTP transport, e.g. in tests
+	Transport sentry.Transport
+	// FlushTimeout bounds how long Close waits for queued events
+	FlushTimeout time.Duration
+}
+
+// Sink sends entries at or above the minimum level to Sentry as events.
+// An "error" field becomes the exception and a "stack" field, as attached by
+// notifyme.WithStackTraceLevel, its stack trace.
+type Sink struct {
+	client   *sentry.Client
+	config   Config
+	tagKeys  map[string]bool
+	minLevel int
+}
+
+// New creates a Sink
+func New(config Config) (*Sink, error) {
+	if config.DSN == "" && config.Transport == nil {
+		return nil, errors.New("sentrysink: DSN must not be empty")
+	}
+	if config.FlushTimeout <= 0 {
+		config.FlushTimeout = defaultFlushTimeout
+	}
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:         config.DSN,
+		Environment: config.Environment,
+		Release:     config.Release,
+		Transport:   config.Transport,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sentrysink: creating client: %w", err)
+	}
+	s := &Sink{client: client, config: config, tagKeys: make(map[string]bool), minLevel: config.MinLevel}
+	if s.minLevel == notifyme.LevelInfo {
+		s.minLevel = notifyme.LevelError
+	}
+	for _, key := range config.TagKeys {
+		s.tagKeys[key] = true
+	}
+	return s, nil
+}
+
+// Write captures the entry as a Sentry event if its level qualifies. The
+// SDK queues events and sends them in the background.
+func (s *Sink) Write(entry notifyme.Entry) error {
+	if entry.Level < s.minLevel {
+		return nil
+	}
+	s.client.CaptureEvent(s.event(entry), nil, nil)
+	return nil
+}
+
+// Close waits for queued events to be sent
+func (s *Sink) Close() error {
+	if !s.client.Flush(s.config.FlushTimeout) {
+		return errors.New("sentrysink: timed out flushing events")
+	}
+	return nil
+}
+
+// event converts an entry into a Sentry event
+func (s *Sink) event(entry notifyme.Entry) *sentry.Event {
+	event := sentry.NewEvent()
+	event.Level = sentryLevel(entry.Level)
+	event.Message = entry.Message
+	event.Timestamp = entry.Time
+	event.Logger = entry.Name
+
+	fields := sentry.Context{"caller": entry.Caller.String()}
+	var errText, stack string
+	for _, field := range entry.Fields {
+		switch {
+		case field.Key == "stack":
+			stack = fmt.Sprint(field.Value)
+		case field.Key == "error":
+			errText = fmt.Sprint(field.Value)
+			fields[field.Key] = errText
+		case s.tagKeys[field.Key]:
+			event.Tags[field.Key] = fmt.Sprint(field.Value)
+		default:
+			fields[field.Key] = field.Value
+		}
+	}
+	event.Contexts["fields"] = fields
+
+	if errText != "" || stack != "" {
+		exception := sentry.Exception{Type: entry.Message, Value: errText}
+		if stack != "" {
+			exception.Stacktrace = parseStack(stack)
+		}
+		event.Exception = []sentry.Exception{exception}
+	}
+	return event
+}
+
+// sentryLevel maps a notifyme level to a Sentry level
+func sentryLevel(level int) sentry.Level {
+	switch level {
+	case notifyme.LevelInfo:
+		return sentry.LevelInfo
+	case notifyme.LevelWarn:
+		return sentry.LevelWarning
+	case notifyme.LevelError:
+		return sentry.LevelError
+	case notifyme.LevelCritical:
+		return sentry.LevelFatal
+	}
+	if level > notifyme.LevelCritical {
+		return sentry.LevelFatal
+	}
+	return sentry.LevelDebug
+}
+
+// parseStack converts a stack written by WithStackTraceLevel, pairs of
+// "function" and "\tfile:line" lines with the innermost call first, into a
+// Sentry stack trace, which lists the outermost call first
+func parseStack(stack string) *sentry.Stacktrace {
+	lines := strings.Split(stack, "\n")
+	var frames []sentry.Frame
+	for i := 0; i+1 < len(lines); i += 2 {
+		frame := sentry.Frame{Function: lines[i], InApp: true}
+		location := strings.TrimSpace(lines[i+1])
+		if colon := strings.LastIndexByte(location, ':'); colon >= 0 {
+			frame.AbsPath = location[:colon]
+			frame.Lineno, _ = strconv.Atoi(location[colon+1:])
+			if slash := strings.LastIndexByte(frame.AbsPath, '/'); slash >= 0 {
+				frame.Filename = frame.AbsPath[slash+1:]
+			} else {
+				frame.Filename = frame.AbsPath
+			}
+		}
+		frames = append(frames, frame)
+	}
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+	return &sentry.Stacktrace{Frames: frames}
+}
@@ -0,0 +1,192 @@
+package sentrysink_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	notifyme "github.com/AmosSParker/NotifyMe"
+	"github.com/AmosSParker/NotifyMe/sentrysink"
+	"github.com/getsentry/sentry-go"
+)
+
+// testDSN is a syntactically valid DSN; events never leave the test
+// transport
+const testDSN = "https://public@sentry.example.com/1"
+
+// recordingTransport keeps the events the client sends
+type recordingTransport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (t *recordingTransport) Flush(time.Duration) bool              { return true }
+func (t *recordingTransport) FlushWithContext(context.Context) bool { return true }
+func (t *recordingTransport) Configure(sentry.ClientOptions)        {}
+func (t *recordingTransport) Close()                                {}
+func (t *recordingTransport) SendEvent(event *sentry.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, event)
+}
+
+// Events returns the events sent so far
+func (t *recordingTransport) Events() []*sentry.Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*sentry.Event(nil), t.events...)
+}
+
+// newTestSink returns a sink recording its events in the returned transport
+func newTestSink(t *testing.T, config sentrysink.Config) (*sentrysink.Sink, *recordingTransport) {
+	t.Helper()
+	transport := &recordingTransport{}
+	config.DSN = testDSN
+	config.Transport = transport
+	sink, err := sentrysink.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sink, transport
+}
+
+func TestSinkLevels(t *testing.T) {
+	tests := []struct {
+		name     string
+		minLevel int
+		level    int
+		sent     bool
+		want     sentry.Level
+	}{
+		{"default skips warn", 0, notifyme.LevelWarn, false, ""},
+		{"default sends error", 0, notifyme.LevelError, true, sentry.LevelError},
+		{"critical is fatal", 0, notifyme.LevelCritical, true, sentry.LevelFatal},
+		{"warn threshold", notifyme.LevelWarn, notifyme.LevelWarn, true, sentry.LevelWarning},
+		{"critical threshold skips error", notifyme.LevelCritical, notifyme.LevelError, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink, transport := newTestSink(t, sentrysink.Config{MinLevel: tt.minLevel})
+			sink.Write(notifyme.Entry{Level: tt.level, Message: "checked"})
+			if err := sink.Close(); err != nil {
+				t.Fatal(err)
+			}
+			events := transport.Events()
+			if sent := len(events) == 1; sent != tt.sent {
+				t.Fatalf("sent %d events, want sent = %v", len(events), tt.sent)
+			}
+			if tt.sent && events[0].Level != tt.want {
+				t.Errorf("level = %q, want %q", events[0].Level, tt.want)
+			}
+		})
+	}
+}
+
+func TestSinkEvent(t *testing.T) {
+	at := time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC)
+	sink, transport := newTestSink(t, sentrysink.Config{
+		TagKeys: []string{"region"}, Environment: "staging", Release: "v1.2.3",
+	})
+	sink.Write(notifyme.Entry{
+		Level: notifyme.LevelError, Message: "charge failed", Time: at, Name: "billing",
+		Caller: notifyme.Caller{File: "/src/billing/charge.go", Line: 42},
+		Fields: []notifyme.Field{
+			{Key: "region", Value: "eu"},
+			{Key: "order", Value: 17},
+			{Key: "error", Value: errors.New("card declined")},
+			{Key: "stack", Value: "app.charge\n\t/src/billing/charge.go:42\napp.main\n\t/src/main.go:9"},
+		},
+	})
+	sink.Close()
+
+	events := transport.Events()
+	if len(events) != 1 {
+		t.Fatalf("sent %d events, want 1", len(events))
+	}
+	event := events[0]
+	if event.Message != "charge failed" || !event.Timestamp.Equal(at) || event.Logger != "billing" {
+		t.Errorf("message %q time %v logger %q", event.Message, event.Timestamp, event.Logger)
+	}
+	if event.Environment != "staging" || event.Release != "v1.2.3" {
+		t.Errorf("environment %q release %q", event.Environment, event.Release)
+	}
+	if event.Tags["region"] != "eu" {
+		t.Errorf("tags = %v, want region=eu", event.Tags)
+	}
+	fields := event.Contexts["fields"]
+	if fields["order"] != 17 || fields["error"] != "card declined" || fields["caller"] != "charge.go:42" {
+		t.Errorf("fields context = %v", fields)
+	}
+	if _, ok := fields["region"]; ok {
+		t.Error("tag field also sent in the fields context")
+	}
+	if _, ok := fields["stack"]; ok {
+		t.Error("stack sent in the fields context")
+	}
+
+	if len(event.Exception) != 1 {
+		t.Fatalf("got %d exceptions, want 1", len(event.Exception))
+	}
+	exception := event.Exception[0]
+	if exception.Type != "charge failed" || exception.Value != "card declined" {
+		t.Errorf("exception type %q value %q", exception.Type, exception.Value)
+	}
+	frames := exception.Stacktrace.Frames
+	want := []sentry.Frame{
+		{Function: "app.main", AbsPath: "/src/main.go", Filename: "main.go", Lineno: 9, InApp: true},
+		{Function: "app.charge", AbsPath: "/src/billing/charge.go", Filename: "charge.go", Lineno: 42, InApp: true},
+	}
+	if len(frames) != len(want) {
+		t.Fatalf("got %d frames, want %d", len(frames), len(want))
+	}
+	for i := range want {
+		got := frames[i]
+		if got.Function != want[i].Function || got.AbsPath != want[i].AbsPath || got.Filename != want[i].Filename ||
+			got.Lineno != want[i].Lineno || !got.InApp {
+			t.Errorf("frame %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestSinkWithoutError(t *testing.T) {
+	sink, transport := newTestSink(t, sentrysink.Config{})
+	sink.Write(notifyme.Entry{Level: notifyme.LevelError, Message: "plain"})
+	sink.Close()
+	if events := transport.Events(); len(events) != 1 || len(events[0].Exception) != 0 {
+		t.Errorf("events = %+v, want one without an exception", events)
+	}
+}
+
+func TestSinkThroughLogger(t *testing.T) {
+	sink, transport := newTestSink(t, sentrysink.Config{})
+	logger := notifyme.NewWriterLogger(notifyme.LevelInfo, io.Discard)
+	logger.AddSink(sink)
+	if err := logger.Configure(notifyme.WithStackTraceLevel(notifyme.LevelError, 4)); err != nil {
+		t.Fatal(err)
+	}
+	logger.Log(notifyme.LevelWarn, "not reported")
+	logger.NewEvent(notifyme.LevelError).Err(errors.New("timeout")).Msg("upstream failed")
+	logger.Close()
+
+	events := transport.Events()
+	if len(events) != 1 {
+		t.Fatalf("sent %d events, want 1", len(events))
+	}
+	exception := events[0].Exception
+	if len(exception) != 1 || exception[0].Value != "timeout" || exception[0].Stacktrace == nil {
+		t.Fatalf("exception = %+v, want the error with a stack", exception)
+	}
+	frames := exception[0].Stacktrace.Frames
+	if innermost := frames[len(frames)-1]; innermost.Filename != "sentry_test.go" {
+		t.Errorf("innermost frame = %+v, want the logging call", innermost)
+	}
+}
+
+func TestNewWithoutDSN(t *testing.T) {
+	if _, err := sentrysink.New(sentrysink.Config{}); err == nil {
+		t.Error("New accepted a config without DSN or transport")
+	}
+}
@@ -0,0 +1,135 @@
+package notifyme
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// seqReserveBlock is how many sequence numbers are reserved in the state
+// file at a time, so it is not rewritten for every entry
+const seqReserveBlock = 1000
+
+// sequence hands out increasing entry sequence numbers. Clones share it,
+// so it has its own mutex.
+type sequence struct {
+	mu       sync.Mutex
+	next     uint64
+	reserved uint64
+	path     string
+	owner    *Logger // the logger that saves the counter on Close
+}
+
+// WithSequenceNumbers adds a "seq" field numbering written entries 1, 2,
+// 3, ... in the order they are written. Entries dropped by filters,
+// sampling or validation do not use up a number, and clones share the
+// counter. It replaces any seq field of entries passed to WriteEntry.
+func WithSequenceNumbers() Option {
+	return func(l *Logger) error {
+		if l.seq == nil {
+			l.seq = &sequence{next: 1, owner: l}
+		}
+		return nil
+	}
+}
+
+// WithSequencePersistence enables sequence numbers that keep increasing
+// across restarts by storing the counter in the file at path. Numbers are
+// reserved in blocks, so after a crash the counter resumes past the last
+// reserved block, leaving a gap but never repeating a number; Close stores
+// the exact position. A missing file starts the counter fresh, and so does
+// an unreadable one after reporting it to the error handler.
+func WithSequencePersistence(path string) Option {
+	return func(l *Logger) error {
+		if path == "" {
+			return errors.New("notifyme: sequence file path must not be empty")
+		}
+		seq := &sequence{next: 1, path: path, owner: l}
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			next, parseErr := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+			if parseErr != nil || next == 0 {
+				l.errorHandler()(fmt.Errorf("notifyme: corrupt sequence file %s, starting at 1", path))
+				break
+			}
+			seq.next = next
+		case !errors.Is(err, os.ErrNotExist):
+			l.errorHandler()(fmt.Errorf("notifyme: reading sequence file: %w", err))
+		}
+		seq.reserved = seq.next
+		l.seq = seq
+		return nil
+	}
+}
+
+// numberEntry sets the entry's seq field if sequence numbers are enabled.
+// It must be called with the logger mutex held.
+func (l *Logger) numberEntry(entry *Entry) {
+	if l.seq == nil {
+		return
+	}
+	n, err := l.seq.take()
+	if err != nil {
+		l.errorHandler()(err)
+	}
+	entry.Fields = setField(entry.Fields, "seq", n)
+}
+
+// take returns the next number, reserving another block in the state file
+// when the current one is used up. A block counts as reserved only once it
+// is stored, so a failed store is retried by the next call and a crash
+// cannot hand out numbers the file does not cover.
+func (s *sequence) take() (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var err error
+	if s.path != "" && s.next >= s.reserved {
+		if err = s.store(s.next + seqReserveBlock); err == nil {
+			s.reserved = s.next + seqReserveBlock
+		}
+	}
+	n := s.next
+	s.next++
+	return n, err
+}
+
+// save stores the exact next number, so a clean restart continues without
+// a gap
+func (s *sequence) save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.path == "" {
+		return nil
+	}
+	if err := s.store(s.next); err != nil {
+		return err
+	}
+	s.reserved = s.next
+	return nil
+}
+
+// store atomically replaces the state file with next. It must be called
+// with s.mu held.
+func (s *sequence) store(next uint64) error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("notifyme: writing sequence file: %w", err)
+	}
+	_, err = tmp.WriteString(strconv.FormatUint(next, 10) + "\n")
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("notifyme: writing sequence file: %w", err)
+	}
+	return nil
+}
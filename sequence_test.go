@@ -0,0 +1,154 @@
+package notifyme
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newSequenceTestLogger returns a logger persisting its sequence counter in
+// path, collecting reported errors in errs, whose entries are kept in the
+// returned ring
+func newSequenceTestLogger(t *testing.T, path string, errs *[]error) (*Logger, *RingSink) {
+	t.Helper()
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	ring, _ := NewRingSink(10)
+	logger.AddSink(ring)
+	err := logger.Configure(
+		WithErrorHandler(func(err error) { *errs = append(*errs, err) }),
+		WithSequencePersistence(path),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger, ring
+}
+
+// lastSeq returns the seq field of the newest entry in ring
+func lastSeq(t *testing.T, ring *RingSink) uint64 {
+	t.Helper()
+	entries := ring.Entries()
+	if len(entries) == 0 {
+		t.Fatal("no entries")
+	}
+	seq, _ := lastField(entries[len(entries)-1].Fields, "seq")
+	n, ok := seq.(uint64)
+	if !ok {
+		t.Fatalf("seq = %#v, want a number", seq)
+	}
+	return n
+}
+
+func TestWithSequencePersistence(t *testing.T) {
+	tests := []struct {
+		name     string
+		state    string // initial file content, "" for no file
+		logged   int
+		close    bool
+		stored   string
+		resumeAt uint64
+		reported bool
+	}{
+		{"fresh start and clean restart", "", 3, true, "4\n", 4, false},
+		{"resume from stored counter", "41\n", 2, true, "43\n", 43, false},
+		{"crash skips to the reserved block", "", 3, false, "1001\n", 1001, false},
+		{"crash past a block", "", seqReserveBlock + 1, false, "2001\n", 2001, false},
+		{"corrupt file starts fresh", "not a number", 1, true, "2\n", 2, true},
+		{"zero starts fresh", "0\n", 1, true, "2\n", 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "seq")
+			if tt.state != "" {
+				if err := os.WriteFile(path, []byte(tt.state), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var errs []error
+			logger, ring := newSequenceTestLogger(t, path, &errs)
+			for i := 0; i < tt.logged; i++ {
+				logger.Log(LevelInfo, "entry")
+			}
+			if tt.close {
+				if err := logger.Close(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if reported := len(errs) > 0; reported != tt.reported {
+				t.Errorf("reported errors %v, want reported = %v", errs, tt.reported)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.stored {
+				t.Errorf("stored %q, want %q", data, tt.stored)
+			}
+
+			// A new logger stands in for the restarted process
+			var restartErrs []error
+			restarted, restartedRing := newSequenceTestLogger(t, path, &restartErrs)
+			restarted.Log(LevelInfo, "after restart")
+			if got := lastSeq(t, restartedRing); got != tt.resumeAt {
+				t.Errorf("resumed at %d, want %d", got, tt.resumeAt)
+			}
+			if before := lastSeq(t, ring); before >= tt.resumeAt {
+				t.Errorf("resumed at %d, not past the last number %d", tt.resumeAt, before)
+			}
+			if len(restartErrs) > 0 {
+				t.Errorf("restart reported %v", restartErrs)
+			}
+			restarted.Close()
+		})
+	}
+}
+
+func TestWithSequencePersistenceErrors(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	if err := logger.Configure(WithSequencePersistence("")); err == nil {
+		t.Error("Configure accepted an empty path")
+	}
+
+	var errs []error
+	path := filepath.Join(t.TempDir(), "missing", "seq")
+	logger, ring := newSequenceTestLogger(t, path, &errs)
+	logger.Log(LevelInfo, "unwritable")
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "writing sequence file") {
+		t.Errorf("reported %v, want the failed write", errs)
+	}
+	if got := lastSeq(t, ring); got != 1 {
+		t.Errorf("seq = %d, want numbering to go on despite the error", got)
+	}
+}
+
+func TestWithSequencePersistenceRetriesFailedStore(t *testing.T) {
+	var errs []error
+	dir := filepath.Join(t.TempDir(), "state")
+	path := filepath.Join(dir, "seq")
+	logger, ring := newSequenceTestLogger(t, path, &errs)
+	logger.Log(LevelInfo, "unwritable")
+	logger.Log(LevelInfo, "unwritable")
+	if len(errs) != 2 {
+		t.Errorf("reported %d errors, want the store retried for each entry", len(errs))
+	}
+
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	logger.Log(LevelInfo, "writable")
+	if got := lastSeq(t, ring); got != 3 {
+		t.Errorf("seq = %d, want 3", got)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("block not reserved once the store works: %v", err)
+	}
+	if got := string(data); got != "1003\n" {
+		t.Errorf("stored %q, want the block after 3 reserved", got)
+	}
+	if len(errs) != 2 {
+		t.Errorf("reported %v after the store recovered", errs[2:])
+	}
+}
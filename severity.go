@@ -0,0 +1,24 @@
+package notifyme
+
+// WithSeverityMapping sets the numeric severity reported for each level,
+// e.g. {LevelCritical: 2} for syslog's "critical". Levels missing from the
+// mapping report their own value.
+func WithSeverityMapping(mapping map[int]int) Option {
+	return func(l *Logger) error {
+		copied := make(map[int]int, len(mapping))
+		for level, severity := range mapping {
+			copied[level] = severity
+		}
+		l.opts.severities = copied
+		return nil
+	}
+}
+
+// severity returns the mapped severity for a level. It must be called with
+// the logger mutex held.
+func (l *Logger) severity(level int) int {
+	if severity, ok := l.opts.severities[level]; ok {
+		return severity
+	}
+	return level
+}
@@ -0,0 +1,71 @@
+package notifyme
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWithSeverityMapping(t *testing.T) {
+	syslog := map[int]int{LevelInfo: 6, LevelWarn: 4, LevelError: 3, LevelCritical: 2}
+	tests := []struct {
+		name    string
+		mapping map[int]int
+		level   int
+		want    int
+	}{
+		{"syslog critical", syslog, LevelCritical, 2},
+		{"syslog info", syslog, LevelInfo, 6},
+		{"unmapped level keeps its value", map[int]int{LevelCritical: 2}, LevelWarn, LevelWarn},
+		{"empty mapping", map[int]int{}, LevelError, LevelError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newWriterLogger(LevelInfo, &buf)
+			ring, _ := NewRingSink(1)
+			logger.AddSink(ring)
+			if err := logger.Configure(WithFormat(FormatJSON), WithSeverityMapping(tt.mapping)); err != nil {
+				t.Fatal(err)
+			}
+			logger.Log(tt.level, "mapped")
+
+			var doc map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+				t.Fatal(err)
+			}
+			if doc["severity"] != float64(tt.want) {
+				t.Errorf("JSON severity = %v, want %d", doc["severity"], tt.want)
+			}
+			if got := ring.Entries()[0].Severity; got != tt.want {
+				t.Errorf("Entry.Severity = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeverityMappingCopied(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newWriterLogger(LevelInfo, &buf)
+	mapping := map[int]int{LevelCritical: 2}
+	if err := logger.Configure(WithFormat(FormatJSON), WithSeverityMapping(mapping)); err != nil {
+		t.Fatal(err)
+	}
+	mapping[LevelCritical] = 9
+	logger.Log(LevelCritical, "mapped")
+	if !bytes.Contains(buf.Bytes(), []byte(`"severity":2`)) {
+		t.Errorf("changing the caller's map changed the mapping: %q", buf.String())
+	}
+}
+
+func TestSeverityOmittedWithoutMapping(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newWriterLogger(LevelInfo, &buf)
+	if err := logger.Configure(WithFormat(FormatJSON)); err != nil {
+		t.Fatal(err)
+	}
+	logger.Log(LevelCritical, "unmapped")
+	if bytes.Contains(buf.Bytes(), []byte(`"severity"`)) {
+		t.Errorf("JSON output has a severity without a mapping: %q", buf.String())
+	}
+}
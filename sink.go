@@ -0,0 +1,135 @@
+package notifyme
+
+// Sink receives every entry that passes the logger's level and sampling
+// filters, in addition to the logger's primary output. Sinks get the same
+// Entry value that is rendered to the primary output.
+type Sink interface {
+	Write(entry Entry) error
+	Close() error
+}
+
+// attachedSink is a sink together with the options it was added with
+type attachedSink struct {
+	sink      Sink
+	transform func(Entry) Entry
+	alerting  bool
+	owner     *Logger // the logger that added the sink and closes it
+}
+
+// SinkOption configures how a single sink added with AddSink receives
+// entries
+type SinkOption func(*attachedSink)
+
+// SinkTransform passes entries through fn before they reach the sink, for
+// example to drop verbose fields or rename keys for that destination only.
+// fn gets a copy whose Fields slice it may modify freely; the primary
+// output and other sinks are not affected.
+func SinkTransform(fn func(Entry) Entry) SinkOption {
+	return func(s *attachedSink) {
+		s.transform = fn
+	}
+}
+
+// SinkAlerting marks the sink as one that notifies people, such as a chat
+// or paging sink, so WithAlertDeduplication applies to it
+func SinkAlerting() SinkOption {
+	return func(s *attachedSink) {
+		s.alerting = true
+	}
+}
+
+// AddSink attaches a sink to the logger
+func (l *Logger) AddSink(sink Sink, opts ...SinkOption) {
+	attached := attachedSink{sink: sink, owner: l}
+	for _, opt := range opts {
+		opt(&attached)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sinks = append(l.sinks, attached)
+}
+
+// Close stops the heartbeat, writes entries held by WithReorderWindow,
+// waits for queued sink deliveries, stores the sequence counter, then
+// closes all sinks attached to the logger, its level files and the log
+// file it was created with, and returns the first error. Sinks, files,
+// the sequence counter and the delivery pool a clone shares with the
+// logger it was made from are left open, so closing a logger returned
+// by WithFields, With, Named or WithContext, e.g. with a deferred Close in
+// a request handler, only waits for its deliveries.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	l.releaseHeld(true)
+	l.reorder = nil
+	seq := l.seq
+	sinks := l.sinks
+	files, output := l.levelFiles, l.output
+	pool := l.sinkPool
+	hb := l.heartbeat
+	l.sinks = nil
+	l.levelFiles, l.output = nil, nil
+	l.sinkPool = nil
+	l.heartbeat = nil
+	l.mu.Unlock()
+
+	if hb != nil {
+		hb.stopAndWait()
+	}
+
+	if pool != nil && pool.owner == l {
+		pool.replace(nil)
+	} else if p := pool.current(); p != nil {
+		p.wait()
+	}
+
+	var firstErr error
+	if seq != nil && seq.owner == l {
+		firstErr = seq.save()
+	}
+	for _, attached := range sinks {
+		if attached.owner != l {
+			continue
+		}
+		if err := attached.sink.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if err := l.closeFiles(files, output); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
+
+// writeSinks delivers the entry to every attached sink. It must be called
+// with the logger mutex held.
+func (l *Logger) writeSinks(entry Entry) {
+	alert := l.shouldAlert(entry)
+	for _, attached := range l.sinks {
+		if attached.alerting && !alert {
+			continue
+		}
+		job := sinkJob{sink: attached.sink, entry: entry, onError: l.errorHandler()}
+		if attached.transform != nil {
+			copied := entry
+			copied.Fields = append([]Field(nil), entry.Fields...)
+			job.entry = attached.transform(copied)
+		}
+		if l.syncErrs != nil {
+			errs, report := l.syncErrs, job.onError
+			job.onError = func(err error) {
+				*errs = append(*errs, err)
+				report(err)
+			}
+		} else if l.sinkPool.submit(job) {
+			continue
+		}
+		if l.opts.sinkTimeout > 0 {
+			l.deliverWithTimeout(job)
+			continue
+		}
+		job.deliver()
+	}
+	if l.sinkPool != nil && len(l.sinks) > 0 {
+		l.updateBackpressure()
+	}
+}
@@ -0,0 +1,108 @@
+package notifyme
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// dropField returns a sink transform removing the fields named key
+func dropField(key string) func(Entry) Entry {
+	return func(entry Entry) Entry {
+		kept := entry.Fields[:0]
+		for _, field := range entry.Fields {
+			if field.Key != key {
+				kept = append(kept, field)
+			}
+		}
+		entry.Fields = kept
+		return entry
+	}
+}
+
+func TestSinkTransform(t *testing.T) {
+	all := []Field{{Key: "user", Value: "ann"}, {Key: "query", Value: "SELECT 1"}, {Key: "ms", Value: 900}}
+	tests := []struct {
+		name      string
+		transform func(Entry) Entry
+		message   string
+		fields    []Field
+	}{
+		{"drop a field", dropField("query"), "slow query",
+			[]Field{{Key: "user", Value: "ann"}, {Key: "ms", Value: 900}}},
+		{"rename a key", func(e Entry) Entry {
+			e.Fields[0].Key = "username"
+			return e
+		}, "slow query", []Field{{Key: "username", Value: "ann"}, {Key: "query", Value: "SELECT 1"}, {Key: "ms", Value: 900}}},
+		{"short message without fields", func(e Entry) Entry {
+			e.Message = "[db] " + e.Message
+			e.Fields = nil
+			return e
+		}, "[db] slow query", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			logger, err := NewLoggerE(LevelInfo, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			slack, _ := NewRingSink(1)
+			archive, _ := NewRingSink(1)
+			logger.AddSink(slack, SinkTransform(tt.transform))
+			logger.AddSink(archive)
+			logger.With("user", "ann", "query", "SELECT 1").NewEvent(LevelWarn).Int("ms", 900).Msg("slow query")
+			if err := logger.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			got := slack.Entries()[0]
+			if got.Message != tt.message || !reflect.DeepEqual(got.Fields, tt.fields) {
+				t.Errorf("transformed sink got %q %v, want %q %v", got.Message, got.Fields, tt.message, tt.fields)
+			}
+			if other := archive.Entries()[0]; other.Message != "slow query" || !reflect.DeepEqual(other.Fields, all) {
+				t.Errorf("other sink got %q %v, want the entry unchanged", other.Message, other.Fields)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasSuffix(string(data), "[WARN] slow query user=ann query=SELECT 1 ms=900\n") {
+				t.Errorf("file line %q, want every field", data)
+			}
+		})
+	}
+}
+
+func TestSinkTransformWithSinkPool(t *testing.T) {
+	slack, _ := NewRingSink(10)
+	archive, _ := NewRingSink(10)
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	logger.AddSink(slack, SinkTransform(dropField("query")))
+	logger.AddSink(archive)
+	if err := logger.Configure(WithSinkConcurrency(2)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		logger.With("query", "SELECT 1").Log(LevelWarn, "slow query")
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range slack.Entries() {
+		if _, ok := lastField(entry.Fields, "query"); ok {
+			t.Errorf("transformed sink got %v", entry.Fields)
+		}
+	}
+	for _, entry := range archive.Entries() {
+		if _, ok := lastField(entry.Fields, "query"); !ok {
+			t.Errorf("other sink got %v, want the query kept", entry.Fields)
+		}
+	}
+	if len(slack.Entries()) != 5 || len(archive.Entries()) != 5 {
+		t.Errorf("sinks got %d and %d entries, want 5 each", len(slack.Entries()), len(archive.Entries()))
+	}
+}
@@ -0,0 +1,242 @@
+package notifyme
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy decides what happens when the sink delivery queue is full
+type OverflowPolicy int
+
+// Overflow policies
+const (
+	// OverflowBlock makes the logging call wait for room in the queue
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDrop discards the delivery and counts it as dropped
+	OverflowDrop
+)
+
+// defaultSinkQueueSize is the queue size used by WithSinkConcurrency
+// unless WithSinkQueue sets another
+const defaultSinkQueueSize = 1024
+
+// sinkJob is a single delivery of an entry to a sink
+type sinkJob struct {
+	sink    Sink
+	entry   Entry
+	onError func(error)
+}
+
+// sinkPool delivers entries to sinks from a bounded number of workers
+type sinkPool struct {
+	mu      sync.RWMutex
+	jobs    chan sinkJob
+	policy  OverflowPolicy
+	closed  bool
+	dropped atomic.Int64
+	wg      sync.WaitGroup
+
+	// pending counts queued and running deliveries so wait can block
+	// until all of them are done
+	pendingMu sync.Mutex
+	pending   int
+	idle      *sync.Cond
+}
+
+// sinkPoolRef holds the current delivery pool of a logger and its
+// backpressure state. Clones share the holder, so a pool replaced by
+// WithSinkConcurrency or WithSinkQueue on the logger that owns it is
+// picked up by its clones as well.
+type sinkPoolRef struct {
+	mu      sync.Mutex
+	pool    *sinkPool
+	owner   *Logger // the logger that starts, replaces and closes the pool
+	monitor backpressureMonitor
+}
+
+// WithSinkConcurrency delivers entries to sinks from n background workers
+// instead of inline, so at most n deliveries run at once. Deliveries wait
+// in a queue configured by WithSinkQueue. With n = 1 deliveries happen one
+// at a time in logging order. Clones share the pool; applying the option
+// to a clone gives it a pool of its own.
+func WithSinkConcurrency(n int) Option {
+	return func(l *Logger) error {
+		if n <= 0 {
+			return errors.New("notifyme: sink concurrency must be positive")
+		}
+		l.opts.sinkConcurrency = n
+		return l.restartSinkPool()
+	}
+}
+
+// WithSinkQueue sets the size of the sink delivery queue and what to do when
+// it is full. It only has an effect together with WithSinkConcurrency.
+func WithSinkQueue(size int, policy OverflowPolicy) Option {
+	return func(l *Logger) error {
+		if size <= 0 {
+			return errors.New("notifyme: sink queue size must be positive")
+		}
+		if policy != OverflowBlock && policy != OverflowDrop {
+			return errors.New("notifyme: unknown overflow policy")
+		}
+		l.opts.sinkQueueSize = size
+		l.opts.sinkOverflow = policy
+		return l.restartSinkPool()
+	}
+}
+
+// DroppedSinkDeliveries returns how many sink deliveries were discarded
+// because the queue was full
+func (l *Logger) DroppedSinkDeliveries() int64 {
+	l.mu.Lock()
+	pool := l.sinkPool.current()
+	l.mu.Unlock()
+	if pool == nil {
+		return 0
+	}
+	return pool.dropped.Load()
+}
+
+// restartSinkPool replaces the current pool with one matching the options,
+// letting the old one drain first. A clone sharing the pool of another
+// logger gets a holder of its own instead. It must be called with the
+// logger mutex held.
+func (l *Logger) restartSinkPool() error {
+	if l.opts.sinkConcurrency == 0 {
+		return nil
+	}
+	if l.sinkPool == nil || l.sinkPool.owner != l {
+		l.sinkPool = &sinkPoolRef{owner: l}
+	}
+	size := l.opts.sinkQueueSize
+	if size == 0 {
+		size = defaultSinkQueueSize
+	}
+	l.sinkPool.replace(newSinkPool(l.opts.sinkConcurrency, size, l.opts.sinkOverflow))
+	return nil
+}
+
+// current returns the pool in use, or nil if there is none
+func (r *sinkPoolRef) current() *sinkPool {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.pool
+}
+
+// replace installs p, or no pool if p is nil, then closes the previous
+// pool, waiting for its queued deliveries
+func (r *sinkPoolRef) replace(p *sinkPool) {
+	r.mu.Lock()
+	old := r.pool
+	r.pool = p
+	r.mu.Unlock()
+	if old != nil {
+		old.close()
+	}
+}
+
+// submit queues a delivery on the current pool, retrying on its
+// replacement if it was closed meanwhile. It returns false when there is
+// no open pool, in which case the caller should deliver the entry itself.
+func (r *sinkPoolRef) submit(job sinkJob) bool {
+	for {
+		p := r.current()
+		if p == nil {
+			return false
+		}
+		if p.submit(job) {
+			return true
+		}
+		if r.current() == p {
+			return false
+		}
+	}
+}
+
+func newSinkPool(workers, size int, policy OverflowPolicy) *sinkPool {
+	p := &sinkPool{
+		jobs:   make(chan sinkJob, size),
+		policy: policy,
+	}
+	p.idle = sync.NewCond(&p.pendingMu)
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// submit queues a delivery. It returns false once the pool is closed, in
+// which case the caller should deliver the entry itself.
+func (p *sinkPool) submit(job sinkJob) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return false
+	}
+	p.addPending(1)
+	if p.policy == OverflowBlock {
+		p.jobs <- job
+		return true
+	}
+	select {
+	case p.jobs <- job:
+	default:
+		p.addPending(-1)
+		p.dropped.Add(1)
+	}
+	return true
+}
+
+// addPending adjusts the pending count, waking waiters when it drops to zero
+func (p *sinkPool) addPending(delta int) {
+	p.pendingMu.Lock()
+	p.pending += delta
+	if p.pending == 0 {
+		p.idle.Broadcast()
+	}
+	p.pendingMu.Unlock()
+}
+
+// wait blocks until every delivery submitted so far has finished
+func (p *sinkPool) wait() {
+	p.pendingMu.Lock()
+	for p.pending > 0 {
+		p.idle.Wait()
+	}
+	p.pendingMu.Unlock()
+}
+
+// work delivers queued entries until the pool is closed and drained
+func (p *sinkPool) work() {
+	defer p.wg.Done()
+	for job := range p.jobs {
+		job.deliver()
+		p.addPending(-1)
+	}
+}
+
+// close stops accepting deliveries and waits for queued ones to finish
+func (p *sinkPool) close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.jobs)
+	p.mu.Unlock()
+	p.wg.Wait()
+}
+
+// deliver writes the entry to the sink, reporting any failure
+func (job sinkJob) deliver() {
+	if err := job.sink.Write(job.entry); err != nil {
+		job.onError(fmt.Errorf("notifyme: sink write failed: %w", err))
+	}
+}
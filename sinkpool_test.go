@@ -0,0 +1,197 @@
+package notifyme
+
+import (
+	"bytes"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// slowSink records the messages it receives and the largest number of
+// deliveries that ran at once
+type slowSink struct {
+	delay    time.Duration
+	mu       sync.Mutex
+	messages []string
+	running  int
+	peak     int
+}
+
+func (s *slowSink) Write(entry Entry) error {
+	s.mu.Lock()
+	s.running++
+	if s.running > s.peak {
+		s.peak = s.running
+	}
+	s.mu.Unlock()
+
+	time.Sleep(s.delay)
+
+	s.mu.Lock()
+	s.running--
+	s.messages = append(s.messages, entry.Message)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *slowSink) Close() error {
+	return nil
+}
+
+// newPoolTestLogger returns a logger delivering to sink through a pool
+// configured by opts
+func newPoolTestLogger(t *testing.T, sink Sink, opts ...Option) *Logger {
+	t.Helper()
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	logger.AddSink(sink)
+	if err := logger.Configure(opts...); err != nil {
+		t.Fatal(err)
+	}
+	return logger
+}
+
+func TestSinkConcurrencyOneSerializesInOrder(t *testing.T) {
+	sink := &slowSink{delay: time.Millisecond}
+	logger := newPoolTestLogger(t, sink, WithSinkConcurrency(1))
+	for i := 0; i < 20; i++ {
+		logger.Log(LevelInfo, strconv.Itoa(i))
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if sink.peak != 1 {
+		t.Errorf("%d deliveries ran at once, want 1", sink.peak)
+	}
+	if len(sink.messages) != 20 {
+		t.Fatalf("got %d deliveries, want 20", len(sink.messages))
+	}
+	for i, message := range sink.messages {
+		if message != strconv.Itoa(i) {
+			t.Fatalf("delivery %d was %q, want logging order", i, message)
+		}
+	}
+}
+
+func TestSinkConcurrencyBound(t *testing.T) {
+	tests := []struct {
+		workers int
+	}{
+		{2},
+		{4},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.workers), func(t *testing.T) {
+			sink := &slowSink{delay: 5 * time.Millisecond}
+			logger := newPoolTestLogger(t, sink, WithSinkConcurrency(tt.workers))
+			for i := 0; i < 5*tt.workers; i++ {
+				logger.Log(LevelInfo, "burst")
+			}
+			if err := logger.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if sink.peak > tt.workers {
+				t.Errorf("%d deliveries ran at once, want at most %d", sink.peak, tt.workers)
+			}
+			if len(sink.messages) != 5*tt.workers {
+				t.Errorf("got %d deliveries, want %d", len(sink.messages), 5*tt.workers)
+			}
+		})
+	}
+}
+
+// gateSink blocks every delivery until release is closed, signalling on
+// started when one begins
+type gateSink struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *gateSink) Write(Entry) error {
+	s.started <- struct{}{}
+	<-s.release
+	return nil
+}
+
+func (s *gateSink) Close() error {
+	return nil
+}
+
+func TestSinkQueueOverflowDrop(t *testing.T) {
+	sink := &gateSink{started: make(chan struct{}, 10), release: make(chan struct{})}
+	logger := newPoolTestLogger(t, sink, WithSinkConcurrency(1), WithSinkQueue(2, OverflowDrop))
+
+	logger.Log(LevelInfo, "running")
+	<-sink.started
+	// The worker is busy, so two entries fill the queue and three overflow
+	for i := 0; i < 5; i++ {
+		logger.Log(LevelInfo, "queued")
+	}
+	if got := logger.DroppedSinkDeliveries(); got != 3 {
+		t.Errorf("DroppedSinkDeliveries = %d, want 3", got)
+	}
+	close(sink.release)
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(sink.started); n != 2 {
+		t.Errorf("%d queued deliveries ran after the first, want 2", n)
+	}
+}
+
+func TestSinkPoolSharedWithClones(t *testing.T) {
+	sink := &slowSink{}
+	logger := newPoolTestLogger(t, sink, WithSinkConcurrency(1))
+	clone := logger.Named("worker")
+	if clone.sinkPool.current() != logger.sinkPool.current() {
+		t.Fatal("the clone does not share the pool")
+	}
+
+	old := logger.sinkPool.current()
+	if err := logger.Configure(WithSinkConcurrency(2)); err != nil {
+		t.Fatal(err)
+	}
+	replaced := logger.sinkPool.current()
+	if replaced == old || clone.sinkPool.current() != replaced {
+		t.Error("the clone does not use the replacement pool")
+	}
+	clone.Log(LevelInfo, "from clone")
+
+	if err := clone.Configure(WithSinkQueue(8, OverflowBlock)); err != nil {
+		t.Fatal(err)
+	}
+	if clone.sinkPool.current() == replaced || logger.sinkPool.current() != replaced {
+		t.Error("reconfiguring the clone changed the original's pool")
+	}
+
+	if err := clone.Close(); err != nil {
+		t.Fatal(err)
+	}
+	logger.Log(LevelInfo, "from original")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.messages) != 2 {
+		t.Errorf("sink got %q, want both entries", sink.messages)
+	}
+}
+
+func TestSinkConcurrencyInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  Option
+	}{
+		{"zero workers", WithSinkConcurrency(0)},
+		{"zero queue", WithSinkQueue(0, OverflowBlock)},
+		{"unknown policy", WithSinkQueue(1, OverflowPolicy(9))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+			if err := logger.Configure(tt.opt); err == nil {
+				t.Error("Configure accepted the option")
+			}
+		})
+	}
+}
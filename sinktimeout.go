@@ -0,0 +1,63 @@
+package notifyme
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ContextSink is a Sink that can abandon a delivery when its context is
+// done. Loggers configured with WithSinkTimeout call WriteContext instead
+// of Write.
+type ContextSink interface {
+	Sink
+	WriteContext(ctx context.Context, entry Entry) error
+}
+
+// WithSinkTimeout bounds each inline sink delivery to d. A delivery still
+// running after d is abandoned, counted in SinkTimeouts and reported to the
+// error handler, so a hung sink cannot stall the logging call. Context
+// sinks receive a context with this deadline. Deliveries made by a
+// WithSinkConcurrency pool are not affected. Zero disables the timeout.
+func WithSinkTimeout(d time.Duration) Option {
+	return func(l *Logger) error {
+		if d < 0 {
+			return errors.New("notifyme: sink timeout must not be negative")
+		}
+		l.opts.sinkTimeout = d
+		return nil
+	}
+}
+
+// SinkTimeouts returns how many sink deliveries were abandoned because they
+// exceeded the WithSinkTimeout duration
+func (l *Logger) SinkTimeouts() int64 {
+	return l.sinkTimeouts.Load()
+}
+
+// deliverWithTimeout runs the delivery in the background and waits at most
+// the configured sink timeout for it. It must be called with the logger
+// mutex held.
+func (l *Logger) deliverWithTimeout(job sinkJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), l.opts.sinkTimeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		if sink, ok := job.sink.(ContextSink); ok {
+			done <- sink.WriteContext(ctx, job.entry)
+			return
+		}
+		done <- job.sink.Write(job.entry)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			job.onError(fmt.Errorf("notifyme: sink write failed: %w", err))
+		}
+	case <-ctx.Done():
+		l.sinkTimeouts.Add(1)
+		job.onError(fmt.Errorf("notifyme: sink write abandoned after %v", l.opts.sinkTimeout))
+	}
+}
@@ -0,0 +1,105 @@
+package notifyme
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// hangingSink blocks every Write until release is closed
+type hangingSink struct {
+	release chan struct{}
+}
+
+func (s *hangingSink) Write(Entry) error {
+	<-s.release
+	return nil
+}
+
+func (s *hangingSink) Close() error {
+	return nil
+}
+
+// deadlineSink records the deadline of the context it was written with
+type deadlineSink struct {
+	mu       sync.Mutex
+	deadline time.Time
+	writes   int
+}
+
+func (s *deadlineSink) Write(Entry) error {
+	return nil
+}
+
+func (s *deadlineSink) WriteContext(ctx context.Context, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deadline, _ = ctx.Deadline()
+	s.writes++
+	return nil
+}
+
+func (s *deadlineSink) Close() error {
+	return nil
+}
+
+func TestWithSinkTimeout(t *testing.T) {
+	sink := &hangingSink{release: make(chan struct{})}
+	defer close(sink.release)
+	var reported []error
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	logger.AddSink(sink)
+	err := logger.Configure(
+		WithSinkTimeout(20*time.Millisecond),
+		WithErrorHandler(func(err error) { reported = append(reported, err) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	logger.Log(LevelInfo, "first")
+	logger.Log(LevelInfo, "second")
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("logging took %v with a hung sink, want about two timeouts", elapsed)
+	}
+	if got := logger.SinkTimeouts(); got != 2 {
+		t.Errorf("SinkTimeouts = %d, want 2", got)
+	}
+	if len(reported) != 2 || !strings.Contains(reported[0].Error(), "abandoned after 20ms") {
+		t.Errorf("reported errors = %v, want two abandoned deliveries", reported)
+	}
+}
+
+func TestSinkTimeoutContextDeadline(t *testing.T) {
+	sink := &deadlineSink{}
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	logger.AddSink(sink)
+	if err := logger.Configure(WithSinkTimeout(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	before := time.Now()
+	logger.Log(LevelInfo, "with deadline")
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if sink.writes != 1 {
+		t.Fatalf("WriteContext called %d times, want 1", sink.writes)
+	}
+	if sink.deadline.Before(before.Add(time.Minute)) || sink.deadline.After(time.Now().Add(time.Minute)) {
+		t.Errorf("context deadline = %v, want a minute after logging", sink.deadline)
+	}
+	if logger.SinkTimeouts() != 0 {
+		t.Errorf("SinkTimeouts = %d, want 0", logger.SinkTimeouts())
+	}
+}
+
+func TestWithSinkTimeoutNegative(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	if err := logger.Configure(WithSinkTimeout(-time.Second)); err == nil {
+		t.Error("Configure accepted a negative timeout")
+	}
+}
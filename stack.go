@@ -0,0 +1,97 @@
+package notifyme
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// defaultStackFrames is the number of frames captured by WithStackTraceLevel
+// when no limit is given
+const defaultStackFrames = 32
+
+// WithStackTraceLevel attaches a "stack" field with the goroutine's stack
+// to every entry at or above level, starting at the logging call. At most
+// maxFrames frames are captured; zero uses a default of 32.
+func WithStackTraceLevel(level, maxFrames int) Option {
+	return func(l *Logger) error {
+		if maxFrames < 0 {
+			return errors.New("notifyme: stack frame limit must not be negative")
+		}
+		if maxFrames == 0 {
+			maxFrames = defaultStackFrames
+		}
+		l.opts.stackTrace = true
+		l.opts.stackLevel = level
+		l.opts.stackFrames = maxFrames
+		return nil
+	}
+}
+
+// maxStackRefs is the number of remembered stacks above which expired ones
+// are forgotten
+const maxStackRefs = 1024
+
+// WithDropDuplicateStacks shortens repeated stack traces: the first entry
+// with a given stack carries it in "stack" together with a "stack_id"
+// hash, and entries with the same stack within window carry only
+// "stack_ref" set to that hash. After the window the full stack is written
+// again. It only has an effect together with WithStackTraceLevel.
+func WithDropDuplicateStacks(window time.Duration) Option {
+	return func(l *Logger) error {
+		if window <= 0 {
+			return errors.New("notifyme: duplicate stack window must be positive")
+		}
+		l.opts.stackDedup = window
+		return nil
+	}
+}
+
+// addStack attaches the stack starting depth frames above its caller,
+// counted like runtime.Caller, or a reference to it if the same stack was
+// written recently. It must be called with the logger mutex held.
+func (l *Logger) addStack(entry *Entry, depth int) {
+	pcs := make([]uintptr, l.opts.stackFrames)
+	pcs = pcs[:runtime.Callers(depth+2, pcs)]
+	if l.opts.stackDedup > 0 {
+		id := stackID(pcs)
+		if !l.stackSeen.check(id, entry.Time, l.opts.stackDedup) {
+			entry.Fields = setField(entry.Fields, "stack_ref", id)
+			return
+		}
+		entry.Fields = setField(entry.Fields, "stack_id", id)
+	}
+	entry.Fields = setField(entry.Fields, "stack", renderStack(pcs))
+}
+
+// stackID returns a short hash identifying the program counters of a stack
+func stackID(pcs []uintptr) string {
+	h := fnv.New64a()
+	var buf [8]byte
+	for _, pc := range pcs {
+		binary.LittleEndian.PutUint64(buf[:], uint64(pc))
+		h.Write(buf[:])
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// renderStack renders the frames one "function\n\tfile:line" pair per frame
+func renderStack(pcs []uintptr) string {
+	frames := runtime.CallersFrames(pcs)
+	var b strings.Builder
+	for {
+		frame, more := frames.Next()
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,213 @@
+package notifyme
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithStackTraceLevel(t *testing.T) {
+	tests := []struct {
+		name  string
+		level int
+		stack bool
+	}{
+		{"info", LevelInfo, false},
+		{"warn", LevelWarn, false},
+		{"error", LevelError, true},
+		{"critical", LevelCritical, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+			ring, _ := NewRingSink(2)
+			logger.AddSink(ring)
+			if err := logger.Configure(WithStackTraceLevel(LevelError, 0)); err != nil {
+				t.Fatal(err)
+			}
+			logger.Log(tt.level, "logged")
+			logger.NewEvent(tt.level).Str("k", "v").Msg("event")
+
+			for _, entry := range ring.Entries() {
+				value, ok := lastField(entry.Fields, "stack")
+				if ok != tt.stack {
+					t.Fatalf("%q: stack attached = %v, want %v", entry.Message, ok, tt.stack)
+				}
+				if !ok {
+					continue
+				}
+				stack := value.(string)
+				if !strings.HasPrefix(stack, "github.com/AmosSParker/NotifyMe.TestWithStackTraceLevel.func") {
+					t.Errorf("%q: stack does not start at the logging call:\n%s", entry.Message, stack)
+				}
+				if !strings.Contains(stack, "\n\t") || !strings.Contains(stack, "stack_test.go:") {
+					t.Errorf("%q: stack lacks file and line:\n%s", entry.Message, stack)
+				}
+			}
+		})
+	}
+}
+
+func TestWithStackTraceLevelMaxFrames(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxFrames int
+		want      int
+	}{
+		{"one frame", 1, 1},
+		{"two frames", 2, 2},
+		{"default", 0, defaultStackFrames},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+			ring, _ := NewRingSink(1)
+			logger.AddSink(ring)
+			if err := logger.Configure(WithStackTraceLevel(LevelError, tt.maxFrames)); err != nil {
+				t.Fatal(err)
+			}
+			deepLog(logger, defaultStackFrames+8)
+
+			value, _ := lastField(ring.Entries()[0].Fields, "stack")
+			if got := strings.Count(value.(string), "\n\t"); got != tt.want {
+				t.Errorf("got %d frames, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// deepLog logs an ERROR from depth nested calls
+func deepLog(logger *Logger, depth int) {
+	if depth > 0 {
+		deepLog(logger, depth-1)
+		return
+	}
+	logger.Log(LevelError, "deep")
+}
+
+func TestWithStackTraceLevelInvalid(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	if err := logger.Configure(WithStackTraceLevel(LevelError, -1)); err == nil {
+		t.Error("Configure accepted a negative frame limit")
+	}
+}
+
+// stackFromA and stackFromB log an ERROR from two different call sites
+func stackFromA(logger *Logger) { logger.Log(LevelError, "a") }
+func stackFromB(logger *Logger) { logger.Log(LevelError, "b") }
+
+func TestWithDropDuplicateStacks(t *testing.T) {
+	type stackStep struct {
+		wait time.Duration
+		log  func(*Logger)
+		full bool
+		// same is the index of the step whose stack_id this step carries or
+		// references, its own for a stack not seen before
+		same int
+	}
+	tests := []struct {
+		name  string
+		steps []stackStep
+	}{
+		{"repeated stack", []stackStep{
+			{0, stackFromA, true, 0}, {0, stackFromA, false, 0}, {time.Second, stackFromA, false, 0},
+		}},
+		{"different stacks", []stackStep{
+			{0, stackFromA, true, 0}, {0, stackFromB, true, 1}, {0, stackFromA, false, 0}, {0, stackFromB, false, 1},
+		}},
+		{"full stack again after the window", []stackStep{
+			{0, stackFromA, true, 0}, {time.Minute, stackFromA, true, 0}, {time.Second, stackFromA, false, 0},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock(time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC))
+			logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+			ring, _ := NewRingSink(len(tt.steps))
+			logger.AddSink(ring)
+			err := logger.Configure(WithClock(clock), WithStackTraceLevel(LevelError, 8), WithDropDuplicateStacks(time.Minute))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids := make([]string, len(tt.steps))
+			for i, step := range tt.steps {
+				clock.Advance(step.wait)
+				step.log(logger)
+				entry := ring.Entries()[i]
+				stack, hasStack := lastField(entry.Fields, "stack")
+				id, _ := lastField(entry.Fields, "stack_id")
+				ref, hasRef := lastField(entry.Fields, "stack_ref")
+				if step.full {
+					if !hasStack || hasRef || !strings.Contains(stack.(string), "stackFrom") {
+						t.Fatalf("step %d: fields %v, want the full stack", i, entry.Fields)
+					}
+					ids[i], _ = id.(string)
+				} else {
+					if hasStack || !hasRef {
+						t.Fatalf("step %d: fields %v, want only a stack reference", i, entry.Fields)
+					}
+					ids[i], _ = ref.(string)
+				}
+				if len(ids[i]) != 16 {
+					t.Errorf("step %d: stack hash %q, want 16 hex digits", i, ids[i])
+				}
+				if ids[i] != ids[step.same] {
+					t.Errorf("step %d: stack hash %s, want %s of step %d", i, ids[i], ids[step.same], step.same)
+				}
+				for j := 0; step.same == i && j < i; j++ {
+					if ids[j] == ids[i] {
+						t.Errorf("step %d: new stack has the hash %s of step %d", i, ids[i], j)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestWithDropDuplicateStacksOutput(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newWriterLogger(LevelInfo, &buf)
+	if err := logger.Configure(WithStackTraceLevel(LevelError, 8), WithDropDuplicateStacks(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		stackFromA(logger)
+	}
+	if n := strings.Count(buf.String(), "stackFromA\n"); n != 1 {
+		t.Errorf("full stack written %d times, want once:\n%s", n, buf.String())
+	}
+	if n := strings.Count(buf.String(), "stack_ref="); n != 2 {
+		t.Errorf("stack referenced %d times, want twice:\n%s", n, buf.String())
+	}
+}
+
+func TestWithDropDuplicateStacksSharedWithClones(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	ring, _ := NewRingSink(3)
+	logger.AddSink(ring)
+	if err := logger.Configure(WithStackTraceLevel(LevelError, 8), WithDropDuplicateStacks(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range []*Logger{logger, logger.Clone(), logger.WithFields(map[string]interface{}{"req": 1})} {
+		stackFromA(l)
+	}
+
+	entries := ring.Entries()
+	id, _ := lastField(entries[0].Fields, "stack_id")
+	for i, entry := range entries[1:] {
+		if _, hasStack := lastField(entry.Fields, "stack"); hasStack {
+			t.Errorf("clone %d wrote the full stack again", i)
+		}
+		if ref, _ := lastField(entry.Fields, "stack_ref"); ref != id || id == nil {
+			t.Errorf("clone %d stack_ref = %v, want the original's stack_id %v", i, ref, id)
+		}
+	}
+}
+
+func TestWithDropDuplicateStacksInvalid(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	if err := logger.Configure(WithDropDuplicateStacks(0)); err == nil {
+		t.Error("Configure accepted a zero window")
+	}
+}
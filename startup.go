@@ -0,0 +1,96 @@
+package notifyme
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// earlyCall is a Notify call made before the global logger was initialized
+type earlyCall struct {
+	time    time.Time
+	level   int
+	message string
+	caller  Caller
+}
+
+// startup buffers early Notify calls until the global logger is initialized
+var startup struct {
+	mu      sync.Mutex
+	size    int
+	calls   []earlyCall
+	dropped int
+}
+
+// EnableStartupReplay makes Notify buffer up to size calls made before
+// InitializeGlobalLogger or ReinitializeGlobalLogger, for example from
+// package init functions, instead of writing them to the default stdout
+// logger. The buffered calls are replayed through the global logger once
+// it is initialized, with their original time and caller; calls beyond
+// size are counted and reported in a WARN entry. Zero turns buffering off
+// and discards anything buffered.
+func EnableStartupReplay(size int) {
+	startup.mu.Lock()
+	defer startup.mu.Unlock()
+	if size < 0 {
+		size = 0
+	}
+	startup.size = size
+	if size == 0 {
+		startup.calls, startup.dropped = nil, 0
+	}
+}
+
+// bufferEarly records a Notify call if startup replay is enabled and the
+// global logger is not initialized yet. The caller is taken depth frames
+// above bufferEarly's caller.
+func bufferEarly(depth int, level int, message string) bool {
+	startup.mu.Lock()
+	defer startup.mu.Unlock()
+	if startup.size == 0 || globalInitialized.Load() {
+		return false
+	}
+	if len(startup.calls) >= startup.size {
+		startup.dropped++
+		return true
+	}
+	startup.calls = append(startup.calls, earlyCall{
+		time:    time.Now(),
+		level:   level,
+		message: message,
+		caller:  callerAt(depth + 1),
+	})
+	return true
+}
+
+// replayEarly writes the buffered early calls to logger and stops
+// buffering. It must be called after globalInitialized is set, so no call
+// can be buffered after the replay.
+func replayEarly(logger *Logger) {
+	startup.mu.Lock()
+	calls, dropped := startup.calls, startup.dropped
+	startup.size, startup.calls, startup.dropped = 0, nil, 0
+	startup.mu.Unlock()
+	if len(calls) == 0 && dropped == 0 {
+		return
+	}
+
+	defer logger.unlockWrite(logger.lockWrite())
+	for _, call := range calls {
+		if logger.effectiveLevel() > call.level {
+			continue
+		}
+		if !logger.opts.callerFunction {
+			call.caller.Function = ""
+		}
+		entry := logger.newEntry(call.level, call.message, call.caller, nil)
+		entry.Time = call.time
+		logger.writeEntry(entry)
+	}
+	if dropped > 0 && logger.effectiveLevel() <= LevelWarn {
+		caller := callerAt(0)
+		caller.Function = ""
+		entry := logger.newEntry(LevelWarn, fmt.Sprintf("Dropped %d log calls made before the logger was initialized", dropped), caller, nil)
+		logger.writeEntry(entry)
+	}
+}
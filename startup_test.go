@@ -0,0 +1,110 @@
+package notifyme
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readLogFile returns the lines of a log file
+func readLogFile(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func TestStartupReplay(t *testing.T) {
+	tests := []struct {
+		name  string
+		size  int
+		level int
+		early func()
+		want  []string
+	}{
+		{"replayed in order", 10, LevelInfo, func() {
+			Notify("Info", "loading config from %s", "/etc/app.yaml")
+			Notify("Warn", "config key %q is deprecated", "timeout")
+		}, []string{`[INFO] loading config from /etc/app.yaml`, `[WARN] config key "timeout" is deprecated`}},
+		{"level applied on replay", 10, LevelWarn, func() {
+			Notify("Info", "verbose detail")
+			Notify("Error", "no database URL")
+		}, []string{"[ERROR] no database URL"}},
+		{"overflow reported", 2, LevelInfo, func() {
+			for _, msg := range []string{"one", "two", "three", "four"} {
+				Notify("Warn", msg)
+			}
+		}, []string{"[WARN] one", "[WARN] two", "[WARN] Dropped 2 log calls made before the logger was initialized"}},
+		{"overflow hidden by the level", 1, LevelError, func() {
+			Notify("Error", "one")
+			Notify("Error", "two")
+		}, []string{"[ERROR] one"}},
+		{"nothing logged early", 10, LevelInfo, func() {}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateGlobalLogger(t)
+			t.Cleanup(func() { EnableStartupReplay(0) })
+			EnableStartupReplay(tt.size)
+			tt.early()
+
+			path := filepath.Join(t.TempDir(), "app.log")
+			InitializeGlobalLogger(tt.level, path)
+			lines := readLogFile(t, path)
+			if len(lines) != len(tt.want) {
+				t.Fatalf("replayed %q, want %d lines", lines, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.HasSuffix(lines[i], want) {
+					t.Errorf("line %d = %q, want suffix %q", i, lines[i], want)
+				}
+				if !strings.Contains(want, "Dropped") && !strings.Contains(lines[i], " startup_test.go:") {
+					t.Errorf("line %d = %q, want the original Notify call as caller", i, lines[i])
+				}
+			}
+		})
+	}
+}
+
+func TestStartupReplayStopsAfterInit(t *testing.T) {
+	isolateGlobalLogger(t)
+	t.Cleanup(func() { EnableStartupReplay(0) })
+	EnableStartupReplay(10)
+	Notify("Warn", "early")
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.log")
+	InitializeGlobalLogger(LevelInfo, first)
+	Notify("Warn", "late")
+	EnableStartupReplay(10)
+	Notify("Warn", "after replay enabled again")
+
+	second := filepath.Join(dir, "second.log")
+	ReinitializeGlobalLogger(LevelInfo, second).Close()
+	lines := readLogFile(t, first)
+	if len(lines) != 3 || !strings.HasSuffix(lines[0], "early") || !strings.HasSuffix(lines[1], "late") {
+		t.Errorf("first log = %q, want the early call replayed once and later calls written directly", lines)
+	}
+	if lines := readLogFile(t, second); len(lines) != 0 {
+		t.Errorf("second log = %q, want nothing replayed twice", lines)
+	}
+}
+
+func TestEnableStartupReplayZeroDiscards(t *testing.T) {
+	isolateGlobalLogger(t)
+	EnableStartupReplay(10)
+	Notify("Error", "discarded")
+	EnableStartupReplay(0)
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	InitializeGlobalLogger(LevelInfo, path)
+	if lines := readLogFile(t, path); len(lines) != 0 {
+		t.Errorf("log = %q, want the buffered call discarded", lines)
+	}
+}
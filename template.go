@@ -0,0 +1,106 @@
+package notifyme
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Tokens of a text template
+const (
+	tokenLiteral = iota
+	tokenTime
+	tokenLevel
+	tokenCaller
+	tokenMsg
+	tokenFields
+)
+
+// templateTokens maps token names to their kinds
+var templateTokens = map[string]int{
+	"time":   tokenTime,
+	"level":  tokenLevel,
+	"caller": tokenCaller,
+	"msg":    tokenMsg,
+	"fields": tokenFields,
+}
+
+// templatePart is a literal or a token of a parsed text template
+type templatePart struct {
+	token   int
+	literal string
+}
+
+// WithTextTemplate sets the layout of text lines using the tokens {time},
+// {level}, {caller}, {msg} and {fields}; everything else is copied as is.
+// {msg} includes the logger name and {fields} the space-separated
+// key=value pairs. Trailing spaces are trimmed, so "{fields}" at the end of
+// the template leaves nothing behind when there are no fields. The default
+// layout is equivalent to "{time} {caller}: [{level}] {msg} {fields}". The
+// level prefix of the output is written before the template.
+func WithTextTemplate(tmpl string) Option {
+	return func(l *Logger) error {
+		parts, err := parseTextTemplate(tmpl)
+		if err != nil {
+			return err
+		}
+		l.opts.textTemplate = parts
+		return nil
+	}
+}
+
+// parseTextTemplate splits a template into literals and tokens, rejecting
+// unknown or unterminated tokens
+func parseTextTemplate(tmpl string) ([]templatePart, error) {
+	var parts []templatePart
+	for tmpl != "" {
+		start := strings.IndexByte(tmpl, '{')
+		if start < 0 {
+			parts = append(parts, templatePart{literal: tmpl})
+			break
+		}
+		if start > 0 {
+			parts = append(parts, templatePart{literal: tmpl[:start]})
+		}
+		end := strings.IndexByte(tmpl[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("notifyme: unterminated token in text template at %q", tmpl[start:])
+		}
+		name := tmpl[start+1 : start+end]
+		token, ok := templateTokens[name]
+		if !ok {
+			return nil, fmt.Errorf("notifyme: unknown token {%s} in text template", name)
+		}
+		parts = append(parts, templatePart{token: token})
+		tmpl = tmpl[start+end+1:]
+	}
+	if len(parts) == 0 {
+		return nil, errors.New("notifyme: text template must not be empty")
+	}
+	return parts, nil
+}
+
+// renderTemplate renders an entry with the configured text template. It
+// must be called with the logger mutex held.
+func (l *Logger) renderTemplate(entry Entry) string {
+	var b strings.Builder
+	for _, part := range l.opts.textTemplate {
+		switch part.token {
+		case tokenLiteral:
+			b.WriteString(part.literal)
+		case tokenTime:
+			b.WriteString(l.formatTime(entry.Time))
+		case tokenLevel:
+			b.WriteString(l.levelLabel(entry.Level))
+		case tokenCaller:
+			writeCaller(&b, entry.Caller)
+		case tokenMsg:
+			writeMessage(&b, entry)
+		case tokenFields:
+			var fields strings.Builder
+			l.writeFields(&fields, entry)
+			b.WriteString(strings.TrimPrefix(fields.String(), " "))
+		}
+	}
+	return strings.TrimRight(b.String(), " ")
+}
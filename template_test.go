@@ -0,0 +1,78 @@
+package notifyme
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestWithTextTemplate(t *testing.T) {
+	entry := Entry{
+		Level:   LevelWarn,
+		Message: "slow query",
+		Time:    time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC),
+		Caller:  Caller{File: "/src/app/db.go", Line: 3},
+		Name:    "db",
+		Fields:  []Field{{Key: "table", Value: "users"}, {Key: "ms", Value: 812}},
+	}
+	bare := entry
+	bare.Name, bare.Fields = "", nil
+	tests := []struct {
+		name  string
+		tmpl  string
+		entry Entry
+		want  string
+	}{
+		{"default layout", "{time} {caller}: [{level}] {msg} {fields}", entry,
+			"WARN: 2024/03/09 14:05:06 db.go:3: [WARN] db: slow query table=users ms=812\n"},
+		{"level first", "[{level}] {time} {msg} ({caller}) {fields}", entry,
+			"WARN: [WARN] 2024/03/09 14:05:06 db: slow query (db.go:3) table=users ms=812\n"},
+		{"fields before message", "{level}|{fields}|{msg}", entry,
+			"WARN: WARN|table=users ms=812|db: slow query\n"},
+		{"trailing fields trimmed", "{level} {msg} {fields}", bare,
+			"WARN: WARN slow query\n"},
+		{"literals only around tokens", ">> {msg} <<", bare,
+			"WARN: >> slow query <<\n"},
+		{"repeated token", "{level} {msg} {level}", bare,
+			"WARN: WARN slow query WARN\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newWriterLogger(LevelInfo, &buf)
+			if err := logger.Configure(WithTextTemplate(tt.tmpl), WithTimeZone(time.UTC)); err != nil {
+				t.Fatal(err)
+			}
+			logger.WriteEntry(tt.entry)
+			if buf.String() != tt.want {
+				t.Errorf("output %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWithTextTemplateDefaultMatchesLayout(t *testing.T) {
+	var templated, plain bytes.Buffer
+	entry := Entry{
+		Level: LevelError, Message: "failed", Time: fixedTime,
+		Caller: Caller{File: "/src/app/main.go", Line: 9}, Fields: []Field{{Key: "k", Value: "v"}},
+	}
+	logger := newWriterLogger(LevelInfo, &templated)
+	if err := logger.Configure(WithTextTemplate("{time} {caller}: [{level}] {msg} {fields}")); err != nil {
+		t.Fatal(err)
+	}
+	logger.WriteEntry(entry)
+	newWriterLogger(LevelInfo, &plain).WriteEntry(entry)
+	if templated.String() != plain.String() {
+		t.Errorf("default template %q differs from the default layout %q", templated.String(), plain.String())
+	}
+}
+
+func TestWithTextTemplateInvalid(t *testing.T) {
+	for _, tmpl := range []string{"", "{time} {host}", "{msg", "{}"} {
+		logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+		if err := logger.Configure(WithTextTemplate(tmpl)); err == nil {
+			t.Errorf("Configure accepted template %q", tmpl)
+		}
+	}
+}
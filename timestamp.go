@@ -0,0 +1,99 @@
+package notifyme
+
+import (
+	"errors"
+	"time"
+)
+
+// Precision controls how many fractional-second digits timestamps show
+type Precision int
+
+// Timestamp precisions
+const (
+	PrecisionSeconds Precision = iota
+	PrecisionMilliseconds
+	PrecisionMicroseconds
+	PrecisionNanoseconds
+)
+
+// textTimeLayout matches the date and time written by the standard log package
+const textTimeLayout = "2006/01/02 15:04:05"
+
+// WithTimestampPrecision sets the precision of rendered timestamps. The
+// default is whole seconds.
+func WithTimestampPrecision(precision Precision) Option {
+	return func(l *Logger) error {
+		if precision < PrecisionSeconds || precision > PrecisionNanoseconds {
+			return errors.New("notifyme: unknown timestamp precision")
+		}
+		l.opts.precision = precision
+		return nil
+	}
+}
+
+// WithTimeZone renders text and JSON timestamps in loc instead of the
+// host's local time zone. ECS output and ElasticSink keep using UTC.
+func WithTimeZone(loc *time.Location) Option {
+	return func(l *Logger) error {
+		if loc == nil {
+			return errors.New("notifyme: time zone must not be nil")
+		}
+		l.opts.location = loc
+		return nil
+	}
+}
+
+// WithTextTimeFormat renders text timestamps with the time.Format layout
+// instead of the precision-based default, independently of JSON output
+func WithTextTimeFormat(layout string) Option {
+	return func(l *Logger) error {
+		if layout == "" {
+			return errors.New("notifyme: text time format must not be empty")
+		}
+		l.opts.textTimeLayout = layout
+		return nil
+	}
+}
+
+// WithJSONTimeFormat renders JSON timestamps, including event_ts, with the
+// time.Format layout instead of RFC 3339, independently of text output.
+// WithTimeKeyAsEpoch takes precedence. ECS output keeps RFC 3339.
+func WithJSONTimeFormat(layout string) Option {
+	return func(l *Logger) error {
+		if layout == "" {
+			return errors.New("notifyme: JSON time format must not be empty")
+		}
+		l.opts.jsonTimeLayout = layout
+		return nil
+	}
+}
+
+// layout returns the text time layout for the precision
+func (p Precision) layout() string {
+	switch p {
+	case PrecisionMilliseconds:
+		return textTimeLayout + ".000"
+	case PrecisionMicroseconds:
+		return textTimeLayout + ".000000"
+	case PrecisionNanoseconds:
+		return textTimeLayout + ".000000000"
+	default:
+		return textTimeLayout
+	}
+}
+
+// formatTime renders the entry time for text output
+func (l *Logger) formatTime(t time.Time) string {
+	if l.opts.textTimeLayout != "" {
+		return l.inZone(t).Format(l.opts.textTimeLayout)
+	}
+	return l.inZone(t).Format(l.opts.precision.layout())
+}
+
+// inZone returns t in the configured time zone, if any
+func (l *Logger) inZone(t time.Time) time.Time {
+	if l.opts.location == nil {
+		return t
+	}
+	return t.In(l.opts.location)
+}
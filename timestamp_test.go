@@ -0,0 +1,231 @@
+package notifyme
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithTimestampPrecision(t *testing.T) {
+	at := time.Date(2024, 3, 9, 14, 5, 6, 123456789, time.UTC)
+	tests := []struct {
+		precision Precision
+		digits    int
+		text      string
+		json      string
+	}{
+		{PrecisionSeconds, 0, "2024/03/09 14:05:06", "2024-03-09T14:05:06Z"},
+		{PrecisionMilliseconds, 3, "2024/03/09 14:05:06.123", "2024-03-09T14:05:06.123Z"},
+		{PrecisionMicroseconds, 6, "2024/03/09 14:05:06.123456", "2024-03-09T14:05:06.123456Z"},
+		{PrecisionNanoseconds, 9, "2024/03/09 14:05:06.123456789", "2024-03-09T14:05:06.123456789Z"},
+	}
+	textTime := regexp.MustCompile(`^INFO: (\S+ [0-9:.]+) `)
+	fraction := regexp.MustCompile(`\.(\d+)`)
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			var text, encoded bytes.Buffer
+			for _, c := range []struct {
+				buf    *bytes.Buffer
+				format Format
+			}{{&text, FormatText}, {&encoded, FormatJSON}} {
+				logger := newWriterLogger(LevelInfo, c.buf)
+				logger.now = func() time.Time { return at }
+				if err := logger.Configure(WithFormat(c.format), WithTimestampPrecision(tt.precision)); err != nil {
+					t.Fatal(err)
+				}
+				logger.Log(LevelInfo, "tick")
+			}
+
+			m := textTime.FindStringSubmatch(text.String())
+			if m == nil || m[1] != tt.text {
+				t.Errorf("text line %q, want time %s", text.String(), tt.text)
+			}
+			var doc map[string]interface{}
+			if err := json.Unmarshal(encoded.Bytes(), &doc); err != nil {
+				t.Fatal(err)
+			}
+			if doc["ts"] != tt.json {
+				t.Errorf("JSON time = %v, want %s", doc["ts"], tt.json)
+			}
+			for _, rendered := range []string{tt.text, tt.json} {
+				digits := 0
+				if f := fraction.FindStringSubmatch(rendered); f != nil {
+					digits = len(f[1])
+				}
+				if digits != tt.digits {
+					t.Errorf("%q has %d fractional digits, want %d", rendered, digits, tt.digits)
+				}
+			}
+		})
+	}
+}
+
+func TestWithTimestampPrecisionInvalid(t *testing.T) {
+	for _, precision := range []Precision{-1, PrecisionNanoseconds + 1} {
+		logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+		if err := logger.Configure(WithTimestampPrecision(precision)); err == nil {
+			t.Errorf("Configure accepted precision %d", precision)
+		}
+	}
+}
+
+func TestWithTimeZone(t *testing.T) {
+	at := time.Date(2024, 3, 9, 22, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		loc  func(t *testing.T) *time.Location
+		text string
+		json string
+	}{
+		{
+			"fixed zone across midnight",
+			func(*testing.T) *time.Location { return time.FixedZone("NPT", 5*3600+45*60) },
+			"2024/03/10 04:15:00", "2024-03-10T04:15:00+05:45",
+		},
+		{
+			"named zone",
+			func(t *testing.T) *time.Location {
+				loc, err := time.LoadLocation("America/New_York")
+				if err != nil {
+					t.Skipf("time zone database unavailable: %v", err)
+				}
+				return loc
+			},
+			"2024/03/09 17:30:00", "2024-03-09T17:30:00-05:00",
+		},
+		{
+			"utc",
+			func(*testing.T) *time.Location { return time.UTC },
+			"2024/03/09 22:30:00", "2024-03-09T22:30:00Z",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := tt.loc(t)
+			var text, encoded, ecs bytes.Buffer
+			for _, c := range []struct {
+				buf    *bytes.Buffer
+				format Format
+			}{{&text, FormatText}, {&encoded, FormatJSON}, {&ecs, FormatECS}} {
+				logger := newWriterLogger(LevelInfo, c.buf)
+				logger.now = func() time.Time { return at }
+				if err := logger.Configure(WithFormat(c.format), WithTimeZone(loc)); err != nil {
+					t.Fatal(err)
+				}
+				logger.Log(LevelInfo, "tick")
+			}
+
+			if want := "INFO: " + tt.text + " "; !bytes.HasPrefix(text.Bytes(), []byte(want)) {
+				t.Errorf("text line %q, want prefix %q", text.String(), want)
+			}
+			var doc map[string]interface{}
+			if err := json.Unmarshal(encoded.Bytes(), &doc); err != nil {
+				t.Fatal(err)
+			}
+			if doc["ts"] != tt.json {
+				t.Errorf("JSON time = %v, want %s", doc["ts"], tt.json)
+			}
+			if !bytes.Contains(ecs.Bytes(), []byte(`"@timestamp":"2024-03-09T22:30:00`)) {
+				t.Errorf("ECS line %q is not in UTC", ecs.String())
+			}
+		})
+	}
+}
+
+func TestWithTimeZoneNil(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	if err := logger.Configure(WithTimeZone(nil)); err == nil {
+		t.Error("Configure accepted a nil time zone")
+	}
+}
+
+func TestTextAndJSONTimeFormats(t *testing.T) {
+	at := time.Date(2024, 3, 9, 22, 30, 5, 250_000_000, time.UTC)
+	tests := []struct {
+		name string
+		opts []Option
+		text string
+		json interface{}
+	}{
+		{"defaults", nil, "2024/03/09 22:30:05", "2024-03-09T22:30:05Z"},
+		{"text only", []Option{WithTextTimeFormat(time.Kitchen)}, "10:30PM", "2024-03-09T22:30:05Z"},
+		{"json only", []Option{WithJSONTimeFormat(time.RFC1123)}, "2024/03/09 22:30:05", "Sat, 09 Mar 2024 22:30:05 UTC"},
+		{"both", []Option{WithTextTimeFormat("15:04:05.000"), WithJSONTimeFormat("2006-01-02 15:04")},
+			"22:30:05.250", "2024-03-09 22:30"},
+		{"both in a time zone", []Option{
+			WithTextTimeFormat("15:04 MST"), WithJSONTimeFormat(time.RFC822Z), WithTimeZone(time.FixedZone("CET", 3600)),
+		}, "23:30 CET", "09 Mar 24 23:30 +0100"},
+		{"epoch takes precedence in JSON", []Option{
+			WithTextTimeFormat(time.Kitchen), WithJSONTimeFormat(time.RFC1123), WithTimeKeyAsEpoch(PrecisionSeconds),
+		}, "10:30PM", float64(at.Unix())},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			textLogger, err := NewLoggerE(LevelInfo, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var encoded bytes.Buffer
+			jsonLogger := newWriterLogger(LevelInfo, &encoded)
+			for _, logger := range []*Logger{textLogger, jsonLogger} {
+				logger.now = func() time.Time { return at }
+				if err := logger.Configure(append([]Option{WithTimeZone(time.UTC)}, tt.opts...)...); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := jsonLogger.Configure(WithFormat(FormatJSON)); err != nil {
+				t.Fatal(err)
+			}
+			for _, logger := range []*Logger{textLogger, jsonLogger} {
+				logger.Log(LevelInfo, "logged")
+				logger.LogAt(at, LevelInfo, "backfilled")
+				logger.NewEvent(LevelInfo).Msg("event")
+			}
+			if err := textLogger.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+			if len(lines) != 3 {
+				t.Fatalf("file has %d lines, want 3: %q", len(lines), data)
+			}
+			for _, line := range lines {
+				if want := "INFO: " + tt.text + " "; !strings.HasPrefix(line, want) {
+					t.Errorf("text line %q, want prefix %q", line, want)
+				}
+			}
+			decoder := json.NewDecoder(&encoded)
+			for i := 0; i < 3; i++ {
+				var doc map[string]interface{}
+				if err := decoder.Decode(&doc); err != nil {
+					t.Fatal(err)
+				}
+				if doc["ts"] != tt.json {
+					t.Errorf("%s: JSON time = %v, want %v", doc["msg"], doc["ts"], tt.json)
+				}
+				if doc["msg"] == "backfilled" && doc["event_ts"] != tt.json {
+					t.Errorf("JSON event_ts = %v, want %v", doc["event_ts"], tt.json)
+				}
+			}
+		})
+	}
+}
+
+func TestTimeFormatsInvalid(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	for _, opt := range []Option{WithTextTimeFormat(""), WithJSONTimeFormat("")} {
+		if err := logger.Configure(opt); err == nil {
+			t.Error("Configure accepted an empty layout")
+		}
+	}
+}
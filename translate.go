@@ -0,0 +1,50 @@
+package notifyme
+
+// msgKeyField is the field naming the message to translate
+const msgKeyField = "msgKey"
+
+// WithMessageTranslator localizes messages of entries carrying a "msgKey"
+// field, attached with WithFields or passed with the entry, e.g. through
+// an Event. The translation of the key replaces the message passed to Log,
+// and any optional parameters are still appended after it. An empty
+// translation keeps the original message.
+func WithMessageTranslator(fn func(msgKey string) string) Option {
+	return func(l *Logger) error {
+		l.opts.translator = fn
+		return nil
+	}
+}
+
+// translateMessage returns the translated message for the msgKey field of
+// an entry with the given fields on top of the logger's, or message if
+// there is none. It must be called with the logger mutex held.
+func (l *Logger) translateMessage(message string, fields []Field) string {
+	if l.opts.translator == nil {
+		return message
+	}
+	value, ok := lastField(fields, msgKeyField)
+	if !ok {
+		value, ok = lastField(l.fields, msgKeyField)
+	}
+	if !ok {
+		return message
+	}
+	key, ok := value.(string)
+	if !ok {
+		return message
+	}
+	if translated := l.opts.translator(key); translated != "" {
+		return translated
+	}
+	return message
+}
+
+// lastField returns the value of the last field with the given key
+func lastField(fields []Field, key string) (interface{}, bool) {
+	for i := len(fields) - 1; i >= 0; i-- {
+		if fields[i].Key == key {
+			return fields[i].Value, true
+		}
+	}
+	return nil, false
+}
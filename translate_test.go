@@ -0,0 +1,85 @@
+package notifyme
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// frenchMessages translates the message keys used by the tests
+func frenchMessages(key string) string {
+	return map[string]string{
+		"order.shipped": "Commande expédiée",
+		"order.late":    "Commande en retard",
+	}[key]
+}
+
+func TestWithMessageTranslator(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(*Logger)
+		want string
+	}{
+		{
+			"logger field",
+			func(l *Logger) {
+				l.WithFields(map[string]interface{}{"msgKey": "order.shipped"}).Log(LevelInfo, "Order shipped")
+			},
+			"[INFO] Commande expédiée msgKey=order.shipped",
+		},
+		{
+			"dynamic parameters kept",
+			func(l *Logger) { l.With("msgKey", "order.late").Log(LevelWarn, "Order late", 42, "days") },
+			"[WARN] Commande en retard 42 days msgKey=order.late",
+		},
+		{
+			"event field",
+			func(l *Logger) { l.NewEvent(LevelInfo).Str("msgKey", "order.shipped").Msg("Order shipped") },
+			"[INFO] Commande expédiée msgKey=order.shipped",
+		},
+		{
+			"entry field overrides logger field",
+			func(l *Logger) {
+				l.With("msgKey", "order.late").NewEvent(LevelInfo).Str("msgKey", "order.shipped").Msg("Order shipped")
+			},
+			"[INFO] Commande expédiée",
+		},
+		{
+			"unknown key",
+			func(l *Logger) { l.With("msgKey", "order.lost").Log(LevelError, "Order lost") },
+			"[ERROR] Order lost msgKey=order.lost",
+		},
+		{
+			"non-string key",
+			func(l *Logger) { l.With("msgKey", 7).Log(LevelInfo, "Order seven") },
+			"[INFO] Order seven msgKey=7",
+		},
+		{
+			"no key",
+			func(l *Logger) { l.Log(LevelInfo, "Order shipped") },
+			"[INFO] Order shipped",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newWriterLogger(LevelInfo, &buf)
+			if err := logger.Configure(WithMessageTranslator(frenchMessages)); err != nil {
+				t.Fatal(err)
+			}
+			tt.log(logger)
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("output %q does not contain %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageTranslatorOffByDefault(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newWriterLogger(LevelInfo, &buf)
+	logger.With("msgKey", "order.shipped").Log(LevelInfo, "Order shipped")
+	if !strings.Contains(buf.String(), "[INFO] Order shipped msgKey=order.shipped") {
+		t.Errorf("message changed without a translator: %q", buf.String())
+	}
+}
@@ -0,0 +1,45 @@
+package notifyme
+
+import (
+	"errors"
+	"unicode/utf8"
+)
+
+// WithMaxMessageLength truncates messages longer than n bytes. The message is
+// cut on a UTF-8 boundary, suffixed with an ellipsis, and the entry gets a
+// truncated_bytes field with the number of bytes removed. Zero disables the
+// limit.
+func WithMaxMessageLength(n int) Option {
+	return func(l *Logger) error {
+		if n < 0 {
+			return errors.New("notifyme: max message length must not be negative")
+		}
+		l.opts.maxMessageLength = n
+		return nil
+	}
+}
+
+// truncateMessage applies the logger's message length limit, recording the
+// removed byte count on the entry. It must be called with the logger mutex
+// held.
+func (l *Logger) truncateMessage(entry *Entry, message string) string {
+	if l.opts.maxMessageLength == 0 || len(message) <= l.opts.maxMessageLength {
+		return message
+	}
+	cut := truncateUTF8(message, l.opts.maxMessageLength)
+	entry.Fields = setField(entry.Fields, "truncated_bytes", len(message)-cut)
+	return message[:cut] + "..."
+}
+
+// truncateUTF8 returns the largest cut point no greater than max that does
+// not split a multi-byte rune
+func truncateUTF8(s string, max int) int {
+	if max >= len(s) {
+		return len(s)
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return cut
+}
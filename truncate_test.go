@@ -0,0 +1,73 @@
+package notifyme
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestWithMaxMessageLength(t *testing.T) {
+	tests := []struct {
+		name      string
+		max       int
+		message   string
+		want      string
+		truncated interface{}
+	}{
+		{"short", 10, "hello", "hello", nil},
+		{"exact", 5, "hello", "hello", nil},
+		{"ascii", 5, "hello world", "hello...", 6},
+		{"disabled", 0, strings.Repeat("x", 100), strings.Repeat("x", 100), nil},
+		// "é" is two bytes, so a cut after its first byte backs off
+		{"multi-byte rune", 2, "aé!", "a...", 3},
+		{"rune boundary", 3, "aé!", "aé...", 1},
+		{"four-byte rune", 3, "😀x", "...", 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+			ring, _ := NewRingSink(1)
+			logger.AddSink(ring)
+			if err := logger.Configure(WithMaxMessageLength(tt.max)); err != nil {
+				t.Fatal(err)
+			}
+			logger.Log(LevelInfo, tt.message)
+
+			entry := ring.Entries()[0]
+			if entry.Message != tt.want {
+				t.Errorf("message = %q, want %q", entry.Message, tt.want)
+			}
+			if !utf8.ValidString(entry.Message) {
+				t.Errorf("message %q is not valid UTF-8", entry.Message)
+			}
+			truncated, _ := lastField(entry.Fields, "truncated_bytes")
+			if truncated != tt.truncated {
+				t.Errorf("truncated_bytes = %v, want %v", truncated, tt.truncated)
+			}
+		})
+	}
+}
+
+func TestWithMaxMessageLengthOversized(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newWriterLogger(LevelInfo, &buf)
+	if err := logger.Configure(WithMaxMessageLength(1024)); err != nil {
+		t.Fatal(err)
+	}
+	logger.Log(LevelInfo, strings.Repeat("payload ", 1<<17))
+	line := buf.String()
+	if len(line) > 2048 {
+		t.Errorf("line is %d bytes long, want it cut near 1024", len(line))
+	}
+	if !strings.Contains(line, "... truncated_bytes=") {
+		t.Errorf("line lacks the truncation annotation: %q", line[len(line)-100:])
+	}
+}
+
+func TestWithMaxMessageLengthNegative(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	if err := logger.Configure(WithMaxMessageLength(-1)); err == nil {
+		t.Error("Configure accepted a negative length")
+	}
+}
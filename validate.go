@@ -0,0 +1,55 @@
+package notifyme
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ValidationAction decides what happens to an entry rejected by the
+// validator
+type ValidationAction int
+
+// Validation actions
+const (
+	// ValidationReport writes the entry unchanged; the error only goes to
+	// the error handler
+	ValidationReport ValidationAction = iota
+	// ValidationTag writes the entry with a "validation_error" field
+	ValidationTag
+	// ValidationDrop discards the entry
+	ValidationDrop
+)
+
+// WithEntryValidator checks every entry before it is written, e.g. to
+// require a request_id field on errors. Errors returned by fn are passed to
+// the error handler and the entry is then handled according to action.
+func WithEntryValidator(fn func(Entry) error, action ValidationAction) Option {
+	return func(l *Logger) error {
+		if action < ValidationReport || action > ValidationDrop {
+			return errors.New("notifyme: unknown validation action")
+		}
+		l.opts.validator = fn
+		l.opts.validationAction = action
+		return nil
+	}
+}
+
+// validateEntry runs the validator and reports whether the entry should be
+// written. It must be called with the logger mutex held.
+func (l *Logger) validateEntry(entry *Entry) bool {
+	if l.opts.validator == nil {
+		return true
+	}
+	err := l.opts.validator(*entry)
+	if err == nil {
+		return true
+	}
+	l.errorHandler()(fmt.Errorf("notifyme: invalid entry %q: %w", entry.Message, err))
+	switch l.opts.validationAction {
+	case ValidationDrop:
+		return false
+	case ValidationTag:
+		entry.Fields = setField(append([]Field(nil), entry.Fields...), "validation_error", err.Error())
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package notifyme
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// errNoRequestID is returned by requireRequestID
+var errNoRequestID = errors.New("missing request_id")
+
+// requireRequestID rejects ERROR and CRITICAL entries without a request_id
+// field
+func requireRequestID(entry Entry) error {
+	if entry.Level < LevelError {
+		return nil
+	}
+	if _, ok := lastField(entry.Fields, "request_id"); !ok {
+		return errNoRequestID
+	}
+	return nil
+}
+
+func TestWithEntryValidator(t *testing.T) {
+	tests := []struct {
+		name     string
+		action   ValidationAction
+		level    int
+		fields   []interface{}
+		written  bool
+		reported bool
+		tagged   bool
+	}{
+		{"valid error", ValidationDrop, LevelError, []interface{}{"request_id", "r-1"}, true, false, false},
+		{"warn not checked", ValidationDrop, LevelWarn, nil, true, false, false},
+		{"report", ValidationReport, LevelError, nil, true, true, false},
+		{"tag", ValidationTag, LevelError, nil, true, true, true},
+		{"drop", ValidationDrop, LevelCritical, []interface{}{"user", "ann"}, false, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			var reported []error
+			logger := newWriterLogger(LevelInfo, &buf)
+			ring, _ := NewRingSink(1)
+			logger.AddSink(ring)
+			err := logger.Configure(
+				WithEntryValidator(requireRequestID, tt.action),
+				WithErrorHandler(func(err error) { reported = append(reported, err) }),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			logger.With(tt.fields...).Log(tt.level, "charge failed")
+
+			entries := ring.Entries()
+			if written := len(entries) == 1; written != tt.written || (buf.Len() > 0) != tt.written {
+				t.Fatalf("written to sink %v and output %v, want %v", written, buf.Len() > 0, tt.written)
+			}
+			if tt.reported != (len(reported) == 1) || tt.reported && !errors.Is(reported[0], errNoRequestID) {
+				t.Errorf("reported %v, want the validation error: %v", reported, tt.reported)
+			}
+			if !tt.written {
+				return
+			}
+			tag, tagged := lastField(entries[0].Fields, "validation_error")
+			if tagged != tt.tagged || tagged && tag != errNoRequestID.Error() {
+				t.Errorf("validation_error = %v, tagged %v, want tagged %v", tag, tagged, tt.tagged)
+			}
+		})
+	}
+}
+
+func TestWithEntryValidatorTagKeepsLoggerFields(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	if err := logger.Configure(WithEntryValidator(requireRequestID, ValidationTag), WithErrorHandler(func(error) {})); err != nil {
+		t.Fatal(err)
+	}
+	child := logger.With("user", "ann")
+	child.Log(LevelError, "first")
+	child.Log(LevelError, "second")
+	if want := []Field{{Key: "user", Value: "ann"}}; !reflect.DeepEqual(child.fields, want) {
+		t.Errorf("logger fields = %v, want %v", child.fields, want)
+	}
+}
+
+func TestWithEntryValidatorInvalidAction(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	if err := logger.Configure(WithEntryValidator(requireRequestID, ValidationAction(9))); err == nil {
+		t.Error("Configure accepted an unknown action")
+	}
+}
@@ -0,0 +1,121 @@
+package notifyme
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// BytesEncoding controls how []byte values are rendered
+type BytesEncoding int
+
+// Byte slice encodings
+const (
+	BytesHex BytesEncoding = iota
+	BytesBase64
+)
+
+// maxRenderedBytes is the number of bytes of a []byte value that are
+// rendered; the rest is replaced by a marker with the full length
+const maxRenderedBytes = 1024
+
+// WithBytesEncoding sets how []byte values are rendered. The default is hex.
+// Slices longer than 1 KiB are rendered up to that length, followed by a
+// marker with their full length, such as "...(4096 bytes)".
+func WithBytesEncoding(encoding BytesEncoding) Option {
+	return func(l *Logger) error {
+		if encoding != BytesHex && encoding != BytesBase64 {
+			return errors.New("notifyme: unknown bytes encoding")
+		}
+		l.opts.bytesEncoding = encoding
+		return nil
+	}
+}
+
+// RawJSON is a field value holding pre-serialized JSON. JSON and ECS output
+// and ElasticSink embed it verbatim instead of escaping it into a string;
+// text output shows it as is. Values that are not well-formed JSON are
+// encoded as strings.
+type RawJSON []byte
+
+// MarshalJSON returns the raw JSON, or a JSON string if it is malformed
+func (r RawJSON) MarshalJSON() ([]byte, error) {
+	if json.Valid(r) {
+		return append([]byte(nil), r...), nil
+	}
+	return json.Marshal(string(r))
+}
+
+// String returns the raw JSON text
+func (r RawJSON) String() string {
+	return string(r)
+}
+
+// DurationFormat controls how time.Duration values are rendered in JSON
+type DurationFormat int
+
+// Duration formats
+const (
+	// DurationString renders durations as strings such as "1.5s"
+	DurationString DurationFormat = iota
+	// DurationNanoseconds renders durations as integer nanoseconds
+	DurationNanoseconds
+	// DurationMilliseconds renders durations as fractional milliseconds
+	DurationMilliseconds
+)
+
+// WithDurationFormat sets how time.Duration values are rendered in JSON
+// and ECS output. The default is DurationString; text output always uses
+// the string form.
+func WithDurationFormat(format DurationFormat) Option {
+	return func(l *Logger) error {
+		if format < DurationString || format > DurationMilliseconds {
+			return errors.New("notifyme: unknown duration format")
+		}
+		l.opts.durationFormat = format
+		return nil
+	}
+}
+
+// formatValue renders a logged value as text. It must be called with the
+// logger mutex held.
+func (l *Logger) formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case []byte:
+		return l.formatBytes(v)
+	default:
+		if l.opts.maxDepth > 0 {
+			return l.formatNested(v)
+		}
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// jsonDuration renders a duration with the configured JSON format
+func (l *Logger) jsonDuration(d time.Duration) interface{} {
+	switch l.opts.durationFormat {
+	case DurationNanoseconds:
+		return int64(d)
+	case DurationMilliseconds:
+		return float64(d) / float64(time.Millisecond)
+	default:
+		return d.String()
+	}
+}
+
+// formatBytes renders a byte slice with the configured encoding, cut at
+// maxRenderedBytes
+func (l *Logger) formatBytes(b []byte) string {
+	marker := ""
+	if len(b) > maxRenderedBytes {
+		marker = fmt.Sprintf("...(%d bytes)", len(b))
+		b = b[:maxRenderedBytes]
+	}
+	if l.opts.bytesEncoding == BytesBase64 {
+		return base64.StdEncoding.EncodeToString(b) + marker
+	}
+	return hex.EncodeToString(b) + marker
+}
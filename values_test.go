@@ -0,0 +1,193 @@
+package notifyme
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithBytesEncoding(t *testing.T) {
+	data := []byte{0xde, 0xad, 0xbe, 0xef, 0x00, 0xff}
+	tests := []struct {
+		name     string
+		opts     []Option
+		encoding string
+	}{
+		{"default hex", nil, "deadbeef00ff"},
+		{"hex", []Option{WithBytesEncoding(BytesHex)}, "deadbeef00ff"},
+		{"base64", []Option{WithBytesEncoding(BytesBase64)}, "3q2+7wD/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var text, encoded bytes.Buffer
+			textLogger := newWriterLogger(LevelInfo, &text)
+			jsonLogger := newWriterLogger(LevelInfo, &encoded)
+			if err := textLogger.Configure(tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if err := jsonLogger.Configure(append(tt.opts, WithFormat(FormatJSON))...); err != nil {
+				t.Fatal(err)
+			}
+			textLogger.With("packet", data).Log(LevelInfo, "dump", data)
+			jsonLogger.With("packet", data).Log(LevelInfo, "dump", data)
+
+			if want := "dump " + tt.encoding + " packet=" + tt.encoding; !strings.Contains(text.String(), want) {
+				t.Errorf("text line %q does not contain %q", text.String(), want)
+			}
+			var doc map[string]interface{}
+			if err := json.Unmarshal(encoded.Bytes(), &doc); err != nil {
+				t.Fatal(err)
+			}
+			if doc["packet"] != tt.encoding || doc["msg"] != "dump "+tt.encoding {
+				t.Errorf("JSON packet = %v and msg = %v, want %s", doc["packet"], doc["msg"], tt.encoding)
+			}
+		})
+	}
+}
+
+func TestBytesRespectMaxMessageLength(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	ring, _ := NewRingSink(1)
+	logger.AddSink(ring)
+	if err := logger.Configure(WithMaxMessageLength(16)); err != nil {
+		t.Fatal(err)
+	}
+	logger.Log(LevelInfo, "dump", make([]byte, 1024))
+	entry := ring.Entries()[0]
+	if want := "dump 00000000000..."; entry.Message != want {
+		t.Errorf("message = %q, want %q", entry.Message, want)
+	}
+	if truncated, _ := lastField(entry.Fields, "truncated_bytes"); truncated != 2048+5-16 {
+		t.Errorf("truncated_bytes = %v, want %d", truncated, 2048+5-16)
+	}
+}
+
+func TestBytesTruncatedAtLimit(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+		want string
+	}{
+		{"at the limit", maxRenderedBytes, strings.Repeat("ab", maxRenderedBytes)},
+		{"over the limit", maxRenderedBytes + 1, strings.Repeat("ab", maxRenderedBytes) + "...(1025 bytes)"},
+		{"far over the limit", 1 << 20, strings.Repeat("ab", maxRenderedBytes) + "...(1048576 bytes)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newWriterLogger(LevelInfo, &buf)
+			logger.With("packet", bytes.Repeat([]byte{0xab}, tt.size)).Log(LevelInfo, "dump")
+			if line := buf.String(); !strings.HasSuffix(line, " packet="+tt.want+"\n") {
+				t.Errorf("line of %d bytes does not end with the %d expected ones", len(line), len(tt.want)+1)
+			}
+		})
+	}
+}
+
+func TestWithBytesEncodingInvalid(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	if err := logger.Configure(WithBytesEncoding(BytesEncoding(7))); err == nil {
+		t.Error("Configure accepted an unknown encoding")
+	}
+}
+
+func TestWithDurationFormat(t *testing.T) {
+	took := 1500 * time.Millisecond
+	tests := []struct {
+		name string
+		opts []Option
+		json string
+	}{
+		{"default string", nil, `"took":"1.5s"`},
+		{"string", []Option{WithDurationFormat(DurationString)}, `"took":"1.5s"`},
+		{"nanoseconds", []Option{WithDurationFormat(DurationNanoseconds)}, `"took":1500000000`},
+		{"milliseconds", []Option{WithDurationFormat(DurationMilliseconds)}, `"took":1500`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var text, encoded bytes.Buffer
+			textLogger := newWriterLogger(LevelInfo, &text)
+			jsonLogger := newWriterLogger(LevelInfo, &encoded)
+			if err := textLogger.Configure(tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if err := jsonLogger.Configure(append(tt.opts, WithFormat(FormatJSON))...); err != nil {
+				t.Fatal(err)
+			}
+			textLogger.With("took", took).Log(LevelInfo, "done")
+			jsonLogger.With("took", took).Log(LevelInfo, "done")
+
+			if !strings.Contains(text.String(), "done took=1.5s") {
+				t.Errorf("text line %q does not render the duration as a string", text.String())
+			}
+			if !strings.Contains(encoded.String(), tt.json) {
+				t.Errorf("JSON line %q does not contain %s", encoded.String(), tt.json)
+			}
+		})
+	}
+}
+
+func TestWithDurationFormatFractionalMilliseconds(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newWriterLogger(LevelInfo, &buf)
+	if err := logger.Configure(WithFormat(FormatJSON), WithDurationFormat(DurationMilliseconds)); err != nil {
+		t.Fatal(err)
+	}
+	logger.With("took", 2500*time.Microsecond).Log(LevelInfo, "done")
+	if !strings.Contains(buf.String(), `"took":2.5`) {
+		t.Errorf("JSON line %q does not contain fractional milliseconds", buf.String())
+	}
+}
+
+func TestWithDurationFormatInvalid(t *testing.T) {
+	logger := newWriterLogger(LevelInfo, &bytes.Buffer{})
+	if err := logger.Configure(WithDurationFormat(DurationFormat(9))); err == nil {
+		t.Error("Configure accepted an unknown duration format")
+	}
+}
+
+func TestRawJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  RawJSON
+		want interface{}
+	}{
+		{"object", RawJSON(`{"id":7,"tags":["a","b"]}`), map[string]interface{}{"id": 7.0, "tags": []interface{}{"a", "b"}}},
+		{"array", RawJSON(`[1, 2]`), []interface{}{1.0, 2.0}},
+		{"number", RawJSON(`42`), 42.0},
+		{"malformed", RawJSON(`{"id":`), `{"id":`},
+		{"empty", RawJSON(``), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, format := range []Format{FormatJSON, FormatECS} {
+				var buf bytes.Buffer
+				logger := newWriterLogger(LevelInfo, &buf)
+				if err := logger.Configure(WithFormat(format)); err != nil {
+					t.Fatal(err)
+				}
+				logger.With("doc", tt.raw).Log(LevelInfo, "stored")
+
+				var doc map[string]interface{}
+				if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+					t.Fatalf("%s: %v in %q", formatName(format), err, buf.String())
+				}
+				if got := doc["doc"]; !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("%s: doc = %#v, want %#v", formatName(format), got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRawJSONText(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newWriterLogger(LevelInfo, &buf)
+	logger.With("doc", RawJSON(`{"id":7}`)).Log(LevelInfo, "stored")
+	if !strings.Contains(buf.String(), `stored doc={"id":7}`) {
+		t.Errorf("text line %q does not show the raw JSON as is", buf.String())
+	}
+}
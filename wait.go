@@ -0,0 +1,38 @@
+package notifyme
+
+import (
+	"strings"
+	"time"
+)
+
+// SinkErrors collects the failed deliveries of a LogAndWait call
+type SinkErrors []error
+
+func (e SinkErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the individual delivery errors
+func (e SinkErrors) Unwrap() []error {
+	return e
+}
+
+// LogAndWait logs a message like Log but delivers it to every sink inline,
+// bypassing the WithSinkConcurrency queue, and returns only once each sink
+// has attempted delivery. Failed or timed-out deliveries are returned as
+// SinkErrors, and are still reported to the error handler. It returns nil
+// when every sink succeeded or the entry was filtered out. It is meant for
+// critical alerts that must be handed off before the program goes on, and
+// is slower than Log by design.
+func (l *Logger) LogAndWait(level int, message string, optionalParams ...interface{}) error {
+	var errs []error
+	l.logEntry(2, &errs, time.Time{}, nil, level, message, optionalParams...)
+	if len(errs) == 0 {
+		return nil
+	}
+	return SinkErrors(errs)
+}
@@ -0,0 +1,99 @@
+package notifyme
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogAndWait(t *testing.T) {
+	tests := []struct {
+		name   string
+		sinks  []Sink
+		opts   []Option
+		level  int
+		failed int
+	}{
+		{"no sinks", nil, nil, LevelError, 0},
+		{"delivered", []Sink{&slowSink{}}, nil, LevelError, 0},
+		{"one failure", []Sink{&slowSink{}, failingSink{}}, nil, LevelError, 1},
+		{"every failure", []Sink{failingSink{}, failingSink{}}, nil, LevelError, 2},
+		{"bypasses the queue", []Sink{failingSink{}}, []Option{WithSinkConcurrency(2)}, LevelError, 1},
+		{"filtered out", []Sink{failingSink{}}, nil, LevelInfo, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reported int
+			logger := newWriterLogger(LevelWarn, &bytes.Buffer{})
+			for _, sink := range tt.sinks {
+				logger.AddSink(sink)
+			}
+			opts := append([]Option{WithErrorHandler(func(error) { reported++ })}, tt.opts...)
+			if err := logger.Configure(opts...); err != nil {
+				t.Fatal(err)
+			}
+			defer logger.Close()
+
+			err := logger.LogAndWait(tt.level, "page on-call")
+			if tt.failed == 0 {
+				if err != nil {
+					t.Errorf("LogAndWait = %v, want nil", err)
+				}
+				return
+			}
+			var sinkErrs SinkErrors
+			if !errors.As(err, &sinkErrs) || len(sinkErrs) != tt.failed {
+				t.Fatalf("LogAndWait = %v, want %d delivery errors", err, tt.failed)
+			}
+			for _, e := range sinkErrs {
+				if !errors.Is(e, errDiskFull) {
+					t.Errorf("delivery error %v, want %v", e, errDiskFull)
+				}
+			}
+			if reported != tt.failed {
+				t.Errorf("error handler got %d errors, want %d", reported, tt.failed)
+			}
+		})
+	}
+}
+
+func TestLogAndWaitWaitsForDelivery(t *testing.T) {
+	sink := &slowSink{delay: 50 * time.Millisecond}
+	logger := newPoolTestLogger(t, sink, WithSinkConcurrency(1))
+	defer logger.Close()
+
+	start := time.Now()
+	if err := logger.LogAndWait(LevelCritical, "payments down"); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < sink.delay {
+		t.Errorf("LogAndWait returned after %v, before the %v delivery", elapsed, sink.delay)
+	}
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if len(sink.messages) != 1 || sink.messages[0] != "payments down" {
+		t.Errorf("sink got %q when LogAndWait returned", sink.messages)
+	}
+}
+
+func TestLogAndWaitTimeout(t *testing.T) {
+	sink := &hangingSink{release: make(chan struct{})}
+	defer close(sink.release)
+	logger := newPoolTestLogger(t, sink, WithSinkTimeout(20*time.Millisecond), WithErrorHandler(func(error) {}))
+	err := logger.LogAndWait(LevelCritical, "payments down")
+	if err == nil || !strings.Contains(err.Error(), "abandoned after 20ms") {
+		t.Errorf("LogAndWait = %v, want a timeout error", err)
+	}
+}
+
+func TestSinkErrorsError(t *testing.T) {
+	err := SinkErrors{errors.New("pagerduty: 503"), errDiskFull}
+	if got, want := err.Error(), "pagerduty: 503; "+errDiskFull.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if got := err.Unwrap(); len(got) != 2 || got[1] != errDiskFull {
+		t.Errorf("Unwrap() = %v", got)
+	}
+}